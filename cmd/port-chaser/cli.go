@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/manson/port-chaser/internal/app"
+	"github.com/manson/port-chaser/internal/hooks"
+	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/policy"
+	"github.com/manson/port-chaser/internal/process"
+)
+
+// runKill implements `port-chaser kill [flags] PORT` for scripted, non-interactive kills.
+// It is also what the TUI runs through sudo or a helper to kill another user's process:
+// -pid, -steps and -json pin the target and signals and make the result machine-readable.
+// It returns the process exit code: 0 on success, 1 if the kill failed, 2 on usage errors.
+func runKill(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("kill", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	signal := fs.String("signal", "", "first signal to send instead of the matching strategy (e.g. SIGINT)")
+	scope := fs.String("scope", string(process.ScopeProcess), "what to signal: process, group or session")
+	dryRun := fs.Bool("dry-run", false, "show what would be signalled without killing anything")
+	pid := fs.Int("pid", 0, "only kill if the port is still held by this PID")
+	steps := fs.String("steps", "", "signal sequence instead of the matching strategy (e.g. SIGINT:10s,SIGKILL:500ms)")
+	jsonOutput := fs.Bool("json", false, "print the kill report as JSON instead of text")
+	noHooks := fs.Bool("no-hooks", false, "don't run the configured pre-kill and post-kill hooks")
+	confirm := fs.String("confirm", "", "process name, required to kill processes a \"confirm\" policy rule protects")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: port-chaser kill [flags] PORT")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	portNumber, err := strconv.Atoi(fs.Arg(0))
+	if err != nil || portNumber <= 0 || portNumber > 65535 {
+		fmt.Fprintf(stderr, "error: invalid port %q\n", fs.Arg(0))
+		return 2
+	}
+	if _, err := process.ParseScope(*scope); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	if *signal != "" {
+		if _, err := process.ParseSignal(*signal); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 2
+		}
+	}
+	if *signal != "" && *steps != "" {
+		fmt.Fprintln(stderr, "error: -signal and -steps are mutually exclusive")
+		return 2
+	}
+	var stepList []process.SignalStep
+	if *steps != "" {
+		if stepList, err = process.ParseSteps(*steps); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 2
+		}
+	}
+
+	cfg := loadConfig()
+	port, err := newScanner(cfg).ScanByPort(portNumber)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: scan failed: %v\n", err)
+		return 1
+	}
+	if port == nil || port.PID == 0 {
+		fmt.Fprintf(stderr, "error: no process found listening on port %d\n", portNumber)
+		return 1
+	}
+	if *pid != 0 && port.PID != *pid {
+		fmt.Fprintf(stderr, "error: port %d is now held by PID %d, not %d\n", portNumber, port.PID, *pid)
+		return 1
+	}
+
+	adapter := newKillerAdapter(cfg)
+	runner := hooks.NewRunner(buildHooks(cfg.Hooks)...)
+	if *noHooks {
+		runner = nil
+	}
+	adapter.steps = stepList
+	opts := app.KillOptions{Signal: *signal, Scope: *scope, Confirmed: *confirm != "" && *confirm == port.ProcessName}
+	jsonOut := stdout
+	if *jsonOutput {
+		// Only the JSON report goes to stdout; the caller watches for respawns itself
+		adapter.respawnWindow = 0
+		stdout = io.Discard
+	}
+
+	fmt.Fprintf(stdout, "Port %d: %s (PID %d)\n", port.PortNumber, port.ProcessName, port.PID)
+	fmt.Fprintf(stdout, "Signals: %s\n", adapter.DescribeKill(*port, opts))
+
+	if opts.Scope != string(process.ScopeProcess) {
+		members, err := adapter.Members(*port, opts.Scope)
+		if err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "Process %s (%d processes):\n", opts.Scope, len(members))
+		printMembers(stdout, members, false)
+	}
+
+	decision := adapter.killer.Policy.Evaluate(port)
+	switch {
+	case decision.Action == policy.ActionBlock:
+		fmt.Fprintf(stderr, "error: %s is protected by policy rule %q\n", port.ProcessName, decision.Rule)
+		return 1
+	case decision.Action == policy.ActionConfirm && !opts.Confirmed:
+		fmt.Fprintf(stderr, "error: policy rule %q protects %s; rerun with -confirm %s to kill it\n",
+			decision.Rule, port.ProcessName, port.ProcessName)
+		return 1
+	case decision.Action == policy.ActionWarn:
+		fmt.Fprintf(stderr, "warning: %s matches policy rule %q\n", port.ProcessName, decision.Rule)
+	}
+
+	if *dryRun {
+		return 0
+	}
+
+	runs, err := runner.Run(context.Background(), hooks.PreKill, *port, nil)
+	printHookRuns(stderr, runs)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: kill cancelled: %v\n", err)
+		return 1
+	}
+
+	report, err := adapter.Kill(*port, opts)
+	result := &hooks.Result{Success: err == nil, Message: "Process killed successfully"}
+	if err != nil {
+		result.Message = err.Error()
+	} else {
+		result.Report = report
+	}
+	runs, _ = runner.Run(context.Background(), hooks.PostKill, *port, result)
+	printHookRuns(stderr, runs)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	if *jsonOutput {
+		if err := json.NewEncoder(jsonOut).Encode(report); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if len(report.Members) > 0 {
+		printMembers(stdout, report.Members, true)
+	}
+	fmt.Fprintf(stdout, "Killed %s (%s)\n", port.ProcessName, report.Method)
+	if report.Release != nil && !report.Release.Released {
+		fmt.Fprintf(stdout, "warning: port %d is still in use%s\n", port.PortNumber, holderSuffix(report.Release))
+	}
+	if report.Respawn != nil {
+		fmt.Fprintf(stdout, "warning: port %d respawned as PID %d (%s)\n",
+			port.PortNumber, report.Respawn.PID, report.Respawn.Supervisor)
+	}
+	return 0
+}
+
+// printHookRuns prints each hook's output and, for failed hooks, why they failed.
+func printHookRuns(w io.Writer, runs []models.HookRun) {
+	for _, run := range runs {
+		if output := strings.TrimRight(run.Output, "\n"); output != "" {
+			fmt.Fprintln(w, output)
+		}
+		if run.Failed() {
+			fmt.Fprintf(w, "hook %s (%s) failed: %s\n", run.Hook, run.Event, run.Error)
+		}
+	}
+}
+
+// holderSuffix describes the process still holding a port, e.g. " by PID 42 (esbuild)".
+func holderSuffix(release *models.PortRelease) string {
+	if release.HolderPID == 0 {
+		return ""
+	}
+	if release.HolderName == "" {
+		return fmt.Sprintf(" by PID %d", release.HolderPID)
+	}
+	return fmt.Sprintf(" by PID %d (%s)", release.HolderPID, release.HolderName)
+}
+
+// printMembers writes one line per group or session member, with its outcome if withOutcome is set.
+func printMembers(w io.Writer, members []models.ProcessMember, withOutcome bool) {
+	for _, member := range members {
+		if !withOutcome {
+			fmt.Fprintf(w, "  %-7d %-15s %s\n", member.PID, member.Name, member.Command)
+			continue
+		}
+		outcome := "still running"
+		if member.Terminated {
+			outcome = "terminated by " + member.Signal
+		}
+		fmt.Fprintf(w, "  %-7d %-15s %s\n", member.PID, member.Name, outcome)
+	}
+}
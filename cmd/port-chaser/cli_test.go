@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gopsprocess "github.com/shirou/gopsutil/v3/process"
+
+	"github.com/manson/port-chaser/internal/config"
+)
+
+func TestRunKill_Usage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no port", nil},
+		{"extra args", []string{"3000", "4000"}},
+		{"invalid port", []string{"http"}},
+		{"port out of range", []string{"70000"}},
+		{"unknown scope", []string{"-scope", "tree", "3000"}},
+		{"unknown signal", []string{"-signal", "SIGNOPE", "3000"}},
+		{"unknown flag", []string{"-force", "3000"}},
+		{"invalid steps", []string{"-steps", "SIGTERM:soon", "3000"}},
+		{"signal and steps", []string{"-signal", "INT", "-steps", "SIGKILL", "3000"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := runKill(tt.args, &stdout, &stderr); code != 2 {
+				t.Errorf("runKill(%v) = %d, want 2 (stderr: %s)", tt.args, code, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunKill_DryRun(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	var stdout, stderr bytes.Buffer
+	code := runKill([]string{"-dry-run", "-signal", "INT", fmt.Sprint(port)}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("runKill() = %d, want 0 (stderr: %s)", code, stderr.String())
+	}
+
+	out := stdout.String()
+	if !strings.Contains(out, fmt.Sprintf("(PID %d)", os.Getpid())) {
+		t.Errorf("output should name this test process:\n%s", out)
+	}
+	if !strings.Contains(out, "Signals: SIGINT 3s → SIGKILL") {
+		t.Errorf("output should describe the chosen signal:\n%s", out)
+	}
+
+	// A group kill would include the test process itself and must be refused
+	stdout.Reset()
+	stderr.Reset()
+	if code := runKill([]string{"-dry-run", "-scope", "group", fmt.Sprint(port)}, &stdout, &stderr); code != 1 {
+		t.Errorf("group dry-run on own process = %d, want 1", code)
+	}
+
+	// Explicit steps replace the strategy
+	stdout.Reset()
+	if code := runKill([]string{"-dry-run", "-steps", "SIGINT:1s,SIGKILL", fmt.Sprint(port)}, &stdout, &stderr); code != 0 {
+		t.Fatalf("steps dry-run = %d, want 0 (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Signals: custom: SIGINT 1s → SIGKILL") {
+		t.Errorf("output should describe the explicit steps:\n%s", stdout.String())
+	}
+
+	// A -pid that no longer holds the port is refused
+	stderr.Reset()
+	if code := runKill([]string{"-dry-run", "-pid", fmt.Sprint(os.Getpid() + 1), fmt.Sprint(port)}, &stdout, &stderr); code != 1 {
+		t.Errorf("mismatched -pid = %d, want 1", code)
+	}
+	if !strings.Contains(stderr.String(), "is now held by PID") {
+		t.Errorf("stderr should explain the PID mismatch: %s", stderr.String())
+	}
+}
+
+func TestRunKill_Policy(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("APPDATA", dir)
+	path := config.DefaultPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	writeRule := func(action string) {
+		data := fmt.Sprintf(`{"policy": {"rules": [{"name": "self", "action": %q, "pids": [%d]}]}}`, action, os.Getpid())
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	p, err := gopsprocess.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		t.Skipf("cannot inspect own process: %v", err)
+	}
+	name, err := p.Name()
+	if err != nil {
+		t.Skipf("cannot read own process name: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		action   string
+		args     []string
+		wantCode int
+		wantErr  string
+	}{
+		{"block", "block", nil, 1, `protected by policy rule "self"`},
+		{"confirm without name", "confirm", nil, 1, "rerun with -confirm"},
+		{"confirm with wrong name", "confirm", []string{"-confirm", "nope"}, 1, "rerun with -confirm"},
+		{"confirm with name", "confirm", []string{"-confirm", name}, 0, ""},
+		{"warn", "warn", nil, 0, `matches policy rule "self"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeRule(tt.action)
+			var stdout, stderr bytes.Buffer
+			args := append(append([]string{"-dry-run"}, tt.args...), fmt.Sprint(port))
+			if code := runKill(args, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("runKill(%v) = %d, want %d (stderr: %s)", args, code, tt.wantCode, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.wantErr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunKill_HookVeto(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("APPDATA", dir)
+	path := config.DefaultPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	// The hook refuses kills of this test process, which holds the port
+	data := fmt.Sprintf(`{"hooks": [{"name": "guard", "event": "pre_kill", "ports": [%d],
+		"command": ["sh", "-c", "echo \"busy on $PORT_CHASER_PORT\"; exit 1"]}]}`, port)
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runKill([]string{fmt.Sprint(port)}, &stdout, &stderr); code != 1 {
+		t.Fatalf("runKill() = %d, want 1 (stderr: %s)", code, stderr.String())
+	}
+	for _, want := range []string{fmt.Sprintf("busy on %d", port), `kill cancelled: vetoed by hook "guard"`} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("stderr = %q, want it to contain %q", stderr.String(), want)
+		}
+	}
+}
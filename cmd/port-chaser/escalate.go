@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manson/port-chaser/internal/app"
+	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/process"
+)
+
+// escalateSlack is extra time for sudo or the helper to start before the elevated kill begins.
+const escalateSlack = 10 * time.Second
+
+// EscalateCommand returns the command line KillEscalated runs for the port: the configured prefix
+// followed by `port-chaser kill` pinned to the scanned PID and the resolved signal sequence, so the
+// elevated process applies exactly what the user confirmed and nothing else.
+// It returns nil when escalation is disabled.
+func (a *killerAdapter) EscalateCommand(port models.PortInfo, opts app.KillOptions) []string {
+	if len(a.escalate) == 0 || a.executable == "" {
+		return nil
+	}
+	strategy, err := a.strategy(port, opts)
+	if err != nil {
+		return nil
+	}
+
+	command := append([]string{}, a.escalate...)
+	command = append(command, a.executable, "kill",
+		"-pid", strconv.Itoa(port.PID),
+		"-steps", process.FormatSteps(strategy.Steps))
+	if opts.Scope != "" {
+		command = append(command, "-scope", opts.Scope)
+	}
+	if opts.Confirmed {
+		command = append(command, "-confirm", port.ProcessName)
+	}
+	// The TUI already runs the hooks as the user; the elevated kill must not run them again as root
+	return append(command, "-no-hooks", "-json", strconv.Itoa(port.PortNumber))
+}
+
+// KillEscalated retries a kill through the escalation command and parses the JSON report it prints.
+// The port is watched for a respawn from here, since the elevated kill skips that step.
+func (a *killerAdapter) KillEscalated(port models.PortInfo, opts app.KillOptions) (*app.KillReport, error) {
+	command := a.EscalateCommand(port, opts)
+	if command == nil {
+		return nil, errors.New("privilege escalation is disabled")
+	}
+	strategy, err := a.strategy(port, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		strategy.TotalWait()+a.killer.ReleaseTimeout+killSlack+escalateSlack)
+	defer cancel()
+
+	// Best effort: another user's environment may be unreadable, leaving only argv
+	launch, _ := process.CaptureLaunch(port.PID)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("escalated kill failed: %s", escalateFailure(stderr.String(), err))
+	}
+
+	var report app.KillReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		return nil, fmt.Errorf("escalated kill returned invalid output: %w", err)
+	}
+	report.Launch = launch
+	// Respawn detection is best effort; a failed socket lookup just means no respawn is reported
+	report.Respawn, _ = process.WatchRespawn(context.Background(), &port, a.respawnWindow)
+	return &report, nil
+}
+
+// escalateFailure picks the most useful explanation of a failed escalation:
+// the last line the command wrote to stderr (e.g. "sudo: a password is required"), or the exit error.
+func escalateFailure(stderr string, err error) string {
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return strings.TrimPrefix(last, "error: ")
+	}
+	return err.Error()
+}
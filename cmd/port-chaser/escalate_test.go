@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/manson/port-chaser/internal/app"
+	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/process"
+)
+
+// installStubSudo puts a fake sudo on PATH that records its arguments and runs script.
+func installStubSudo(t *testing.T, script string) (argsFile string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("stub sudo is a shell script")
+	}
+
+	dir := t.TempDir()
+	argsFile = filepath.Join(dir, "args")
+	stub := "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + argsFile + "\n" + script
+	if err := os.WriteFile(filepath.Join(dir, "sudo"), []byte(stub), 0755); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return argsFile
+}
+
+func newEscalatingAdapter() *killerAdapter {
+	return &killerAdapter{
+		killer:     process.NewProcessKiller(),
+		escalate:   []string{"sudo", "-n"},
+		executable: "/usr/local/bin/port-chaser",
+	}
+}
+
+func TestKillerAdapter_EscalateCommand(t *testing.T) {
+	adapter := newEscalatingAdapter()
+	port := models.PortInfo{PortNumber: 80, ProcessName: "nginx", PID: 4242}
+
+	got := adapter.EscalateCommand(port, app.KillOptions{Signal: "SIGINT", Scope: "group"})
+	want := []string{"sudo", "-n", "/usr/local/bin/port-chaser", "kill",
+		"-pid", "4242", "-steps", "SIGINT:3s,SIGKILL:500ms", "-scope", "group", "-no-hooks", "-json", "80"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EscalateCommand() = %v, want %v", got, want)
+	}
+
+	// A typed policy confirmation is passed on, since the elevated kill evaluates the policy again
+	got = adapter.EscalateCommand(port, app.KillOptions{Confirmed: true})
+	if !strings.Contains(strings.Join(got, " "), "-confirm nginx -no-hooks -json 80") {
+		t.Errorf("EscalateCommand() with confirmation = %v, want -confirm nginx", got)
+	}
+
+	adapter.escalate = nil
+	if got := adapter.EscalateCommand(port, app.KillOptions{}); got != nil {
+		t.Errorf("EscalateCommand() with escalation disabled = %v, want nil", got)
+	}
+}
+
+func TestKillerAdapter_KillEscalated(t *testing.T) {
+	port := models.PortInfo{PortNumber: 80, ProcessName: "nginx", PID: 4242}
+
+	t.Run("success", func(t *testing.T) {
+		argsFile := installStubSudo(t, `echo '{"method":"SIGQUIT","strategy":"nginx","release":{"released":true}}'`)
+
+		report, err := newEscalatingAdapter().KillEscalated(port, app.KillOptions{})
+		if err != nil {
+			t.Fatalf("KillEscalated() error = %v", err)
+		}
+		if report.Method != "SIGQUIT" || report.Strategy != "nginx" || report.Release == nil || !report.Release.Released {
+			t.Errorf("report = %+v, want the stub's JSON", report)
+		}
+
+		args, err := os.ReadFile(argsFile)
+		if err != nil {
+			t.Fatalf("stub sudo was not run: %v", err)
+		}
+		if got := strings.Fields(string(args)); got[0] != "-n" || got[2] != "kill" || got[len(got)-1] != "80" {
+			t.Errorf("sudo args = %v", got)
+		}
+	})
+
+	t.Run("password required", func(t *testing.T) {
+		installStubSudo(t, "echo 'sudo: a password is required' >&2\nexit 1")
+
+		_, err := newEscalatingAdapter().KillEscalated(port, app.KillOptions{})
+		if err == nil || !strings.Contains(err.Error(), "sudo: a password is required") {
+			t.Errorf("KillEscalated() error = %v, want sudo's message", err)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		adapter := newEscalatingAdapter()
+		adapter.escalate = nil
+		if _, err := adapter.KillEscalated(port, app.KillOptions{}); err == nil {
+			t.Error("KillEscalated() should fail when escalation is disabled")
+		}
+	})
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/manson/port-chaser/internal/config"
+	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/policy"
+	"github.com/manson/port-chaser/internal/storage"
+)
+
+// runHistory implements `port-chaser history SUBCOMMAND` for browsing and maintaining the kill history.
+// It returns the process exit code: 0 on success, 1 if the command failed, 2 on usage errors.
+func runHistory(args []string, stdout, stderr io.Writer) int {
+	usage := func() {
+		fmt.Fprintln(stderr, "Usage: port-chaser history list [filters] [-limit N] [-cursor CURSOR] [-json]")
+		fmt.Fprintln(stderr, "       port-chaser history export [filters] [-format jsonl|csv] [-o FILE]")
+		fmt.Fprintln(stderr, "       port-chaser history import [-format jsonl|csv] FILE")
+		fmt.Fprintln(stderr, "       port-chaser history prune [-max-age AGE] [-max-rows N] [-vacuum]")
+	}
+	if len(args) == 0 {
+		usage()
+		return 2
+	}
+
+	switch args[0] {
+	case "list":
+		return runHistoryList(args[1:], stdout, stderr)
+	case "export":
+		return runHistoryExport(args[1:], stdout, stderr)
+	case "import":
+		return runHistoryImport(args[1:], stdout, stderr)
+	case "prune":
+		return runHistoryPrune(args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "error: unknown history command %q\n", args[0])
+		usage()
+		return 2
+	}
+}
+
+// runHistoryList prints one page of the kill history matching the filter flags, newest first.
+// When more entries match, it prints the -cursor value that continues the listing.
+func runHistoryList(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("history list", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	filters := historyFilterFlags(fs)
+	limit := fs.Int("limit", 20, "entries per page")
+	cursor := fs.String("cursor", "", "continue from a previous page")
+	jsonOutput := fs.Bool("json", false, "print the page as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: port-chaser history list [flags]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	query, err := filters()
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	query.Limit, query.Cursor = *limit, *cursor
+	if *limit <= 0 {
+		fmt.Fprintf(stderr, "error: invalid -limit %d\n", *limit)
+		return 2
+	}
+
+	sto, err := openHistory(loadConfig(), stderr, false)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	defer sto.Close()
+
+	page, err := sto.QueryHistory(query)
+	if errors.Is(err, storage.ErrInvalidCursor) {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+
+	if *jsonOutput {
+		if page.Entries == nil {
+			page.Entries = []models.HistoryEntry{}
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(page); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if len(page.Entries) == 0 {
+		fmt.Fprintln(stdout, "No matching history")
+		return 0
+	}
+	labels := loadLabels(sto, stderr)
+	for _, entry := range page.Entries {
+		result := "killed"
+		if entry.Outcome != "" {
+			result = entry.Outcome
+		}
+		if entry.Failed {
+			result = "failed: " + entry.Error
+		}
+		// The recorded working directory lets project labels match
+		port := models.PortInfo{PortNumber: entry.PortNumber, ProcessName: entry.ProcessName}
+		if entry.Launch != nil {
+			port.WorkDir = entry.Launch.Cwd
+		}
+		fmt.Fprintf(stdout, "%s  %5d  %-16s PID %-7d %s\n",
+			entry.KilledAt.Format("2006-01-02 15:04:05"), entry.PortNumber, entry.ProcessName+labelSuffix(labels, port), entry.PID, result)
+	}
+	if page.NextCursor != "" {
+		fmt.Fprintf(stdout, "\nMore entries: rerun with -cursor %s\n", page.NextCursor)
+	}
+	return 0
+}
+
+// historyFilterFlags registers the history filter flags on fs. The returned function builds
+// the query from them once fs has been parsed.
+func historyFilterFlags(fs *flag.FlagSet) func() (models.HistoryQuery, error) {
+	ports := fs.String("port", "", "only this port or port range (e.g. 3000 or 3000-3999)")
+	name := fs.String("process", "", "only this process name (case-insensitive)")
+	command := fs.String("command", "", "only commands containing this text (case-insensitive)")
+	since := fs.String("since", "", "only kills after this date or this long ago (e.g. 2026-01-31, 7d, 12h)")
+	until := fs.String("until", "", "only kills before this date or this long ago")
+	status := fs.String("status", "", "only succeeded or failed kills")
+
+	return func() (models.HistoryQuery, error) {
+		query := models.HistoryQuery{ProcessName: *name, Command: *command}
+		if *ports != "" {
+			r, err := policy.ParseRange(*ports)
+			if err != nil {
+				return query, fmt.Errorf("invalid -port: %w", err)
+			}
+			if r.Min == r.Max {
+				query.Port = r.Min
+			} else {
+				query.PortMin, query.PortMax = r.Min, r.Max
+			}
+		}
+		now := time.Now()
+		var err error
+		if query.Since, err = parseTimeBound(*since, now); err != nil {
+			return query, fmt.Errorf("invalid -since: %w", err)
+		}
+		if query.Until, err = parseTimeBound(*until, now); err != nil {
+			return query, fmt.Errorf("invalid -until: %w", err)
+		}
+		switch s := models.HistoryStatus(*status); s {
+		case models.StatusAny, models.StatusSucceeded, models.StatusFailed:
+			query.Status = s
+		default:
+			return query, fmt.Errorf("invalid -status %q (want succeeded or failed)", *status)
+		}
+		return query, nil
+	}
+}
+
+// runHistoryExport writes the history matching the filter flags to a file or stdout.
+func runHistoryExport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("history export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	filters := historyFilterFlags(fs)
+	format := fs.String("format", "", "jsonl or csv (default: from the -o extension, else jsonl)")
+	output := fs.String("o", "", "file to write (default: stdout)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: port-chaser history export [flags]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+	query, err := filters()
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	f, err := historyFormat(*format, *output)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+
+	sto, err := openHistory(loadConfig(), stderr, false)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	defer sto.Close()
+
+	w := stdout
+	if *output != "" {
+		file, err := os.OpenFile(*output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+
+	n, err := storage.Export(sto, w, f, query)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	if *output != "" {
+		fmt.Fprintf(stdout, "Exported %d entries to %s\n", n, *output)
+	}
+	return 0
+}
+
+// runHistoryImport adds the entries of an exported file (or stdin for "-") that aren't in the history yet.
+func runHistoryImport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("history import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "", "jsonl or csv (default: from the file extension, else jsonl)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: port-chaser history import [flags] FILE")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	path := fs.Arg(0)
+	f, err := historyFormat(*format, path)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		r = file
+	}
+
+	sto, err := openHistory(loadConfig(), stderr, false)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	defer sto.Close()
+
+	result, err := storage.Import(sto, r, f)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Imported %d entries (%d duplicates skipped)\n", result.Added, result.Skipped())
+	return 0
+}
+
+// historyFormat returns the -format flag's format, or the one the file extension implies.
+func historyFormat(name, path string) (storage.Format, error) {
+	if name != "" {
+		return storage.ParseFormat(name)
+	}
+	return storage.FormatForPath(path), nil
+}
+
+// parseTimeBound parses a -since or -until value: a date (2006-01-02), an RFC 3339 time,
+// or a duration before now (7d, 12h). Empty means no bound.
+func parseTimeBound(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	age, err := config.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date or duration", s)
+	}
+	return now.Add(-age), nil
+}
+
+// runHistoryPrune applies the retention limits (from flags, falling back to the config) right away.
+func runHistoryPrune(args []string, stdout, stderr io.Writer) int {
+	cfg := loadConfig()
+
+	fs := flag.NewFlagSet("history prune", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	maxAge := fs.String("max-age", cfg.History.MaxAge.String(), "delete entries older than this (e.g. 30d, 72h; 0 keeps all)")
+	maxRows := fs.Int("max-rows", cfg.History.MaxRows, "keep only the newest N entries (0 means unlimited)")
+	vacuum := fs.Bool("vacuum", false, "compact the database file after pruning")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: port-chaser history prune [flags]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+	age, err := config.ParseDuration(*maxAge)
+	if err != nil || age < 0 {
+		fmt.Fprintf(stderr, "error: invalid -max-age %q\n", *maxAge)
+		return 2
+	}
+	if *maxRows < 0 {
+		fmt.Fprintf(stderr, "error: invalid -max-rows %d\n", *maxRows)
+		return 2
+	}
+
+	sto, err := openHistory(cfg, stderr, false)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	defer sto.Close()
+
+	removed, err := sto.Prune(age, *maxRows)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Pruned %d entries\n", removed)
+	// The port timeline follows the same age limit
+	if events, err := sto.PrunePortEvents(age); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	} else if events > 0 {
+		fmt.Fprintf(stdout, "Pruned %d timeline events\n", events)
+	}
+
+	if *vacuum {
+		if err := sto.Compact(); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 1
+		}
+		fmt.Fprintln(stdout, "Compacted the history database")
+	}
+	return 0
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/platform"
+	"github.com/manson/port-chaser/internal/storage"
+)
+
+func TestRunHistory_Usage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no subcommand", nil},
+		{"unknown subcommand", []string{"purge"}},
+		{"bad max age", []string{"prune", "-max-age", "soon"}},
+		{"negative max rows", []string{"prune", "-max-rows", "-1"}},
+		{"extra argument", []string{"prune", "all"}},
+		{"bad port range", []string{"list", "-port", "9-1"}},
+		{"bad since", []string{"list", "-since", "yesterday"}},
+		{"bad status", []string{"list", "-status", "maybe"}},
+		{"bad limit", []string{"list", "-limit", "0"}},
+		{"bad cursor", []string{"list", "-cursor", "nope"}},
+	}
+
+	setHome(t, t.TempDir())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := runHistory(tt.args, &stdout, &stderr); code != 2 {
+				t.Errorf("runHistory(%v) = %d, want 2 (stderr: %s)", tt.args, code, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunHistory_Prune(t *testing.T) {
+	dir := t.TempDir()
+	setHome(t, dir)
+
+	sto, err := storage.NewSQLite(storage.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	now := time.Now()
+	for i, age := range []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour, 40 * 24 * time.Hour} {
+		entry := models.HistoryEntry{PortNumber: 3000 + i, ProcessName: "node", KilledAt: now.Add(-age)}
+		if err := sto.RecordKill(entry); err != nil {
+			t.Fatalf("RecordKill() error = %v", err)
+		}
+	}
+	sto.Close()
+
+	var stdout, stderr bytes.Buffer
+	if code := runHistory([]string{"prune", "-max-age", "30d", "-max-rows", "2", "-vacuum"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("runHistory() = %d, stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Pruned 2 entries") || !strings.Contains(stdout.String(), "Compacted") {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+
+	if _, err := os.Stat(platform.GetHistoryPath()); err != nil {
+		t.Fatalf("history database missing: %v", err)
+	}
+	sto, err = storage.NewSQLite(storage.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	defer sto.Close()
+	history, err := sto.GetHistory(10)
+	if err != nil || len(history) != 2 || history[0].PortNumber != 3000 || history[1].PortNumber != 3001 {
+		t.Errorf("GetHistory() = %+v, %v; want the two newest entries", history, err)
+	}
+}
+
+func TestRunHistory_List(t *testing.T) {
+	dir := t.TempDir()
+	setHome(t, dir)
+
+	sto, err := storage.NewSQLite(storage.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	now := time.Now()
+	for i := 1; i <= 5; i++ {
+		entry := models.HistoryEntry{PortNumber: 3000 + i, ProcessName: "node", PID: i, KilledAt: now.Add(-time.Duration(i) * time.Hour)}
+		if i == 4 {
+			entry.ProcessName, entry.Failed, entry.Error = "vite", true, "permission denied"
+		}
+		if err := sto.RecordKill(entry); err != nil {
+			t.Fatalf("RecordKill() error = %v", err)
+		}
+	}
+	sto.Close()
+
+	var stdout, stderr bytes.Buffer
+	if code := runHistory([]string{"list", "-status", "failed"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("runHistory() = %d, stderr: %s", code, stderr.String())
+	}
+	if out := stdout.String(); !strings.Contains(out, "3004") || !strings.Contains(out, "failed: permission denied") || strings.Contains(out, "3001") {
+		t.Errorf("failed kills = %q", out)
+	}
+
+	// Page through the JSON output with the cursor
+	var ports []int
+	base := []string{"list", "-process", "NODE", "-since", "4h30m", "-limit", "2", "-json"}
+	args := base
+	for pages := 0; pages < 3; pages++ {
+		stdout.Reset()
+		if code := runHistory(args, &stdout, &stderr); code != 0 {
+			t.Fatalf("runHistory(%v) = %d, stderr: %s", args, code, stderr.String())
+		}
+		var page models.HistoryPage
+		if err := json.Unmarshal(stdout.Bytes(), &page); err != nil {
+			t.Fatalf("output is not JSON: %v\n%s", err, stdout.String())
+		}
+		for _, entry := range page.Entries {
+			ports = append(ports, entry.PortNumber)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		args = append(base[:len(base):len(base)], "-cursor", page.NextCursor)
+	}
+	if !reflect.DeepEqual(ports, []int{3001, 3002, 3003}) {
+		t.Errorf("paged ports = %v, want the node kills of the last 4.5 hours", ports)
+	}
+}
+
+func TestRunHistory_ExportImport(t *testing.T) {
+	setHome(t, t.TempDir())
+	sto, err := storage.NewSQLite(storage.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	now := time.Now()
+	for i := 1; i <= 3; i++ {
+		if err := sto.RecordKill(models.HistoryEntry{PortNumber: 3000 + i, ProcessName: "node", PID: i, KilledAt: now.Add(-time.Duration(i) * time.Hour)}); err != nil {
+			t.Fatalf("RecordKill() error = %v", err)
+		}
+	}
+	sto.Close()
+
+	for _, ext := range []string{"jsonl", "csv"} {
+		t.Run(ext, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "history."+ext)
+			var stdout, stderr bytes.Buffer
+			if code := runHistory([]string{"export", "-port", "3001-3002", "-o", file}, &stdout, &stderr); code != 0 {
+				t.Fatalf("export = %d, stderr: %s", code, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), "Exported 2 entries") {
+				t.Errorf("export stdout = %q", stdout.String())
+			}
+
+			// Import on another "machine", twice
+			t.Setenv(dbEnv, filepath.Join(t.TempDir(), "other.db"))
+			for _, want := range []string{"Imported 2 entries (0 duplicates skipped)", "Imported 0 entries (2 duplicates skipped)"} {
+				stdout.Reset()
+				if code := runHistory([]string{"import", file}, &stdout, &stderr); code != 0 {
+					t.Fatalf("import = %d, stderr: %s", code, stderr.String())
+				}
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("import stdout = %q, want %q", stdout.String(), want)
+				}
+			}
+		})
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runHistory([]string{"export", "-format", "xml"}, &stdout, &stderr); code != 2 {
+		t.Errorf("export -format xml = %d, want 2", code)
+	}
+	if code := runHistory([]string{"import", filepath.Join(t.TempDir(), "missing.jsonl")}, &stdout, &stderr); code != 1 {
+		t.Errorf("import of a missing file = %d, want 1", code)
+	}
+}
+
+func TestRunHistory_Redaction(t *testing.T) {
+	setHome(t, t.TempDir())
+	sto, err := storage.NewSQLite(storage.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	// Recorded by a version without redaction
+	entry := models.HistoryEntry{PortNumber: 3000, ProcessName: "node", PID: 1, KilledAt: time.Now(),
+		Command: "node app.js --token=hunter2",
+		Launch:  &models.LaunchContext{Args: []string{"node", "app.js", "--token=hunter2"}, Env: []string{"NPM_TOKEN=npm_hunter3"}}}
+	if err := sto.RecordKill(entry); err != nil {
+		t.Fatalf("RecordKill() error = %v", err)
+	}
+	sto.Close()
+
+	file := filepath.Join(t.TempDir(), "history.jsonl")
+	var stdout, stderr bytes.Buffer
+	if code := runHistory([]string{"export", "-o", file}, &stdout, &stderr); code != 0 {
+		t.Fatalf("export = %d, stderr: %s", code, stderr.String())
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(data), "hunter2") || strings.Contains(string(data), "hunter3") || !strings.Contains(string(data), "NPM_TOKEN="+models.RedactionMask) {
+		t.Errorf("export = %s, want the token redacted in the command and launch context", data)
+	}
+
+	// Opting out shows the command line as it was recorded
+	configDir := platform.GetConfigPath()
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"redaction": {"disabled": true}}`), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	stdout.Reset()
+	if code := runHistory([]string{"list", "-json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("list = %d, stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "hunter2") {
+		t.Errorf("list with redaction disabled = %q, want the command as recorded", stdout.String())
+	}
+}
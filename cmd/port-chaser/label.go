@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/storage"
+)
+
+// runLabel implements `port-chaser label list|set|rm`: names and notes for ports, kept with the history.
+// It returns the process exit code: 0 on success, 1 if the labels couldn't be read or written, 2 on usage errors.
+func runLabel(args []string, stdout, stderr io.Writer) int {
+	usage := func() {
+		fmt.Fprintln(stderr, "Usage: port-chaser label list [-json]")
+		fmt.Fprintln(stderr, "       port-chaser label set [-process NAME] [-project DIR] [-note TEXT] PORT LABEL")
+		fmt.Fprintln(stderr, "       port-chaser label rm [-process NAME] [-project DIR] PORT")
+	}
+	if len(args) == 0 {
+		usage()
+		return 2
+	}
+
+	switch args[0] {
+	case "list":
+		return runLabelList(args[1:], stdout, stderr)
+	case "set":
+		return runLabelSet(args[1:], stdout, stderr)
+	case "rm":
+		return runLabelRemove(args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "error: unknown label command %q\n", args[0])
+		usage()
+		return 2
+	}
+}
+
+// runLabelList prints every label, ordered by port.
+func runLabelList(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("label list", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	jsonOutput := fs.Bool("json", false, "print the labels as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: port-chaser label list [-json]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	sto, err := openStorage(loadConfig(), stderr, false)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	defer sto.Close()
+
+	labels, err := sto.Labels()
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+
+	if *jsonOutput {
+		if labels == nil {
+			labels = []models.PortLabel{}
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(labels); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if len(labels) == 0 {
+		fmt.Fprintln(stdout, "No labels")
+		return 0
+	}
+	for _, l := range labels {
+		scope := l.Scope()
+		if scope != "" {
+			scope = " [" + scope + "]"
+		}
+		fmt.Fprintf(stdout, "%5d  %s%s\n", l.Port, l.Label, scope)
+		if l.Note != "" {
+			fmt.Fprintf(stdout, "       %s\n", l.Note)
+		}
+	}
+	return 0
+}
+
+// runLabelSet labels a port, replacing the label with the same port and scope.
+func runLabelSet(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("label set", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	scope := labelScopeFlags(fs)
+	note := fs.String("note", "", "free-text note shown with the label")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: port-chaser label set [flags] PORT LABEL")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return 2
+	}
+	label, err := scope(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	label.Label = strings.Join(fs.Args()[1:], " ")
+	label.Note = *note
+	if label = label.Normalized(); label.Label == "" {
+		fmt.Fprintln(stderr, "error: the label is empty")
+		return 2
+	}
+
+	sto, err := openStorage(loadConfig(), stderr, false)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	defer sto.Close()
+
+	if err := sto.SaveLabel(label); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Labelled port %d %q\n", label.Port, label.Label)
+	return 0
+}
+
+// runLabelRemove deletes the label with the given port and scope.
+func runLabelRemove(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("label rm", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	scope := labelScopeFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: port-chaser label rm [flags] PORT")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	label, err := scope(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+
+	sto, err := openStorage(loadConfig(), stderr, false)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	defer sto.Close()
+
+	deleted, err := sto.DeleteLabel(label)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	if !deleted {
+		fmt.Fprintf(stderr, "error: no label on port %d with that scope\n", label.Port)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Removed the label from port %d\n", label.Port)
+	return 0
+}
+
+// labelScopeFlags registers the -process and -project flags on fs. The returned function builds
+// a label scoped by them for the port argument once fs has been parsed.
+func labelScopeFlags(fs *flag.FlagSet) func(port string) (models.PortLabel, error) {
+	name := fs.String("process", "", "only when this process holds the port (case-insensitive)")
+	project := fs.String("project", "", "only for processes working in this directory or below it")
+	return func(port string) (models.PortLabel, error) {
+		n, err := strconv.Atoi(port)
+		if err != nil || n < 1 || n > 65535 {
+			return models.PortLabel{}, fmt.Errorf("invalid port %q", port)
+		}
+		label := models.PortLabel{Port: n, ProcessName: *name}
+		if *project != "" {
+			// Relative to where the command runs, e.g. -project .
+			if label.Project, err = filepath.Abs(*project); err != nil {
+				return models.PortLabel{}, err
+			}
+		}
+		return label, nil
+	}
+}
+
+// loadLabels returns the labels for decorating CLI output; if they can't be read, it warns and
+// returns none, since the output is still useful without them.
+func loadLabels(sto storage.Storage, stderr io.Writer) []models.PortLabel {
+	labels, err := sto.Labels()
+	if err != nil {
+		fmt.Fprintf(stderr, "warning: %v\n", err)
+	}
+	return labels
+}
+
+// labelSuffix returns " (LABEL)" for a port that has a label, or "".
+func labelSuffix(labels []models.PortLabel, port models.PortInfo) string {
+	if l, ok := models.LabelFor(labels, port); ok && l.Label != "" {
+		return " (" + l.Label + ")"
+	}
+	return ""
+}
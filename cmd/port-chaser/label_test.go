@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/storage"
+)
+
+func TestRunLabel(t *testing.T) {
+	setHome(t, t.TempDir())
+	project := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	for _, args := range [][]string{
+		{"set", "-note", "ask the web team", "3000", "storefront"},
+		{"set", "-process", "ssh", "5433", "staging", "DB", "tunnel"},
+		{"set", "-project", project, "8080", "api"},
+	} {
+		if code := runLabel(args, &stdout, &stderr); code != 0 {
+			t.Fatalf("runLabel(%v) = %d, stderr: %s", args, code, stderr.String())
+		}
+	}
+
+	stdout.Reset()
+	if code := runLabel([]string{"list"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("runLabel(list) = %d, stderr: %s", code, stderr.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{"3000  storefront\n", "ask the web team", "5433  staging DB tunnel [ssh]", "8080  api [in " + filepath.Clean(project) + "]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("label list missing %q:\n%s", want, out)
+		}
+	}
+
+	// Labels show up in the history
+	sto, err := storage.NewSQLite(storage.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	launch := &models.LaunchContext{Args: []string{"api"}, Cwd: filepath.Join(project, "cmd")}
+	for _, entry := range []models.HistoryEntry{
+		{PortNumber: 3000, ProcessName: "node", KilledAt: time.Now().Add(-time.Minute)},
+		{PortNumber: 8080, ProcessName: "api", Launch: launch, KilledAt: time.Now()},
+	} {
+		if err := sto.RecordKill(entry); err != nil {
+			t.Fatalf("RecordKill() error = %v", err)
+		}
+	}
+	sto.Close()
+	stdout.Reset()
+	if code := runHistory([]string{"list"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("runHistory(list) = %d, stderr: %s", code, stderr.String())
+	}
+	if out := stdout.String(); !strings.Contains(out, "node (storefront)") || !strings.Contains(out, "api (api)") {
+		t.Errorf("history should show labels:\n%s", out)
+	}
+
+	stdout.Reset()
+	if code := runLabel([]string{"rm", "-process", "SSH", "5433"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("runLabel(rm) = %d, stderr: %s", code, stderr.String())
+	}
+	if code := runLabel([]string{"rm", "5433"}, &stdout, &stderr); code != 1 {
+		t.Errorf("removing a label that isn't there = %d, want 1", code)
+	}
+	stdout.Reset()
+	if code := runLabel([]string{"list", "-json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("runLabel(list -json) = %d, stderr: %s", code, stderr.String())
+	}
+	var labels []models.PortLabel
+	if err := json.Unmarshal(stdout.Bytes(), &labels); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout.String())
+	}
+	if len(labels) != 2 || labels[0].Note != "ask the web team" {
+		t.Errorf("labels = %+v, want two left", labels)
+	}
+
+	for _, args := range [][]string{nil, {"add"}, {"set", "3000"}, {"set", "http", "web"}, {"set", "3000", " "}, {"rm"}, {"list", "extra"}} {
+		if code := runLabel(args, &stdout, &stderr); code != 2 {
+			t.Errorf("runLabel(%v) = %d, want 2", args, code)
+		}
+	}
+}
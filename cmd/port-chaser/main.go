@@ -2,16 +2,25 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/manson/port-chaser/internal/app"
+	"github.com/manson/port-chaser/internal/config"
+	"github.com/manson/port-chaser/internal/detector"
+	"github.com/manson/port-chaser/internal/hooks"
 	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/platform"
+	"github.com/manson/port-chaser/internal/policy"
 	"github.com/manson/port-chaser/internal/process"
+	"github.com/manson/port-chaser/internal/recommend"
+	"github.com/manson/port-chaser/internal/redact"
 	"github.com/manson/port-chaser/internal/scanner"
-	"github.com/manson/port-chaser/internal/storage"
+	"github.com/manson/port-chaser/internal/timeline"
 )
 
 const (
@@ -24,15 +33,32 @@ const (
 // main is the application entry point.
 // It handles command-line flags and starts the Bubbletea TUI program.
 func main() {
+	// Options that apply to every command come first
+	args, err := parseGlobalFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(2)
+	}
+
 	// Handle command-line flags for version and help
-	if len(os.Args) > 1 {
-		switch os.Args[1] {
+	if len(args) > 0 {
+		switch args[0] {
 		case "-v", "--version", "version":
 			fmt.Printf("%s v%s\n", appName, version)
 			os.Exit(0)
 		case "-h", "--help", "help":
 			printHelp()
 			os.Exit(0)
+		case "kill":
+			os.Exit(runKill(args[1:], os.Stdout, os.Stderr))
+		case "history":
+			os.Exit(runHistory(args[1:], os.Stdout, os.Stderr))
+		case "stats":
+			os.Exit(runStats(args[1:], os.Stdout, os.Stderr))
+		case "timeline":
+			os.Exit(runTimeline(args[1:], os.Stdout, os.Stderr))
+		case "label":
+			os.Exit(runLabel(args[1:], os.Stdout, os.Stderr))
 		}
 	}
 
@@ -65,19 +91,24 @@ func main() {
 // initializeModel creates the initial application state with all dependencies wired up.
 // This is where dependency injection happens for testability.
 func initializeModel() app.Model {
-	killer := process.NewProcessKiller()
-	commonPortScanner := scanner.NewCommonPortScanner()
+	cfg := loadConfig()
+
+	// Initialize storage; if no persistent backend opens, history is kept in memory
+	raw, _ := openStorage(cfg, os.Stderr, true)
+	// History keeps the real launch contexts so entries can be restarted; the TUI shows only their cwd
+	sto := redact.StoreForRestart(raw, newRedactor(cfg))
+
+	// Scans also carry each port's kill history and process activity, scored for the [!] recommendations
+	pipeline := newScanner(cfg, detector.NewKillHistoryDetector(sto), detector.NewActivityDetector(), recommend.New())
 
-	// Initialize storage (SQLite backend)
-	// If storage initialization fails, the app will work without persistence
-	var sto app.Storage
-	sqliteStorage, err := storage.NewSQLite(storage.DefaultConfig())
-	if err == nil {
-		sto = sqliteStorage
+	killer := newKillerAdapter(cfg)
+	retention := app.Retention{
+		MaxAge:   cfg.History.MaxAge.Duration,
+		MaxRows:  cfg.History.MaxRows,
+		Interval: cfg.History.PruneInterval.Duration,
 	}
-	// If err != nil, sto remains nil and the app works without persistence
 
-	return app.Model{
+	model := app.Model{
 		Ports:          []models.PortInfo{},
 		FilteredPorts:  []models.PortInfo{},
 		SelectedIndex:  -1,
@@ -87,13 +118,132 @@ func initializeModel() app.Model {
 		Loading:        true,
 		Width:          80,
 		Height:         24,
-		Scanner:        commonPortScanner,
-		Killer:         &killerAdapter{killer: killer},
+		Scanner:        pipeline,
+		Killer:         killer,
 		Storage:        sto,
+		Launcher:       &launcherAdapter{timeout: cfg.Kill.RestartTimeout.Duration},
+		Policy:         killer.killer.Policy,
+		Hooks:          hooks.NewRunner(buildHooks(cfg.Hooks)...),
+		Operator:       platform.GetUserName(),
+		Retention:      retention,
 		PreviousPorts:  make(map[int]models.PortInfo),
 		NewPorts:       make(map[int]bool),
 		RemovedPorts:   make(map[int]bool),
 	}
+	// The timeline is opt-in; it writes to storage on every scan that changes a listener
+	if cfg.Timeline.Enabled {
+		model.Timeline = timeline.NewRecorder(sto)
+	}
+	return model
+}
+
+// loadConfig loads user settings; a broken config file falls back to defaults with a warning.
+func loadConfig() *config.Config {
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v (using defaults)\n", err)
+	}
+	return cfg
+}
+
+// newScanner creates the port scanner with the process manager detector and any extra detectors attached.
+// Command lines are redacted last, after every detector that may match on them.
+func newScanner(cfg *config.Config, extra ...detector.Detector) *scanner.Pipeline {
+	detectors := append([]detector.Detector{detector.NewManagerDetector()}, extra...)
+	detectors = append(detectors, newRedactor(cfg))
+	return scanner.NewPipeline(scanner.NewCommonPortScanner(), detectors...)
+}
+
+// newRedactor creates the secret redactor with the built-in and configured patterns,
+// or returns nil when redaction is disabled.
+func newRedactor(cfg *config.Config) *redact.Redactor {
+	if cfg.Redaction.Disabled {
+		return nil
+	}
+	r, err := redact.New(cfg.Redaction.Patterns...)
+	if err != nil {
+		// config.Load rejects invalid patterns, but never leave secrets in the clear
+		r, _ = redact.New()
+	}
+	return r
+}
+
+// newKillerAdapter creates the killer configured with the user's grace period and strategies.
+func newKillerAdapter(cfg *config.Config) *killerAdapter {
+	killer := process.NewProcessKillerWithGracePeriod(cfg.Kill.GracePeriod.Duration)
+	killer.ReleaseTimeout = cfg.Kill.ReleaseTimeout.Duration
+	// User strategies take precedence over the built-in ones (postgres, nginx)
+	killer.Strategies = append(buildStrategies(cfg.Kill.Strategies), killer.Strategies...)
+	// Configured rules are evaluated before the built-in ones, so they can loosen or tighten them
+	killer.Policy = policy.New(cfg.Policy.Effective()...)
+
+	adapter := &killerAdapter{
+		killer:        killer,
+		respawnWindow: cfg.Kill.RespawnWindow.Duration,
+		escalate:      cfg.Kill.EscalateCommand,
+		redactor:      newRedactor(cfg),
+	}
+	// Without a path to ourselves there is nothing to run elevated, so escalation stays off
+	if exe, err := os.Executable(); err == nil {
+		adapter.executable = exe
+	}
+	return adapter
+}
+
+// buildStrategies converts configured kill strategies into process.KillStrategy values.
+// Strategies with an unknown signal are skipped with a warning rather than failing startup.
+func buildStrategies(configs []config.StrategyConfig) []process.KillStrategy {
+	var strategies []process.KillStrategy
+	for _, c := range configs {
+		strategy := process.KillStrategy{
+			Name:            c.Name,
+			ProcessNames:    c.ProcessNames,
+			CommandContains: c.CommandContains,
+			Ports:           c.Ports,
+		}
+
+		valid := true
+		for _, step := range c.Signals {
+			sig, err := process.ParseSignal(step.Signal)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: kill strategy %q: %v (skipped)\n", c.Name, err)
+				valid = false
+				break
+			}
+			strategy.Steps = append(strategy.Steps, process.SignalStep{Signal: sig, Wait: step.Wait.Duration})
+		}
+
+		if valid && len(strategy.Steps) > 0 {
+			strategies = append(strategies, strategy)
+		}
+	}
+	return strategies
+}
+
+// buildHooks converts configured hooks into hooks.Hook values.
+// Hooks with an unknown event or no command are skipped with a warning rather than failing startup.
+func buildHooks(configs []config.HookConfig) []hooks.Hook {
+	var built []hooks.Hook
+	for _, c := range configs {
+		event, err := hooks.ParseEvent(c.Event)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: hook %q: %v (skipped)\n", c.Name, err)
+			continue
+		}
+		if len(c.Command) == 0 {
+			fmt.Fprintf(os.Stderr, "warning: hook %q has no command (skipped)\n", c.Name)
+			continue
+		}
+		built = append(built, hooks.Hook{
+			Name:         c.Name,
+			Event:        event,
+			Command:      c.Command,
+			Timeout:      c.Timeout.Duration,
+			ProcessNames: c.ProcessNames,
+			Ports:        c.Ports,
+		})
+	}
+	return built
 }
 
 // printHelp displays usage information and keyboard shortcuts.
@@ -102,8 +252,21 @@ func printHelp() {
 
 Usage:
   port-chaser [options]
+  port-chaser kill [-signal SIG] [-scope process|group|session] [-dry-run] PORT
+  port-chaser kill [-pid PID] [-steps SIG:WAIT,...] [-json] [-confirm NAME] [-no-hooks] PORT
+  port-chaser history list [-port N|N-M] [-process NAME] [-command TEXT] [-since T] [-until T]
+                           [-status succeeded|failed] [-limit N] [-cursor CURSOR] [-json]
+  port-chaser history export [filters] [-format jsonl|csv] [-o FILE]
+  port-chaser history import [-format jsonl|csv] FILE
+  port-chaser history prune [-max-age AGE] [-max-rows N] [-vacuum]
+  port-chaser stats [-since T] [-until T] [-top N] [-json]
+  port-chaser timeline [-since T] [-until T] [-json] PORT
+  port-chaser label list [-json]
+  port-chaser label set [-process NAME] [-project DIR] [-note TEXT] PORT LABEL
+  port-chaser label rm [-process NAME] [-project DIR] PORT
 
 Options:
+  --db PATH         History database or .jsonl file (also $PORT_CHASER_DB)
   -v, --version     Show version
   -h, --help        Show help
 
@@ -111,9 +274,19 @@ TUI Key Bindings:
   Arrow/k/j         Navigate up/down
   gg, G             Jump to top/bottom
   Enter             Kill process
+  s (kill dialog)   Cycle the kill signal (default, SIGTERM, SIGINT, ...)
+  g (kill dialog)   Cycle the kill scope (process, process group, session)
+  R (kill dialog)   Kill and restart with the same command, cwd and env
+  m (kill dialog)   Stop via process manager (pm2, supervisord, ...)
+  p                 Suspend/resume process (SIGSTOP/SIGCONT)
   /                 Search
   d                 Toggle Docker filter
-  h                 Show history
+  o                 Sort by scan order or recommendation score
+  K                 Kill every recommended process ([!]), after confirmation
+  l                 Label the port and add a note (Tab: note, Ctrl+P: scope)
+  h                 Show history (R restarts the selected entry, / filters it)
+  S                 Show kill stats (w changes the period)
+  t                 Show who listened on the port over time (w changes the period)
   ?                 Show help
   r                 Refresh
   q, Ctrl+C         Quit
@@ -128,21 +301,140 @@ Project: https://github.com/manson/port-chaser
 // takes a PID and context separately. This adapter bridges the two.
 type killerAdapter struct {
 	killer *process.ProcessKiller
+	// respawnWindow is how long to watch the port after a kill (0 disables respawn detection)
+	respawnWindow time.Duration
+	// steps replaces the matching strategy's signal sequence when set (the kill subcommand's -steps)
+	steps []process.SignalStep
+	// escalate is the command prefix for elevated retries (e.g. sudo -n); empty disables escalation
+	escalate []string
+	// executable is the port-chaser binary the escalation command runs
+	executable string
+	// redactor masks secrets in the command lines of group and session members (nil disables it)
+	redactor *redact.Redactor
 }
 
+// killSlack is added to a strategy's total wait and the release timeout to form the kill context timeout.
+const killSlack = 2 * time.Second
+
 // Kill attempts to terminate the process associated with the given port.
-// It uses a 5-second timeout and returns an error if termination fails.
-func (a *killerAdapter) Kill(port models.PortInfo) error {
-	// Create a context with 5-second timeout (5 * 1e9 nanoseconds)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*1000000000)
+// It uses the matching kill strategy (or the signal chosen in opts) and a timeout long enough
+// for the whole signal sequence plus the port-release check; it returns an error if termination fails.
+// After a successful kill the port is watched for respawnWindow to catch supervisors restarting it.
+func (a *killerAdapter) Kill(port models.PortInfo, opts app.KillOptions) (*app.KillReport, error) {
+	strategy, err := a.strategy(port, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, err := process.ParseScope(opts.Scope)
+	if err != nil {
+		return nil, err
+	}
+	// Copy the killer so the per-kill scope doesn't leak into later kills
+	killer := *a.killer
+	killer.Scope = scope
+	killer.Confirmed = opts.Confirmed
+
+	ctx, cancel := context.WithTimeout(context.Background(), strategy.TotalWait()+killer.ReleaseTimeout+killSlack)
 	defer cancel()
 
-	result, err := a.killer.Kill(ctx, port.PID, &port)
+	// Capture how the process was started while it still exists, so it can be restarted
+	launch, _ := process.CaptureLaunch(port.PID)
+
+	result, err := killer.KillWithStrategy(ctx, port.PID, strategy, &port)
+	if errors.Is(err, process.ErrStaleTarget) {
+		return nil, fmt.Errorf("%w: %s", app.ErrStaleTarget, result.Message)
+	}
+	if errors.Is(err, os.ErrPermission) {
+		return nil, fmt.Errorf("%w: %s", app.ErrPermissionDenied, result.Message)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if !result.Success {
-		return fmt.Errorf("kill failed: %s", result.Message)
+		return nil, fmt.Errorf("kill failed: %s", result.Message)
+	}
+
+	report := &app.KillReport{
+		Method:   string(result.Method),
+		Strategy: result.Strategy,
+		Members:  a.redactor.Members(result.Members),
+		Release:  result.Release,
+		// Kept as captured so an immediate restart gets the real environment; history redacts it
+		Launch: launch,
+	}
+	// Respawn detection is best effort; a failed socket lookup just means no respawn is reported
+	report.Respawn, _ = process.WatchRespawn(context.Background(), &port, a.respawnWindow)
+	return report, nil
+}
+
+// DescribeKill returns the signal sequence Kill would use for the port, e.g. "SIGINT 10s → SIGKILL".
+func (a *killerAdapter) DescribeKill(port models.PortInfo, opts app.KillOptions) string {
+	strategy, err := a.strategy(port, opts)
+	if err != nil {
+		return err.Error()
+	}
+	if strategy.Name == "" || strategy.Name == process.SignalName(strategy.Steps[0].Signal) {
+		return strategy.Describe()
+	}
+	return strategy.Name + ": " + strategy.Describe()
+}
+
+// Members lists the processes a kill with the given scope ("group" or "session") would signal.
+func (a *killerAdapter) Members(port models.PortInfo, scope string) ([]models.ProcessMember, error) {
+	killScope, err := process.ParseScope(scope)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	members, err := process.Members(port.PID, killScope)
+	return a.redactor.Members(members), err
+}
+
+// strategy resolves the kill strategy for a port: the manually chosen signal if any,
+// then explicit steps, otherwise the first configured strategy matching the process.
+func (a *killerAdapter) strategy(port models.PortInfo, opts app.KillOptions) (process.KillStrategy, error) {
+	if opts.Signal == "" && len(a.steps) > 0 {
+		return process.KillStrategy{Name: "custom", Steps: a.steps}, nil
+	}
+	if opts.Signal == "" {
+		return a.killer.StrategyFor(&port), nil
+	}
+	sig, err := process.ParseSignal(opts.Signal)
+	if err != nil {
+		return process.KillStrategy{}, err
+	}
+	return process.SignalStrategy(sig, a.killer.GracePeriod), nil
+}
+
+// Suspend pauses the port's process with SIGSTOP without killing it.
+// confirmed passes "confirm" policy rules, as the typed confirmation does for kills.
+func (a *killerAdapter) Suspend(port models.PortInfo, confirmed bool) error {
+	killer := *a.killer
+	killer.Confirmed = confirmed
+	return killer.Suspend(port.PID, &port)
+}
+
+// Resume continues a process paused by Suspend.
+func (a *killerAdapter) Resume(port models.PortInfo) error {
+	return a.killer.Resume(port.PID, &port)
+}
+
+// launcherAdapter adapts process.Restart to the app.Launcher interface.
+type launcherAdapter struct {
+	// timeout is how long a restarted process gets to listen on its port again
+	timeout time.Duration
+}
+
+// Restart relaunches the process detached and waits for it to re-bind the port.
+func (l *launcherAdapter) Restart(launch models.LaunchContext, port int) (*models.RestartResult, error) {
+	return process.Restart(context.Background(), &launch, port, l.timeout)
+}
+
+// StopManaged stops a supervised process through its process manager.
+// It uses a 10-second timeout since manager CLIs wait for the app to shut down.
+func (a *killerAdapter) StopManaged(port models.PortInfo) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return process.StopManaged(ctx, &port)
 }
@@ -2,11 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"os/exec"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/manson/port-chaser/internal/app"
+	"github.com/manson/port-chaser/internal/config"
+	"github.com/manson/port-chaser/internal/hooks"
 	"github.com/manson/port-chaser/internal/models"
 	"github.com/manson/port-chaser/internal/process"
 )
@@ -271,7 +275,7 @@ func TestE2E_KillerAdapter(t *testing.T) {
 		IsSystem:    false,
 	}
 
-	err := adapter.Kill(port)
+	_, err := adapter.Kill(port, app.KillOptions{})
 	if err != nil {
 		t.Logf("kill attempt result (expected): %v", err)
 	}
@@ -454,3 +458,74 @@ func getTestMockPorts() []models.PortInfo {
 		},
 	}
 }
+
+func TestBuildStrategies(t *testing.T) {
+	configs := []config.StrategyConfig{
+		{
+			Name:            "java",
+			CommandContains: []string{"java -jar"},
+			Signals: []config.SignalStepConfig{
+				{Signal: "TERM", Wait: config.Duration{Duration: 15 * time.Second}},
+				{Signal: "SIGKILL", Wait: config.Duration{Duration: time.Second}},
+			},
+		},
+		{Name: "broken", ProcessNames: []string{"x"}, Signals: []config.SignalStepConfig{{Signal: "SIGNOPE"}}},
+		{Name: "empty", ProcessNames: []string{"y"}},
+	}
+
+	strategies := buildStrategies(configs)
+	if len(strategies) != 1 {
+		t.Fatalf("strategy count = %d, want 1 (invalid and empty skipped)", len(strategies))
+	}
+	if got := strategies[0].Describe(); got != "SIGTERM 15s → SIGKILL" {
+		t.Errorf("Describe() = %q", got)
+	}
+
+	killer := process.NewProcessKiller()
+	killer.Strategies = append(strategies, killer.Strategies...)
+	adapter := &killerAdapter{killer: killer}
+
+	java := models.PortInfo{ProcessName: "java", Command: "java -jar app.jar"}
+	if got := adapter.DescribeKill(java, app.KillOptions{}); got != "java: SIGTERM 15s → SIGKILL" {
+		t.Errorf("DescribeKill() = %q", got)
+	}
+	if got := adapter.DescribeKill(java, app.KillOptions{Signal: "SIGQUIT"}); got != "SIGQUIT 3s → SIGKILL" {
+		t.Errorf("DescribeKill(SIGQUIT) = %q", got)
+	}
+}
+
+func TestBuildHooks(t *testing.T) {
+	configs := []config.HookConfig{
+		{Name: "flush", Event: "pre_kill", Command: []string{"flush"}, Timeout: config.Duration{Duration: 30 * time.Second}, Ports: []int{8080}},
+		{Name: "typo", Event: "before_kill", Command: []string{"x"}},
+		{Name: "empty", Event: "post_kill"},
+	}
+
+	built := buildHooks(configs)
+	if len(built) != 1 {
+		t.Fatalf("hook count = %d, want 1 (invalid event and missing command skipped)", len(built))
+	}
+	if built[0].Event != hooks.PreKill || built[0].Timeout != 30*time.Second || built[0].Ports[0] != 8080 {
+		t.Errorf("hook = %+v", built[0])
+	}
+}
+
+func TestKillerAdapter_StaleTarget(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start test process: %v", err)
+	}
+	go cmd.Wait()
+	defer cmd.Process.Kill()
+
+	killer := process.NewProcessKiller()
+	killer.SystemProcessProtection = false
+	adapter := &killerAdapter{killer: killer}
+
+	// A start time from long ago means the scanned process is gone and the PID was reused
+	port := models.PortInfo{PortNumber: 3000, ProcessName: "node", PID: cmd.Process.Pid, StartTime: time.Unix(1000, 0)}
+	_, err := adapter.Kill(port, app.KillOptions{})
+	if !errors.Is(err, app.ErrStaleTarget) {
+		t.Fatalf("Kill() error = %v, want app.ErrStaleTarget", err)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// runStats implements `port-chaser stats [flags]`: which ports and processes get killed most,
+// when, how long kills take and how often they need SIGKILL.
+// It returns the process exit code: 0 on success, 1 if the stats couldn't be computed, 2 on usage errors.
+func runStats(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	since := fs.String("since", "30d", "only kills after this date or this long ago (e.g. 2026-01-31, 7d; empty for all)")
+	until := fs.String("until", "", "only kills before this date or this long ago")
+	top := fs.Int("top", models.DefaultStatsTop, "how many ports and processes to rank")
+	jsonOutput := fs.Bool("json", false, "print the statistics as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: port-chaser stats [flags]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	now := time.Now()
+	query := models.StatsQuery{Top: *top}
+	var err error
+	if query.Since, err = parseTimeBound(*since, now); err != nil {
+		fmt.Fprintf(stderr, "error: invalid -since: %v\n", err)
+		return 2
+	}
+	if query.Until, err = parseTimeBound(*until, now); err != nil {
+		fmt.Fprintf(stderr, "error: invalid -until: %v\n", err)
+		return 2
+	}
+	if *top <= 0 {
+		fmt.Fprintf(stderr, "error: invalid -top %d\n", *top)
+		return 2
+	}
+
+	sto, err := openHistory(loadConfig(), stderr, false)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	defer sto.Close()
+
+	stats, err := sto.Stats(query)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(stats); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	printStats(stdout, stats)
+	return 0
+}
+
+// printStats writes the statistics as text.
+func printStats(w io.Writer, stats models.KillStats) {
+	if stats.Attempts == 0 {
+		fmt.Fprintln(w, "No kills in this period")
+		return
+	}
+
+	fmt.Fprintf(w, "Kills:          %d (%d failed attempts)\n", stats.Kills, stats.Failed)
+	if stats.MedianDuration > 0 {
+		fmt.Fprintf(w, "Median time:    %s\n", stats.MedianDuration.Round(time.Millisecond))
+	}
+	if len(stats.Methods) > 0 {
+		methods := make([]string, 0, len(stats.Methods))
+		for _, m := range stats.Methods {
+			methods = append(methods, fmt.Sprintf("%s %d", m.Method, m.Kills))
+		}
+		fmt.Fprintf(w, "Methods:        %s\n", strings.Join(methods, ", "))
+		fmt.Fprintf(w, "Needed SIGKILL: %.0f%%\n", stats.ForcedRatio*100)
+	}
+
+	if len(stats.TopPorts) > 0 {
+		fmt.Fprintln(w, "\nTop ports:")
+		for _, c := range stats.TopPorts {
+			fmt.Fprintf(w, "  %-6d %-20s %d\n", c.Port, c.ProcessName, c.Kills)
+		}
+	}
+	if len(stats.TopProcesses) > 0 {
+		fmt.Fprintln(w, "\nTop processes:")
+		for _, c := range stats.TopProcesses {
+			fmt.Fprintf(w, "  %-27s %d\n", c.ProcessName, c.Kills)
+		}
+	}
+	if len(stats.PerDay) > 0 {
+		fmt.Fprintln(w, "\nKills per day:")
+		for _, d := range stats.PerDay {
+			fmt.Fprintf(w, "  %s  %d\n", d.Day, d.Kills)
+		}
+	}
+
+	fmt.Fprintln(w, "\nKills by hour:")
+	for hour, n := range stats.PerHour {
+		if n > 0 {
+			fmt.Fprintf(w, "  %02d:00  %d\n", hour, n)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/storage"
+)
+
+func TestRunStats(t *testing.T) {
+	setHome(t, t.TempDir())
+
+	sto, err := storage.NewSQLite(storage.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	now := time.Now()
+	for i, method := range []string{"SIGTERM", "SIGTERM", "SIGKILL"} {
+		entry := models.HistoryEntry{PortNumber: 3000, ProcessName: "node", Method: method, Duration: time.Second, KilledAt: now.Add(-time.Duration(i) * time.Hour)}
+		if err := sto.RecordKill(entry); err != nil {
+			t.Fatalf("RecordKill() error = %v", err)
+		}
+	}
+	if err := sto.RecordKill(models.HistoryEntry{PortNumber: 8080, ProcessName: "java", KilledAt: now.AddDate(0, 0, -60)}); err != nil {
+		t.Fatalf("RecordKill() error = %v", err)
+	}
+	sto.Close()
+
+	var stdout, stderr bytes.Buffer
+	if code := runStats(nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("runStats() = %d, stderr: %s", code, stderr.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{"Kills:          3 (0 failed attempts)", "Median time:    1s", "SIGTERM 2, SIGKILL 1", "Needed SIGKILL: 33%", "3000   node"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("stats output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "java") {
+		t.Errorf("the default 30 day window shouldn't include older kills:\n%s", out)
+	}
+
+	stdout.Reset()
+	if code := runStats([]string{"-since", "", "-top", "1", "-json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("runStats(-json) = %d, stderr: %s", code, stderr.String())
+	}
+	var stats models.KillStats
+	if err := json.Unmarshal(stdout.Bytes(), &stats); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout.String())
+	}
+	if stats.Attempts != 4 || len(stats.TopPorts) != 1 || stats.TopPorts[0].Port != 3000 || stats.MethodKills("SIGKILL") != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	for _, args := range [][]string{{"-since", "soon"}, {"-top", "0"}, {"extra"}} {
+		if code := runStats(args, &stdout, &stderr); code != 2 {
+			t.Errorf("runStats(%v) = %d, want 2", args, code)
+		}
+	}
+}
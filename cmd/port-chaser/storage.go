@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/manson/port-chaser/internal/config"
+	"github.com/manson/port-chaser/internal/platform"
+	"github.com/manson/port-chaser/internal/redact"
+	"github.com/manson/port-chaser/internal/storage"
+)
+
+// dbEnv is the environment variable that overrides where the history is kept.
+const dbEnv = "PORT_CHASER_DB"
+
+// dbFlag is the value of the global --db option ("" if it wasn't given).
+var dbFlag string
+
+// parseGlobalFlags consumes the options that come before the command (currently only --db PATH)
+// and returns the remaining arguments.
+func parseGlobalFlags(args []string) ([]string, error) {
+	for len(args) > 0 {
+		name, value, hasValue := strings.Cut(args[0], "=")
+		if name != "--db" && name != "-db" {
+			break
+		}
+		if !hasValue {
+			if len(args) < 2 {
+				return nil, fmt.Errorf("%s needs a path", name)
+			}
+			value, args = args[1], args[1:]
+		}
+		if value == "" {
+			return nil, fmt.Errorf("%s needs a path", name)
+		}
+		dbFlag = value
+		args = args[1:]
+	}
+	return args, nil
+}
+
+// historyPath returns where the history is kept: the --db option, then $PORT_CHASER_DB,
+// then storage.path from the config. Empty means the backend's default location.
+func historyPath(cfg *config.Config) string {
+	for _, path := range []string{dbFlag, os.Getenv(dbEnv), cfg.Storage.Path} {
+		if path != "" {
+			return platform.NormalizePath(path)
+		}
+	}
+	return ""
+}
+
+// openHistory opens the history like openStorage, redacting the command lines that go in and
+// the command lines and launch contexts that come out unless redaction is disabled in the config.
+func openHistory(cfg *config.Config, stderr io.Writer, allowMemory bool) (storage.Storage, error) {
+	sto, err := openStorage(cfg, stderr, allowMemory)
+	if err != nil {
+		return nil, err
+	}
+	return redact.Store(sto, newRedactor(cfg)), nil
+}
+
+// openStorage opens the history backend selected in the config. If SQLite can't be opened
+// (typically a static build without cgo), it falls back to the JSON Lines file, and warns on stderr.
+// With allowMemory, history is kept in memory as a last resort so the TUI still works;
+// the CLI commands pass false and report the error instead.
+func openStorage(cfg *config.Config, stderr io.Writer, allowMemory bool) (storage.Storage, error) {
+	stoCfg := storage.DefaultConfig()
+	stoCfg.DBPath = historyPath(cfg)
+	backend, err := storage.ParseBackend(cfg.Storage.Backend)
+	if err != nil {
+		fmt.Fprintf(stderr, "warning: %v (using %s)\n", err, storage.BackendSQLite)
+		backend = storage.BackendSQLite
+	}
+	// A .jsonl path picks its backend unless the config names one
+	if cfg.Storage.Backend == "" && strings.EqualFold(filepath.Ext(stoCfg.DBPath), ".jsonl") {
+		backend = storage.BackendJSONL
+	}
+	stoCfg.Backend = backend
+
+	sto, err := storage.Open(stoCfg)
+	if err == nil {
+		return sto, nil
+	}
+	// Don't write around history from a newer version; upgrading port-chaser brings it back
+	if backend == storage.BackendSQLite && !errors.Is(err, storage.ErrSchemaTooNew) {
+		fmt.Fprintf(stderr, "warning: %v (using %s history instead)\n", err, storage.BackendJSONL)
+		sto, err = storage.Open(storage.Config{Backend: storage.BackendJSONL})
+		if err == nil {
+			return sto, nil
+		}
+	}
+	if !allowMemory {
+		return nil, err
+	}
+	fmt.Fprintf(stderr, "warning: %v (history is kept in memory until exit)\n", err)
+	return storage.NewMemory(), nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/manson/port-chaser/internal/config"
+	"github.com/manson/port-chaser/internal/storage"
+)
+
+// setHome points every per-user directory (home, config and data) at dir and clears the
+// history overrides, so tests never touch the real history.
+func setHome(t *testing.T, dir string) {
+	t.Helper()
+	for _, name := range []string{"HOME", "USERPROFILE", "XDG_CONFIG_HOME", "XDG_DATA_HOME", "APPDATA", "LOCALAPPDATA"} {
+		t.Setenv(name, dir)
+	}
+	t.Setenv(dbEnv, "")
+	flag := dbFlag
+	dbFlag = ""
+	t.Cleanup(func() { dbFlag = flag })
+}
+
+func TestParseGlobalFlags(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantDB  string
+		wantRem []string
+		wantErr bool
+	}{
+		{[]string{"history", "list"}, "", []string{"history", "list"}, false},
+		{[]string{"--db", "/tmp/a.db", "stats"}, "/tmp/a.db", []string{"stats"}, false},
+		{[]string{"--db=/tmp/b.jsonl"}, "/tmp/b.jsonl", []string{}, false},
+		{[]string{"-db", "x.db", "--db", "y.db", "kill", "3000"}, "y.db", []string{"kill", "3000"}, false},
+		// Only options before the command are global
+		{[]string{"stats", "--db", "x.db"}, "", []string{"stats", "--db", "x.db"}, false},
+		{[]string{"--db"}, "", nil, true},
+		{[]string{"--db="}, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			setHome(t, t.TempDir())
+			rest, err := parseGlobalFlags(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGlobalFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if dbFlag != tt.wantDB || strings.Join(rest, " ") != strings.Join(tt.wantRem, " ") {
+				t.Errorf("parseGlobalFlags() = %q with --db %q, want %q with %q", rest, dbFlag, tt.wantRem, tt.wantDB)
+			}
+		})
+	}
+}
+
+func TestHistoryPath(t *testing.T) {
+	home := t.TempDir()
+	setHome(t, home)
+	cfg := config.Default()
+	if got := historyPath(cfg); got != "" {
+		t.Errorf("historyPath() = %q, want the backend default", got)
+	}
+
+	cfg.Storage.Path = "~/config.db"
+	if got, want := historyPath(cfg), filepath.Join(home, "config.db"); got != want {
+		t.Errorf("historyPath(config) = %q, want %q", got, want)
+	}
+	t.Setenv(dbEnv, filepath.Join(home, "env.db"))
+	if got, want := historyPath(cfg), filepath.Join(home, "env.db"); got != want {
+		t.Errorf("historyPath(env) = %q, want %q", got, want)
+	}
+	dbFlag = filepath.Join(home, "flag.jsonl")
+	if got := historyPath(cfg); got != dbFlag {
+		t.Errorf("historyPath(flag) = %q, want %q", got, dbFlag)
+	}
+
+	// The extension picks the backend
+	var stderr bytes.Buffer
+	sto, err := openStorage(cfg, &stderr, false)
+	if err != nil {
+		t.Fatalf("openStorage() error = %v", err)
+	}
+	defer sto.Close()
+	if _, ok := sto.(*storage.JSONL); !ok {
+		t.Errorf("openStorage(--db flag.jsonl) = %T, want the JSON Lines store", sto)
+	}
+}
+
+func TestOpenStorage(t *testing.T) {
+	home := t.TempDir()
+	setHome(t, home)
+
+	tests := []struct {
+		name        string
+		storage     config.StorageConfig
+		wantType    string
+		wantWarning string
+	}{
+		{"default", config.StorageConfig{}, "*storage.SQLite", ""},
+		{"jsonl", config.StorageConfig{Backend: "jsonl", Path: filepath.Join(home, "h.jsonl")}, "*storage.JSONL", ""},
+		{"memory", config.StorageConfig{Backend: "memory"}, "*storage.Memory", ""},
+		{"unknown backend", config.StorageConfig{Backend: "postgres"}, "*storage.SQLite", "unknown storage backend"},
+		// A directory can't be opened as a database, as when SQLite isn't compiled in
+		{"sqlite fails", config.StorageConfig{Path: home}, "*storage.JSONL", "using jsonl history instead"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Storage = tt.storage
+			var stderr bytes.Buffer
+			sto, err := openStorage(cfg, &stderr, false)
+			if err != nil {
+				t.Fatalf("openStorage() error = %v", err)
+			}
+			defer sto.Close()
+
+			if got := fmt.Sprintf("%T", sto); got != tt.wantType {
+				t.Errorf("openStorage() = %s, want %s", got, tt.wantType)
+			}
+			if tt.wantWarning == "" && stderr.Len() > 0 {
+				t.Errorf("unexpected warning: %s", stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.wantWarning) {
+				t.Errorf("warning = %q, want it to mention %q", stderr.String(), tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestOpenStorage_SchemaTooNew(t *testing.T) {
+	home := t.TempDir()
+	setHome(t, home)
+	path := filepath.Join(home, "newer.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	_, err = db.Exec("PRAGMA user_version = 99")
+	db.Close()
+	if err != nil {
+		t.Fatalf("PRAGMA error = %v", err)
+	}
+
+	cfg := config.Default()
+	cfg.Storage.Path = path
+	var stderr bytes.Buffer
+	// The CLI reports the error instead of writing history somewhere else
+	if _, err := openStorage(cfg, &stderr, false); err == nil {
+		t.Fatal("openStorage() should fail for a database from a newer version")
+	}
+
+	// The TUI keeps working with in-memory history and says so
+	sto, err := openStorage(cfg, &stderr, true)
+	if err != nil {
+		t.Fatalf("openStorage(allowMemory) error = %v", err)
+	}
+	defer sto.Close()
+	if _, ok := sto.(*storage.Memory); !ok {
+		t.Errorf("openStorage(allowMemory) = %T, want the in-memory store", sto)
+	}
+	if !strings.Contains(stderr.String(), "kept in memory") {
+		t.Errorf("warning = %q, want it to say history is kept in memory", stderr.String())
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/timeline"
+)
+
+// runTimeline implements `port-chaser timeline [flags] PORT`: who listened on the port between
+// -since and -until, as recorded by the TUI with timeline.enabled.
+// It returns the process exit code: 0 on success, 1 if the timeline couldn't be read, 2 on usage errors.
+func runTimeline(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("timeline", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	since := fs.String("since", "", "only listeners after this date or this long ago (e.g. 2026-01-31, 7d)")
+	until := fs.String("until", "", "only listeners before this date or this long ago")
+	jsonOutput := fs.Bool("json", false, "print the listeners as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: port-chaser timeline [flags] PORT")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	port, err := strconv.Atoi(fs.Arg(0))
+	if err != nil || port < 1 || port > 65535 {
+		fmt.Fprintf(stderr, "error: invalid port %q\n", fs.Arg(0))
+		return 2
+	}
+
+	now := time.Now()
+	from, err := parseTimeBound(*since, now)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: invalid -since: %v\n", err)
+		return 2
+	}
+	to, err := parseTimeBound(*until, now)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: invalid -until: %v\n", err)
+		return 2
+	}
+
+	sto, err := openHistory(loadConfig(), stderr, false)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	defer sto.Close()
+
+	holdings, err := timeline.Holdings(sto, port, from, to)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+
+	if *jsonOutput {
+		if holdings == nil {
+			holdings = []models.PortHolding{}
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(holdings); err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	printTimeline(stdout, port, holdings, loadLabels(sto, stderr))
+	return 0
+}
+
+// printTimeline writes who held port as text, oldest first, with their labels.
+func printTimeline(w io.Writer, port int, holdings []models.PortHolding, labels []models.PortLabel) {
+	if len(holdings) == 0 {
+		fmt.Fprintf(w, "No listeners recorded on port %d in this period\n", port)
+		return
+	}
+
+	const layout = "2006-01-02 15:04:05"
+	for _, h := range holdings {
+		from, until := "(before recording)", "now"
+		if !h.From.IsZero() {
+			from = h.From.Format(layout)
+		}
+		if !h.Open() {
+			until = h.Until.Format(layout)
+		}
+		process := fmt.Sprintf("%s (PID %d)", h.ProcessName, h.PID)
+		if h.ContainerName != "" {
+			process += " [" + h.ContainerName + "]"
+		}
+		process += labelSuffix(labels, models.PortInfo{PortNumber: h.Port, ProcessName: h.ProcessName})
+		fmt.Fprintf(w, "%-19s  %-19s  %s\n", from, until, process)
+		if h.Command != "" {
+			fmt.Fprintf(w, "    %s\n", h.Command)
+		}
+	}
+}
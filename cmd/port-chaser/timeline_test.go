@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/storage"
+)
+
+func TestRunTimeline(t *testing.T) {
+	setHome(t, t.TempDir())
+
+	sto, err := storage.NewSQLite(storage.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	now := time.Now()
+	node := models.PortInfo{PortNumber: 3000, PID: 101, ProcessName: "node", Command: "node server.js"}
+	vite := models.PortInfo{PortNumber: 3000, PID: 202, ProcessName: "vite"}
+	events := []models.PortEvent{
+		models.NewPortEvent(models.PortEventAppeared, node, now.Add(-72*time.Hour)),
+		models.NewPortEvent(models.PortEventDisappeared, node, now.Add(-48*time.Hour)),
+		models.NewPortEvent(models.PortEventAppeared, vite, now.Add(-time.Hour)),
+	}
+	if err := sto.RecordPortEvents(events); err != nil {
+		t.Fatalf("RecordPortEvents() error = %v", err)
+	}
+	sto.Close()
+
+	var stdout, stderr bytes.Buffer
+	if code := runTimeline([]string{"3000"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("runTimeline() = %d, stderr: %s", code, stderr.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{"node (PID 101)", "node server.js", "vite (PID 202)", "now"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("timeline output missing %q:\n%s", want, out)
+		}
+	}
+
+	// Who held the port two to three days ago
+	stdout.Reset()
+	if code := runTimeline([]string{"-since", "3d", "-until", "2d", "-json", "3000"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("runTimeline(-json) = %d, stderr: %s", code, stderr.String())
+	}
+	var holdings []models.PortHolding
+	if err := json.Unmarshal(stdout.Bytes(), &holdings); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout.String())
+	}
+	if len(holdings) != 1 || holdings[0].ProcessName != "node" || holdings[0].Open() {
+		t.Errorf("holdings = %+v, want only node", holdings)
+	}
+
+	stdout.Reset()
+	if code := runTimeline([]string{"8080"}, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "No listeners recorded on port 8080") {
+		t.Errorf("runTimeline(8080) = %d, %q", code, stdout.String())
+	}
+
+	for _, args := range [][]string{nil, {"http"}, {"70000"}, {"-since", "soon", "3000"}, {"3000", "8080"}} {
+		if code := runTimeline(args, &stdout, &stderr); code != 2 {
+			t.Errorf("runTimeline(%v) = %d, want 2", args, code)
+		}
+	}
+}
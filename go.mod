@@ -8,6 +8,7 @@ require (
 	github.com/charmbracelet/lipgloss v0.12.1
 	github.com/mattn/go-sqlite3 v1.14.22
 	github.com/shirou/gopsutil/v3 v3.24.5
+	golang.org/x/sys v0.24.0
 )
 
 require (
@@ -34,6 +35,5 @@ require (
 	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
 	github.com/yusufpapurcu/wmi v1.2.4 // indirect
 	golang.org/x/sync v0.8.0 // indirect
-	golang.org/x/sys v0.24.0 // indirect
 	golang.org/x/text v0.3.8 // indirect
 )
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -13,7 +14,7 @@ import (
 func TestHistoryPersistence(t *testing.T) {
 	// Create temporary storage
 	cfg := storage.Config{
-		DBPath:     "/tmp/test-history-persistence.db",
+		DBPath:     filepath.Join(t.TempDir(), "test-history-persistence.db"),
 		WALEnabled: false,
 		Timeout:    50,
 	}
@@ -1,13 +1,19 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/manson/port-chaser/internal/config"
+	"github.com/manson/port-chaser/internal/hooks"
 	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/policy"
 )
 
 // ViewMode represents the different UI views/states of the application.
@@ -23,6 +29,16 @@ const (
 	ViewModeHistory
 	// ViewModeHelp shows the help/usage information
 	ViewModeHelp
+	// ViewModeConfirmEscalate offers to retry a permission-denied kill with elevated privileges
+	ViewModeConfirmEscalate
+	// ViewModeConfirmQuit warns before quitting while processes are still suspended
+	ViewModeConfirmQuit
+	// ViewModeStats shows kill statistics computed from history
+	ViewModeStats
+	// ViewModeTimeline shows which processes listened on a port over time
+	ViewModeTimeline
+	// ViewModeConfirmKillRecommended lists the recommended processes before killing them all
+	ViewModeConfirmKillRecommended
 )
 
 // String returns the string representation of the ViewMode for logging/debugging
@@ -36,11 +52,41 @@ func (vm ViewMode) String() string {
 		return "history"
 	case ViewModeHelp:
 		return "help"
+	case ViewModeConfirmEscalate:
+		return "confirm_escalate"
+	case ViewModeConfirmQuit:
+		return "confirm_quit"
+	case ViewModeStats:
+		return "stats"
+	case ViewModeTimeline:
+		return "timeline"
+	case ViewModeConfirmKillRecommended:
+		return "confirm_kill_recommended"
 	default:
 		return "unknown"
 	}
 }
 
+// SortMode orders the main port list.
+type SortMode int
+
+const (
+	// SortByScan keeps the order the scanner found the ports in
+	SortByScan SortMode = iota
+	// SortByScore puts the ports most recommended for killing first
+	SortByScore
+)
+
+// String returns the name shown in the port list header.
+func (s SortMode) String() string {
+	switch s {
+	case SortByScore:
+		return "recommendation score"
+	default:
+		return "scan order"
+	}
+}
+
 // Model is the main application state for the Bubbletea TUI.
 // It holds all mutable state including port data, UI state, and configuration.
 // This follows the Bubbletea Model pattern: Init() -> Update() -> View() cycle.
@@ -55,6 +101,8 @@ type Model struct {
 	ViewMode ViewMode
 	// ShowDockerOnly when true filters to show only Docker container ports
 	ShowDockerOnly bool
+	// SortMode orders FilteredPorts
+	SortMode SortMode
 	// History contains records of previously killed processes
 	History []models.HistoryEntry
 	// Loading indicates a port scan is currently in progress
@@ -65,12 +113,78 @@ type Model struct {
 	StatusMessage string
 	// StatusMessageTimeout is when the status message should be cleared
 	StatusMessageTimeout time.Time
+	// HookRuns holds the hooks run by the last kill, shown below the status message
+	HookRuns []models.HookRun
+	// HookRunsTimeout is when the hook output should be cleared
+	HookRunsTimeout time.Time
 	// Quit when true signals the application should exit
 	Quit bool
 	// LastScanTime tracks when the last port scan was completed
 	LastScanTime time.Time
 	// KillConfirmationPort holds the port info pending user confirmation to kill
 	KillConfirmationPort *models.PortInfo
+	// KillSignal is the signal picked in the confirmation dialog ("" means use the matching strategy)
+	KillSignal string
+	// KillScope is the scope picked in the confirmation dialog ("" for the process, "group" or "session")
+	KillScope string
+	// KillMembers lists the processes a group or session kill would signal (loaded when the scope changes)
+	KillMembers []models.ProcessMember
+	// KillMembersErr holds the error from listing group or session members
+	KillMembersErr error
+	// ConfirmTyping is true while the dialog waits for the process name a "confirm" policy rule asks for
+	ConfirmTyping bool
+	// ConfirmInput is the text typed so far in answer to a "confirm" policy rule
+	ConfirmInput string
+	// ConfirmRestart is true if the typed confirmation was started with 'R' (kill and restart)
+	ConfirmRestart bool
+	// ConfirmSuspend is true if the typed confirmation was started with 'p' (suspend instead of kill)
+	ConfirmSuspend bool
+	// KillConfirmed is true once the typed confirmation matched the process name
+	KillConfirmed bool
+	// KillTargets are the recommended ports 'K' is about to kill, highest score first
+	KillTargets []models.PortInfo
+	// KillSkipped are the recommended ports 'K' leaves alone because the policy protects them
+	KillSkipped []models.PortInfo
+	// Escalation holds the permission-denied kill awaiting confirmation of an elevated retry
+	Escalation *Escalation
+	// Suspended maps the PIDs paused with 'p' to their port info, until resumed, killed or gone
+	Suspended map[int]models.PortInfo
+	// HistoryIndex is the selected entry in the history view
+	HistoryIndex int
+	// HistoryQuery is the filter applied to the history view
+	HistoryQuery models.HistoryQuery
+	// HistoryCursor loads the next page of history (empty once every matching entry is loaded)
+	HistoryCursor string
+	// HistoryLoading is true while a page of history is being loaded
+	HistoryLoading bool
+	// HistoryFiltering is true while a history filter is being typed into HistoryFilter
+	HistoryFiltering bool
+	// HistoryFilter is the text of the history filter (e.g. "3000-3999 node failed since:7d")
+	HistoryFilter string
+	// Stats is the kill statistics shown in the stats view (nil until loaded)
+	Stats *models.KillStats
+	// StatsError is why the statistics couldn't be loaded
+	StatsError error
+	// StatsWindow indexes statsWindows, the period the stats view covers
+	StatsWindow int
+	// TimelinePort is the port the timeline view shows
+	TimelinePort int
+	// TimelineWindow indexes timelineWindows, the period the timeline view covers
+	TimelineWindow int
+	// TimelineHoldings are the port's listeners in the period, oldest first (nil until loaded)
+	TimelineHoldings []models.PortHolding
+	// TimelineLoading is true while the timeline is being loaded
+	TimelineLoading bool
+	// TimelineError is why the timeline couldn't be loaded or recorded
+	TimelineError error
+	// Labels are the user's port labels and notes, as loaded from storage
+	Labels []models.PortLabel
+	// LabelEditing is true while the selected port's label is being edited in the main view
+	LabelEditing bool
+	// LabelDraft is the label being edited; its port and scope are where it will be saved
+	LabelDraft models.PortLabel
+	// LabelEditingNote is true while the note, rather than the label, is being typed
+	LabelEditingNote bool
 	// Width is the current terminal width in characters
 	Width int
 	// Height is the current terminal height in characters
@@ -81,6 +195,18 @@ type Model struct {
 	Killer        Killer
 	// Storage is the persistence layer for kill history (optional, nil means no persistence)
 	Storage       Storage
+	// Launcher restarts killed processes (optional, nil disables restart)
+	Launcher Launcher
+	// Policy decides which processes are protected (optional, nil falls back to the IsSystem marker)
+	Policy *policy.Policy
+	// Hooks runs the configured pre-kill and post-kill hooks (optional, nil runs none)
+	Hooks HookRunner
+	// Operator is the user running port-chaser, recorded with every kill in history
+	Operator string
+	// Retention limits how much history is kept; it is applied at startup and every Retention.Interval
+	Retention Retention
+	// Timeline records port occupancy from every scan (optional, nil records nothing)
+	Timeline Timeline
 	// PreviousPorts maps port numbers to their info from the last scan (for change detection)
 	PreviousPorts map[int]models.PortInfo
 	// NewPorts contains ports that appeared since the last scan (for highlighting)
@@ -99,8 +225,93 @@ type Scanner interface {
 // Killer defines the interface for process termination operations.
 // This allows mocking in tests and platform-specific implementations.
 type Killer interface {
-	// Kill attempts to terminate the process associated with the given port
-	Kill(port models.PortInfo) error
+	// Kill attempts to terminate the process associated with the given port.
+	// The report is non-nil on success and describes what happened afterwards.
+	Kill(port models.PortInfo, opts KillOptions) (*KillReport, error)
+	// DescribeKill returns a short description of the signal sequence Kill would use, e.g. "SIGINT 10s → SIGKILL"
+	DescribeKill(port models.PortInfo, opts KillOptions) string
+	// Members lists the processes in the port's process group or session ("group" or "session")
+	Members(port models.PortInfo, scope string) ([]models.ProcessMember, error)
+	// StopManaged stops a supervised process through its process manager's stop command
+	StopManaged(port models.PortInfo) error
+	// EscalateCommand returns the command KillEscalated would run, e.g. "sudo -n port-chaser kill ...".
+	// An empty result means escalation is disabled or unavailable.
+	EscalateCommand(port models.PortInfo, opts KillOptions) []string
+	// KillEscalated retries a kill through the escalation command (sudo or a configured helper)
+	KillEscalated(port models.PortInfo, opts KillOptions) (*KillReport, error)
+	// Suspend pauses the port's process without killing it (SIGSTOP).
+	// confirmed is true if the user typed the process name a "confirm" policy rule asks for.
+	Suspend(port models.PortInfo, confirmed bool) error
+	// Resume continues a suspended process (SIGCONT)
+	Resume(port models.PortInfo) error
+}
+
+// ErrStaleTarget is returned (wrapped) by Killer.Kill when the port's PID now belongs to a
+// different process than the one scanned, i.e. the original exited and its PID was reused.
+var ErrStaleTarget = errors.New("stale target")
+
+// Launcher restarts killed processes from the launch context captured before the kill.
+type Launcher interface {
+	// Restart relaunches the process detached and waits for it to listen on port again
+	Restart(launch models.LaunchContext, port int) (*models.RestartResult, error)
+}
+
+// HookRunner runs external commands before and after kills.
+type HookRunner interface {
+	// Run runs the hooks for event; for hooks.PreKill an error wrapping hooks.ErrVetoed cancels the kill
+	Run(ctx context.Context, event hooks.Event, port models.PortInfo, result *hooks.Result) ([]models.HookRun, error)
+}
+
+// ErrPermissionDenied is returned (wrapped) by Killer.Kill when the process belongs to another
+// user (EPERM); the kill can then be retried with KillEscalated.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Escalation is a permission-denied kill the user may retry with elevated privileges.
+type Escalation struct {
+	// Port is the target of the failed kill
+	Port models.PortInfo
+	// Options are the kill options to retry with
+	Options KillOptions
+	// Command is the exact command line the retry will run
+	Command []string
+	// Reason is the error message of the failed kill
+	Reason string
+	// Restart is true if the process should be relaunched after the kill
+	Restart bool
+}
+
+// KillOptions adjusts a single kill.
+type KillOptions struct {
+	// Signal overrides the configured strategy with a manually chosen first signal ("" means no override)
+	Signal string
+	// Scope widens the kill to the listener's process "group" or "session" ("" means just the process)
+	Scope string
+	// Confirmed is true if the user typed the process name a "confirm" policy rule asks for
+	Confirmed bool
+}
+
+// KillSignals lists the signals the confirmation dialog cycles through; "" is the configured strategy.
+var KillSignals = []string{"", "SIGTERM", "SIGINT", "SIGQUIT", "SIGHUP", "SIGKILL"}
+
+// KillScopes lists the scopes the confirmation dialog cycles through; "" is the single process.
+var KillScopes = []string{"", "group", "session"}
+
+// KillReport describes what happened after a successful kill.
+// It is also the JSON output of `port-chaser kill -json`, which escalated kills parse.
+type KillReport struct {
+	// Method is the signal that actually terminated the process (e.g. "SIGINT")
+	Method string `json:"method"`
+	// Strategy is the name of the kill strategy that was applied
+	Strategy string `json:"strategy,omitempty"`
+	// Members lists every signalled process and its outcome for group and session kills
+	Members []models.ProcessMember `json:"members,omitempty"`
+	// Release reports whether the port was actually freed (nil if not checked)
+	Release *models.PortRelease `json:"release,omitempty"`
+	// Respawn is set when a new process took the port within the respawn window
+	Respawn *models.RespawnInfo `json:"respawn,omitempty"`
+	// Launch is how the process was started, captured before the kill (nil if unavailable).
+	// It holds the environment, so it is never part of the JSON report.
+	Launch *models.LaunchContext `json:"-"`
 }
 
 // Storage defines the interface for persisting and retrieving kill history.
@@ -108,14 +319,49 @@ type Killer interface {
 type Storage interface {
 	// RecordKill saves a history entry when a process is killed
 	RecordKill(entry models.HistoryEntry) error
-	// GetHistory retrieves recent kill history, limited to the specified count
-	GetHistory(limit int) ([]models.HistoryEntry, error)
+	// QueryHistory returns one page of the history entries matching the query, newest first
+	QueryHistory(q models.HistoryQuery) (models.HistoryPage, error)
+	// Stats aggregates the kill history in the query's window
+	Stats(q models.StatsQuery) (models.KillStats, error)
 	// GetKillCount returns how many times a specific port has been killed within days
 	GetKillCount(port int, days int) (int, error)
+	// Prune deletes entries older than maxAge and beyond the newest maxRows (0 disables either limit)
+	Prune(maxAge time.Duration, maxRows int) (int, error)
+	// Labels returns every port label
+	Labels() ([]models.PortLabel, error)
+	// SaveLabel stores a label, replacing the one with the same port and scope
+	SaveLabel(label models.PortLabel) error
+	// DeleteLabel deletes the label with the same port and scope, reporting whether there was one
+	DeleteLabel(label models.PortLabel) (bool, error)
 	// Close closes the storage connection and releases resources
 	Close() error
 }
 
+// Timeline records which processes listen on which ports as scans happen.
+type Timeline interface {
+	// Observe records the listeners that appeared or disappeared since the previous scan
+	Observe(ports []models.PortInfo, at time.Time) ([]models.PortEvent, error)
+	// Holdings returns who listened on port at any time in [since, until) (zero bounds are open)
+	Holdings(port int, since, until time.Time) ([]models.PortHolding, error)
+	// Prune deletes events older than maxAge (0 keeps them all)
+	Prune(maxAge time.Duration) (int, error)
+}
+
+// Retention is the history retention policy applied by the TUI.
+type Retention struct {
+	// MaxAge removes entries older than this (0 keeps them forever)
+	MaxAge time.Duration
+	// MaxRows keeps only the newest entries (0 means unlimited)
+	MaxRows int
+	// Interval is how often pruning runs after startup (0 only prunes at startup)
+	Interval time.Duration
+}
+
+// enabled reports whether any retention limit is set.
+func (r Retention) enabled() bool {
+	return r.MaxAge > 0 || r.MaxRows > 0
+}
+
 // Init is called by Bubbletea when the application starts.
 // It returns the initial commands to run: load history, start port scanning, start tick timer, and enter alt screen.
 func (m Model) Init() tea.Cmd {
@@ -129,7 +375,10 @@ func (m Model) Init() tea.Cmd {
 
 	// Add history loading command if storage is available
 	if m.Storage != nil {
-		batch = append(batch, m.loadHistoryCmd())
+		batch = append(batch, m.loadHistoryCmd(), m.loadLabelsCmd())
+		if m.Retention.enabled() {
+			batch = append(batch, m.pruneHistoryCmd())
+		}
 	}
 
 	return tea.Batch(batch...)
@@ -154,15 +403,108 @@ func (m Model) scanPortsCmd() tea.Cmd {
 	}
 }
 
-// loadHistoryCmd returns a command that loads history from storage.
+// loadHistoryCmd returns a command that loads the first page of history matching HistoryQuery.
 // This runs asynchronously and will send a HistoryLoadedMsg when complete.
 func (m Model) loadHistoryCmd() tea.Cmd {
+	return m.queryHistoryCmd("")
+}
+
+// loadMoreHistoryCmd returns a command that loads the page after the entries already shown.
+func (m Model) loadMoreHistoryCmd() tea.Cmd {
+	return m.queryHistoryCmd(m.HistoryCursor)
+}
+
+// queryHistoryCmd returns a command that loads the page of history starting at cursor.
+func (m Model) queryHistoryCmd(cursor string) tea.Cmd {
+	query := m.HistoryQuery
 	return func() tea.Msg {
-		history, err := m.Storage.GetHistory(100)
+		q := query
+		q.Cursor = cursor
+		page, err := m.Storage.QueryHistory(q)
 		return HistoryLoadedMsg{
-			History: history,
-			Error:   err,
+			History:    page.Entries,
+			NextCursor: page.NextCursor,
+			Query:      query,
+			Append:     cursor != "",
+			Error:      err,
+		}
+	}
+}
+
+// statsWindows are the periods the stats view cycles through with w (0 means all history).
+var statsWindows = []time.Duration{7 * 24 * time.Hour, 30 * 24 * time.Hour, 90 * 24 * time.Hour, 0}
+
+// loadStatsCmd returns a command that computes kill statistics for the selected window.
+// It sends a StatsLoadedMsg when complete.
+func (m Model) loadStatsCmd() tea.Cmd {
+	window := m.StatsWindow
+	return func() tea.Msg {
+		var q models.StatsQuery
+		if age := statsWindows[window]; age > 0 {
+			q.Since = time.Now().Add(-age)
+		}
+		stats, err := m.Storage.Stats(q)
+		return StatsLoadedMsg{Window: window, Stats: stats, Error: err}
+	}
+}
+
+// pruneHistoryCmd returns a command that applies the retention limits to storage.
+// The port timeline is pruned to the same age. It sends a HistoryPrunedMsg when complete.
+func (m Model) pruneHistoryCmd() tea.Cmd {
+	return func() tea.Msg {
+		removed, err := m.Storage.Prune(m.Retention.MaxAge, m.Retention.MaxRows)
+		if m.Timeline != nil && err == nil {
+			_, err = m.Timeline.Prune(m.Retention.MaxAge)
+		}
+		return HistoryPrunedMsg{Removed: removed, Error: err}
+	}
+}
+
+// recordTimelineCmd returns a command that records the changes in a scan to the timeline.
+// It sends a TimelineRecordedMsg when complete.
+func (m Model) recordTimelineCmd(ports []models.PortInfo, at time.Time) tea.Cmd {
+	return func() tea.Msg {
+		events, err := m.Timeline.Observe(ports, at)
+		return TimelineRecordedMsg{Events: events, Error: err}
+	}
+}
+
+// loadLabelsCmd returns a command that loads the port labels. It sends a LabelsLoadedMsg when complete.
+func (m Model) loadLabelsCmd() tea.Cmd {
+	return func() tea.Msg {
+		labels, err := m.Storage.Labels()
+		return LabelsLoadedMsg{Labels: labels, Error: err}
+	}
+}
+
+// saveLabelCmd returns a command that saves label, or deletes it if both its label and note are empty.
+// It sends a LabelSavedMsg when complete.
+func (m Model) saveLabelCmd(label models.PortLabel) tea.Cmd {
+	return func() tea.Msg {
+		label = label.Normalized()
+		if label.Label == "" && label.Note == "" {
+			_, err := m.Storage.DeleteLabel(label)
+			return LabelSavedMsg{Label: label, Deleted: true, Error: err}
+		}
+		label.UpdatedAt = time.Now()
+		return LabelSavedMsg{Label: label, Error: m.Storage.SaveLabel(label)}
+	}
+}
+
+// timelineWindows are the periods the timeline view cycles through with w (0 means everything recorded).
+var timelineWindows = []time.Duration{24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour, 0}
+
+// loadTimelineCmd returns a command that loads who listened on TimelinePort in the selected window.
+// It sends a TimelineLoadedMsg when complete.
+func (m Model) loadTimelineCmd() tea.Cmd {
+	port, window := m.TimelinePort, m.TimelineWindow
+	return func() tea.Msg {
+		var since time.Time
+		if age := timelineWindows[window]; age > 0 {
+			since = time.Now().Add(-age)
 		}
+		holdings, err := m.Timeline.Holdings(port, since, time.Time{})
+		return TimelineLoadedMsg{Port: port, Window: window, Holdings: holdings, Error: err}
 	}
 }
 
@@ -189,6 +531,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Handle process kill completion (success or failure)
 		return m.handlePortKilled(msg)
 
+	case RecommendedKilledMsg:
+		// Handle the end of a kill of every recommended process
+		return m.handleRecommendedKilled(msg)
+
+	case SuspendedMsg:
+		// Handle suspend/resume completion
+		return m.handleSuspended(msg)
+
+	case RestartedMsg:
+		// Report the relaunch and rescan to show the new listener
+		m.StatusMessage = restartMessage(msg)
+		m.StatusMessageTimeout = time.Now().Add(time.Second * 5)
+		m.Loading = true
+		return m, m.scanPortsCmd()
+
 	case StatusMsg:
 		// Display a temporary status message
 		m.StatusMessage = msg.Message
@@ -205,14 +562,114 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.RemovedPorts = make(map[int]bool)
 		return m, nil
 
+	case MembersLoadedMsg:
+		// Ignore results for a scope the user has already cycled past
+		if msg.Scope == m.KillScope {
+			m.KillMembers = msg.Members
+			m.KillMembersErr = msg.Error
+		}
+		return m, nil
+
 	case HistoryLoadedMsg:
-		// Handle loaded history from storage
-		if msg.Error == nil {
+		// Ignore pages loaded for a filter that has since changed
+		if msg.Query != m.HistoryQuery {
+			return m, nil
+		}
+		m.HistoryLoading = false
+		// If loading fails, continue with the history already shown (don't crash)
+		if msg.Error != nil {
+			if msg.Append {
+				m.StatusMessage = fmt.Sprintf("Failed to load more history: %v", msg.Error)
+				m.StatusMessageTimeout = time.Now().Add(time.Second * 3)
+			}
+			return m, nil
+		}
+		if msg.Append {
+			m.History = append(append([]models.HistoryEntry{}, m.History...), msg.History...)
+		} else {
 			m.History = msg.History
+			m.HistoryIndex = 0
+		}
+		m.HistoryCursor = msg.NextCursor
+		return m, nil
+
+	case StatsLoadedMsg:
+		// Ignore statistics for a window the user has already cycled past
+		if msg.Window == m.StatsWindow {
+			stats := msg.Stats
+			m.Stats = &stats
+			m.StatsError = msg.Error
+		}
+		return m, nil
+
+	case LabelsLoadedMsg:
+		if msg.Error != nil {
+			m.StatusMessage = fmt.Sprintf("Failed to load labels: %v", msg.Error)
+			m.StatusMessageTimeout = time.Now().Add(time.Second * 5)
+			return m, nil
+		}
+		m.Labels = msg.Labels
+		return m, nil
+
+	case LabelSavedMsg:
+		switch {
+		case msg.Error != nil:
+			m.StatusMessage = fmt.Sprintf("Failed to save label: %v", msg.Error)
+		case msg.Deleted:
+			m.StatusMessage = fmt.Sprintf("Removed the label from port %d", msg.Label.Port)
+		default:
+			m.StatusMessage = fmt.Sprintf("Labelled port %d %q", msg.Label.Port, msg.Label.Label)
+		}
+		m.StatusMessageTimeout = time.Now().Add(time.Second * 3)
+		if msg.Error != nil {
+			return m, nil
+		}
+		return m, m.loadLabelsCmd()
+
+	case TimelineRecordedMsg:
+		// A failure is shown once; recording retries with the next scan
+		if msg.Error != nil {
+			if m.TimelineError == nil {
+				m.StatusMessage = fmt.Sprintf("Failed to record port timeline: %v", msg.Error)
+				m.StatusMessageTimeout = time.Now().Add(time.Second * 5)
+			}
+			m.TimelineError = msg.Error
+			return m, nil
+		}
+		m.TimelineError = nil
+		// Keep an open timeline up to date
+		if m.ViewMode == ViewModeTimeline {
+			for _, event := range msg.Events {
+				if event.Port == m.TimelinePort {
+					return m, m.loadTimelineCmd()
+				}
+			}
+		}
+		return m, nil
+
+	case TimelineLoadedMsg:
+		// Ignore timelines for a port or window the user has already left
+		if msg.Port == m.TimelinePort && msg.Window == m.TimelineWindow {
+			m.TimelineLoading = false
+			m.TimelineHoldings = msg.Holdings
+			m.TimelineError = msg.Error
 		}
-		// If loading fails, continue with empty history (don't crash)
 		return m, nil
 
+	case HistoryPrunedMsg:
+		var cmds []tea.Cmd
+		if msg.Error == nil && msg.Removed > 0 {
+			cmds = append(cmds, m.loadHistoryCmd())
+		}
+		// Pruning failures are not fatal; the next run tries again
+		if m.Retention.Interval > 0 {
+			prune := m.pruneHistoryCmd()
+			cmds = append(cmds, tea.Tick(m.Retention.Interval, func(time.Time) tea.Msg {
+				return prune()
+			}))
+		}
+		return m, tea.Batch(cmds...)
+
 	default:
 		return m, nil
 	}
@@ -231,6 +688,16 @@ func (m Model) View() string {
 		return m.renderHistoryView()
 	case ViewModeHelp:
 		return m.renderHelpView()
+	case ViewModeConfirmEscalate:
+		return m.renderConfirmEscalateView()
+	case ViewModeConfirmQuit:
+		return m.renderConfirmQuitView()
+	case ViewModeStats:
+		return m.renderStatsView()
+	case ViewModeTimeline:
+		return m.renderTimelineView()
+	case ViewModeConfirmKillRecommended:
+		return m.renderConfirmKillRecommendedView()
 	default:
 		return "Unknown view mode"
 	}
@@ -250,6 +717,48 @@ type PortKilledMsg struct {
 	Port    models.PortInfo
 	Success bool
 	Message string
+	// ViaManager is true when the process was stopped through its process manager
+	ViaManager bool
+	// Stale is true when the kill was refused because the PID was reused since the scan
+	Stale bool
+	// PermissionDenied is true when the kill failed because the process belongs to another user
+	PermissionDenied bool
+	// Escalated is true when the kill ran through the escalation command
+	Escalated bool
+	// Options are the kill options used, kept so a permission-denied kill can be retried
+	Options KillOptions
+	// Restart is true when the process should be relaunched once the kill succeeds
+	Restart bool
+	// Report holds post-kill details such as a detected respawn (nil on failure)
+	Report *KillReport
+	// Vetoed is true when a pre-kill hook cancelled the kill
+	Vetoed bool
+	// Duration is how long the kill (or manager stop) took
+	Duration time.Duration
+	// Hooks lists the pre-kill and post-kill hooks that ran for this kill
+	Hooks []models.HookRun
+}
+
+// RecommendedKilledMsg is sent when a kill of every recommended process completes.
+type RecommendedKilledMsg struct {
+	// Results holds one kill result per process, in the order they were killed
+	Results []PortKilledMsg
+}
+
+// RestartedMsg is sent when relaunching a killed process completes.
+type RestartedMsg struct {
+	Port        int
+	ProcessName string
+	Result      *models.RestartResult
+	Error       error
+}
+
+// SuspendedMsg is sent when a suspend or resume completes.
+type SuspendedMsg struct {
+	Port models.PortInfo
+	// Suspended is true for a suspend, false for a resume
+	Suspended bool
+	Error     error
 }
 
 // StatusMsg is a temporary notification message to display to the user.
@@ -270,6 +779,63 @@ type TickMsg struct {
 // It contains the loaded history entries and any error that occurred.
 type HistoryLoadedMsg struct {
 	History []models.HistoryEntry
+	// NextCursor loads the following page (empty on the last page)
+	NextCursor string
+	// Query is the filter the page was loaded for
+	Query models.HistoryQuery
+	// Append is true for a following page, false for the first page of a query
+	Append bool
+	Error  error
+}
+
+// StatsLoadedMsg is sent when kill statistics have been computed.
+type StatsLoadedMsg struct {
+	// Window is the statsWindows index the statistics were computed for
+	Window int
+	Stats  models.KillStats
+	Error  error
+}
+
+// HistoryPrunedMsg is sent when the retention limits have been applied to storage.
+type HistoryPrunedMsg struct {
+	// Removed is how many entries were deleted
+	Removed int
+	Error   error
+}
+
+// LabelsLoadedMsg is sent when the port labels have been loaded from storage.
+type LabelsLoadedMsg struct {
+	Labels []models.PortLabel
+	Error  error
+}
+
+// LabelSavedMsg is sent when a label edited in the TUI has been saved (or deleted, if it was emptied).
+type LabelSavedMsg struct {
+	Label   models.PortLabel
+	Deleted bool
+	Error   error
+}
+
+// TimelineRecordedMsg is sent when a scan's changes have been recorded to the port timeline.
+type TimelineRecordedMsg struct {
+	// Events are the recorded appearances and disappearances
+	Events []models.PortEvent
+	Error  error
+}
+
+// TimelineLoadedMsg is sent when a port's timeline has been loaded.
+type TimelineLoadedMsg struct {
+	// Port and Window are the port and timelineWindows index the timeline was loaded for
+	Port     int
+	Window   int
+	Holdings []models.PortHolding
+	Error    error
+}
+
+// MembersLoadedMsg is sent when the process group or session members for the kill dialog are listed.
+type MembersLoadedMsg struct {
+	Scope   string
+	Members []models.ProcessMember
 	Error   error
 }
 
@@ -285,6 +851,16 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleHistoryKeyMsg(msg)
 	case ViewModeHelp:
 		return m.handleHelpKeyMsg(msg)
+	case ViewModeConfirmEscalate:
+		return m.handleEscalateKeyMsg(msg)
+	case ViewModeConfirmQuit:
+		return m.handleQuitKeyMsg(msg)
+	case ViewModeStats:
+		return m.handleStatsKeyMsg(msg)
+	case ViewModeTimeline:
+		return m.handleTimelineKeyMsg(msg)
+	case ViewModeConfirmKillRecommended:
+		return m.handleKillRecommendedKeyMsg(msg)
 	default:
 		return m, nil
 	}
@@ -305,6 +881,9 @@ func (m Model) handlePortsScanned(msg PortsScannedMsg) (tea.Model, tea.Cmd) {
 		currentPorts[port.PortNumber] = port
 	}
 
+	// Forget suspended processes that no longer hold any port (killed elsewhere or exited)
+	m.Suspended = pruneSuspended(m.Suspended, msg.Ports)
+
 	// Detect new ports (present now but not in previous scan)
 	newPorts := make(map[int]bool)
 	removedPorts := make(map[int]bool)
@@ -324,7 +903,7 @@ func (m Model) handlePortsScanned(msg PortsScannedMsg) (tea.Model, tea.Cmd) {
 
 	// Update model state with scan results
 	m.Ports = msg.Ports
-	m.FilteredPorts = msg.Ports
+	m.FilteredPorts = m.sortPorts(msg.Ports)
 	m.LastScanTime = msg.ScannedAt
 	m.PreviousPorts = currentPorts
 	m.NewPorts = newPorts
@@ -332,46 +911,74 @@ func (m Model) handlePortsScanned(msg PortsScannedMsg) (tea.Model, tea.Cmd) {
 	m.Loading = false
 
 	// Schedule clearing of highlights after 3 seconds
-	return m, tea.Tick(time.Second*3, func(t time.Time) tea.Msg {
+	clear := tea.Tick(time.Second*3, func(t time.Time) tea.Msg {
 		return ClearHighlightsMsg{}
 	})
+	if m.Timeline == nil {
+		return m, clear
+	}
+	return m, tea.Batch(clear, m.recordTimelineCmd(msg.Ports, msg.ScannedAt))
 }
 
 // handlePortKilled handles the result of a process kill operation.
 // It shows a status message, records history if storage is available, and triggers a port rescan.
 func (m Model) handlePortKilled(msg PortKilledMsg) (tea.Model, tea.Cmd) {
+	respawn := msg.respawn()
+	held := msg.portHeld()
+
+	// Every attempt is recorded, failures included, so history doubles as an audit log
+	m.recordKill(msg)
+
+	// A permission-denied kill can be retried with elevated privileges once the user confirms
+	if msg.PermissionDenied && !msg.Escalated && m.Killer != nil {
+		if command := m.Killer.EscalateCommand(msg.Port, msg.Options); len(command) > 0 {
+			m.ViewMode = ViewModeConfirmEscalate
+			m.Escalation = &Escalation{Port: msg.Port, Options: msg.Options, Command: command, Reason: msg.Message, Restart: msg.Restart}
+			return m, nil
+		}
+	}
+
 	m.ViewMode = ViewModeMain
 	m.Loading = true
 
-	// Record to history if kill succeeded and storage is available
-	if msg.Success && m.Storage != nil {
-		entry := models.HistoryEntry{
-			PortNumber:  msg.Port.PortNumber,
-			ProcessName:  msg.Port.ProcessName,
-			PID:         msg.Port.PID,
-			Command:     msg.Port.Command,
-			KilledAt:    time.Now(),
-		}
+	// Keep hook output on screen a little longer than the status message
+	m.HookRuns = msg.Hooks
+	m.HookRunsTimeout = time.Now().Add(time.Second * 10)
 
-		// Record the kill (ignore errors to not disrupt UI)
-		if err := m.Storage.RecordKill(entry); err != nil {
-			// Log error but don't disrupt the flow
-			// In production, you might want to show a warning
-		} else {
-			// Add to in-memory history for immediate display
-			m.History = append([]models.HistoryEntry{entry}, m.History...)
-			// Limit history to 100 entries in memory
-			if len(m.History) > 100 {
-				m.History = m.History[:100]
-			}
-		}
+	// A killed process is no longer suspended
+	if msg.Success {
+		m.Suspended = withoutSuspended(m.Suspended, msg.Port.PID)
 	}
 
 	// Generate appropriate status message based on kill result
 	var statusCmd tea.Cmd
-	if msg.Success {
+	if msg.Success && msg.ViaManager {
+		statusCmd = func() tea.Msg {
+			return StatusMsg{Message: fmt.Sprintf("Stopped %s via %s", managedLabel(msg.Port), msg.Port.Manager)}
+		}
+	} else if msg.Success && respawn != nil {
+		statusCmd = func() tea.Msg {
+			return StatusMsg{Message: respawnMessage(msg.Port, respawn)}
+		}
+	} else if msg.Success && held != nil {
+		statusCmd = func() tea.Msg {
+			return StatusMsg{Message: portHeldMessage(msg.Port, held)}
+		}
+	} else if msg.Success && msg.Escalated {
+		statusCmd = func() tea.Msg {
+			return StatusMsg{Message: killedMessage(msg.Port, msg.Report) + " with elevated privileges"}
+		}
+	} else if msg.Success {
+		statusCmd = func() tea.Msg {
+			return StatusMsg{Message: killedMessage(msg.Port, msg.Report)}
+		}
+	} else if msg.Stale {
+		statusCmd = func() tea.Msg {
+			return StatusMsg{Message: staleMessage(msg.Port)}
+		}
+	} else if msg.Vetoed {
 		statusCmd = func() tea.Msg {
-			return StatusMsg{Message: "Killed " + msg.Port.ProcessName}
+			return StatusMsg{Message: "Kill cancelled: " + msg.Message}
 		}
 	} else {
 		statusCmd = func() tea.Msg {
@@ -379,9 +986,217 @@ func (m Model) handlePortKilled(msg PortKilledMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Relaunch after a kill-and-restart; the status message is replaced once the restart reports back
+	if msg.Success && msg.Restart {
+		if launch := msg.launch(); launch != nil && m.Launcher != nil {
+			return m, tea.Batch(statusCmd, m.restartCmd(*launch, msg.Port.PortNumber, msg.Port.ProcessName))
+		}
+		statusCmd = func() tea.Msg {
+			return StatusMsg{Message: fmt.Sprintf("Killed %s, but it can't be restarted: its command line could not be read", msg.Port.ProcessName)}
+		}
+	}
+
 	return m, tea.Batch(statusCmd, m.scanPortsCmd())
 }
 
+// recordKill stores the audit record of a kill attempt; without storage nothing is recorded.
+func (m *Model) recordKill(msg PortKilledMsg) {
+	if m.Storage == nil {
+		return
+	}
+	entry := m.historyEntry(msg)
+	// Add to in-memory history for immediate display, unless the history filter hides it.
+	// It isn't trimmed: older entries are paged in from storage and the cursor follows the last one.
+	if err := m.Storage.RecordKill(entry); err == nil && m.HistoryQuery.Matches(entry) {
+		m.History = append([]models.HistoryEntry{entry}, m.History...)
+	}
+}
+
+// handleRecommendedKilled records every kill of a kill of all recommended processes,
+// reports how many succeeded and rescans. Permission-denied kills aren't offered an elevated retry.
+func (m Model) handleRecommendedKilled(msg RecommendedKilledMsg) (tea.Model, tea.Cmd) {
+	var runs []models.HookRun
+	for _, result := range msg.Results {
+		m.recordKill(result)
+		if result.Success {
+			m.Suspended = withoutSuspended(m.Suspended, result.Port.PID)
+		}
+		runs = append(runs, result.Hooks...)
+	}
+
+	m.ViewMode = ViewModeMain
+	m.KillTargets = nil
+	m.KillSkipped = nil
+	m.Loading = true
+	m.HookRuns = runs
+	m.HookRunsTimeout = time.Now().Add(time.Second * 10)
+	m.StatusMessage = recommendedKilledMessage(msg.Results)
+	m.StatusMessageTimeout = time.Now().Add(time.Second * 5)
+	return m, m.scanPortsCmd()
+}
+
+// recommendedKilledMessage summarizes a kill of every recommended process,
+// e.g. "Killed 2 of 3 recommended processes; node on 3000 failed: permission denied".
+func recommendedKilledMessage(results []PortKilledMsg) string {
+	killed := 0
+	var failures []string
+	for _, result := range results {
+		if result.Success {
+			killed++
+			continue
+		}
+		failures = append(failures, fmt.Sprintf("%s on %d failed: %s", result.Port.ProcessName, result.Port.PortNumber, result.Message))
+	}
+	message := fmt.Sprintf("Killed %d of %d recommended processes", killed, len(results))
+	if len(failures) > 0 {
+		message += "; " + strings.Join(failures, "; ")
+	}
+	return message
+}
+
+// historyEntry builds the audit record of a kill attempt.
+func (m Model) historyEntry(msg PortKilledMsg) models.HistoryEntry {
+	entry := models.HistoryEntry{
+		PortNumber:    msg.Port.PortNumber,
+		ProcessName:   msg.Port.ProcessName,
+		PID:           msg.Port.PID,
+		Command:       msg.Port.Command,
+		Manager:       msg.Port.Manager,
+		Outcome:       models.OutcomeKilled,
+		Escalated:     msg.Escalated,
+		Launch:        msg.launch(),
+		Duration:      msg.Duration,
+		KilledBy:      m.Operator,
+		Owner:         msg.Port.User,
+		Protocol:      msg.Port.Protocol,
+		Address:       msg.Port.Address,
+		ContainerID:   msg.Port.ContainerID,
+		ContainerName: msg.Port.ContainerName,
+		ImageName:     msg.Port.ImageName,
+		KilledAt:      time.Now(),
+	}
+	if msg.Report != nil {
+		entry.Method = msg.Report.Method
+	}
+
+	switch {
+	case !msg.Success:
+		entry.Outcome = models.OutcomeFailed
+		entry.Failed = true
+		entry.Error = msg.Message
+	case msg.ViaManager:
+		entry.Outcome = models.OutcomeStopped
+		entry.Method = "stop"
+	}
+	if msg.Success {
+		if held := msg.portHeld(); held != nil {
+			entry.Outcome = models.OutcomePortHeld
+		}
+		if respawn := msg.respawn(); respawn != nil {
+			entry.Outcome = models.OutcomeRespawned
+			entry.RespawnPID = respawn.PID
+			entry.Supervisor = respawn.Supervisor
+		}
+	}
+	return entry
+}
+
+// launch returns the launch context captured before the kill, if any.
+func (msg PortKilledMsg) launch() *models.LaunchContext {
+	if msg.Report == nil {
+		return nil
+	}
+	return msg.Report.Launch
+}
+
+// restartCmd returns a command that relaunches a killed process and waits for it to re-bind its port.
+// The command runs asynchronously and sends a RestartedMsg when complete.
+func (m Model) restartCmd(launch models.LaunchContext, port int, name string) tea.Cmd {
+	launcher := m.Launcher
+
+	return func() tea.Msg {
+		result, err := launcher.Restart(launch, port)
+		return RestartedMsg{Port: port, ProcessName: name, Result: result, Error: err}
+	}
+}
+
+// restartMessage reports the outcome of a restart, including where its output went.
+func restartMessage(msg RestartedMsg) string {
+	if msg.Error != nil {
+		return "Restart failed: " + msg.Error.Error()
+	}
+	if !msg.Result.Bound {
+		return fmt.Sprintf("Restarted %s as PID %d, but port %d is not listening yet (output: %s)",
+			msg.ProcessName, msg.Result.PID, msg.Port, msg.Result.LogPath)
+	}
+	return fmt.Sprintf("Restarted %s as PID %d, listening on port %d", msg.ProcessName, msg.Result.PID, msg.Port)
+}
+
+// respawn returns the respawned process reported for this kill, if any.
+func (msg PortKilledMsg) respawn() *models.RespawnInfo {
+	if msg.Report == nil {
+		return nil
+	}
+	return msg.Report.Respawn
+}
+
+// staleMessage explains why a kill was refused: the process exited after the scan and
+// its PID now belongs to something else. The port list is rescanned right after.
+func staleMessage(port models.PortInfo) string {
+	return fmt.Sprintf("Not killed: %s (PID %d) exited after the last scan and the PID now belongs to another process - list refreshed",
+		port.ProcessName, port.PID)
+}
+
+// portHeld returns the release check result if the port was still in use after the kill.
+func (msg PortKilledMsg) portHeld() *models.PortRelease {
+	if msg.Report == nil || msg.Report.Release == nil || msg.Report.Release.Released {
+		return nil
+	}
+	return msg.Report.Release
+}
+
+// portHeldMessage explains that the process died but the port is still in use.
+func portHeldMessage(port models.PortInfo, release *models.PortRelease) string {
+	msg := fmt.Sprintf("Killed %s, but port %d is still in use", port.ProcessName, port.PortNumber)
+	if release.HolderPID != 0 {
+		msg += fmt.Sprintf(" by PID %d", release.HolderPID)
+		if release.HolderName != "" {
+			msg += " (" + release.HolderName + ")"
+		}
+	}
+	return msg
+}
+
+// killedMessage reports a successful kill, naming the signal that did it when known.
+func killedMessage(port models.PortInfo, report *KillReport) string {
+	msg := "Killed " + port.ProcessName
+	if report == nil {
+		return msg
+	}
+	if report.Method != "" {
+		msg += " (" + report.Method + ")"
+	}
+	switch others := len(report.Members) - 1; {
+	case others == 1:
+		msg += " and 1 other process"
+	case others > 1:
+		msg += fmt.Sprintf(" and %d other processes", others)
+	}
+	return msg
+}
+
+// respawnMessage explains that a killed port was immediately taken over again.
+func respawnMessage(port models.PortInfo, respawn *models.RespawnInfo) string {
+	msg := fmt.Sprintf("Killed %s, but port %d respawned as PID %d", port.ProcessName, port.PortNumber, respawn.PID)
+	if respawn.ProcessName != "" {
+		msg += " (" + respawn.ProcessName + ")"
+	}
+	if respawn.Supervisor != "" {
+		msg += " - likely restarted by " + respawn.Supervisor
+	}
+	return msg
+}
+
 // handleTick processes periodic events like clearing expired status messages and auto-refreshing.
 // The tick fires every 3 seconds to handle background tasks.
 func (m Model) handleTick(msg TickMsg) (tea.Model, tea.Cmd) {
@@ -390,6 +1205,10 @@ func (m Model) handleTick(msg TickMsg) (tea.Model, tea.Cmd) {
 		m.StatusMessage = ""
 		m.StatusMessageTimeout = time.Time{}
 	}
+	if !m.HookRunsTimeout.IsZero() && time.Now().After(m.HookRunsTimeout) {
+		m.HookRuns = nil
+		m.HookRunsTimeout = time.Time{}
+	}
 
 	// Auto-refresh ports every 3 seconds
 	if time.Since(m.LastScanTime) > 3*time.Second {
@@ -409,6 +1228,9 @@ func (m Model) renderMainView() string {
 	if m.StatusMessage != "" {
 		sb.WriteString(m.StatusMessage + "\n\n")
 	}
+	if len(m.HookRuns) > 0 {
+		sb.WriteString(renderHookRuns(m.HookRuns) + "\n")
+	}
 
 	if m.Loading {
 		sb.WriteString("Scanning ports...\n")
@@ -418,7 +1240,11 @@ func (m Model) renderMainView() string {
 	if len(m.FilteredPorts) == 0 {
 		sb.WriteString("No active ports found.\n")
 	} else {
-		sb.WriteString("Active Ports:\n\n")
+		if m.SortMode == SortByScan {
+			sb.WriteString("Active Ports:\n\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("Active Ports (by %s):\n\n", m.SortMode))
+		}
 
 		// Render each port with selection cursor and highlights
 		for i, port := range m.FilteredPorts {
@@ -435,30 +1261,92 @@ func (m Model) renderMainView() string {
 				highlight = "\033[31m[GONE]\033[0m "
 			}
 
-			sb.WriteString(fmt.Sprintf("%s%s%d - %s (PID: %d)\n",
-				prefix, highlight, port.PortNumber, port.ProcessName, port.PID))
+			// Mark processes paused with 'p'
+			if _, ok := m.Suspended[port.PID]; ok {
+				highlight += "\033[33m[PAUSED]\033[0m "
+			}
+			// Mark ports that keep getting killed
+			if port.IsRecommended() {
+				highlight += "\033[33m[!]\033[0m "
+			}
+
+			label, labelled := models.LabelFor(m.Labels, port)
+			name := fmt.Sprint(port.PortNumber)
+			if labelled && label.Label != "" {
+				name += " (" + label.Label + ")"
+			}
+			sb.WriteString(fmt.Sprintf("%s%s%s - %s (PID: %d)\n",
+				prefix, highlight, name, port.ProcessName, port.PID))
+
+			// Edit the label under its port, or show the note
+			if m.LabelEditing && i == m.SelectedIndex {
+				sb.WriteString(m.renderLabelEditor())
+			} else if labelled && label.Note != "" {
+				sb.WriteString(fmt.Sprintf("    Note: %s\n", label.Note))
+			}
 
 			// Show additional info for Docker containers
 			if port.IsDocker {
 				sb.WriteString(fmt.Sprintf("    Docker: %s (%s)\n",
 					port.ContainerName, port.ImageName))
 			}
-			// Warn about system processes
-			if port.IsSystem {
-				sb.WriteString("    [System Process]\n")
+			// Show the process manager that would respawn this process
+			if port.IsManaged() {
+				sb.WriteString(fmt.Sprintf("    Managed by %s (%s)\n",
+					port.Manager, managedLabel(port)))
 			}
-			// Show kill count for frequently killed ports
+			// Mark processes the protection policy blocks, guards or warns about
+			if marker := m.policyMarker(port); marker != "" {
+				sb.WriteString(fmt.Sprintf("    [%s]\n", marker))
+			}
+			// Show how often and how recently the port was killed
 			if port.KillCount > 0 {
-				sb.WriteString(fmt.Sprintf("    Kill Count: %d\n", port.KillCount))
+				sb.WriteString(fmt.Sprintf("    Kill Count: %d (last killed %s)\n", port.KillCount, sinceLastKill(port.LastKilled)))
+			}
+			// Say why the port is recommended
+			if port.IsRecommended() && port.Recommendation != nil {
+				sb.WriteString(fmt.Sprintf("    Recommended (score %.0f): %s\n", port.Recommendation.Score, port.Recommendation.Explain()))
 			}
 		}
 	}
 
-	sb.WriteString("\nKeys: ↑/k=up, ↓/j=down, Enter=kill, d=Docker only, q=quit\n")
+	if m.LabelEditing {
+		sb.WriteString("\nKeys: Tab=label/note, Ctrl+P=scope, Enter=save (empty removes), Esc=cancel\n")
+	} else {
+		sb.WriteString("\nKeys: ↑/k=up, ↓/j=down, Enter=kill, K=kill recommended, o=sort, l=label, p=pause/resume, d=Docker only, q=quit\n")
+	}
 
 	return sb.String()
 }
 
+// sinceLastKill describes how long ago a port was last killed, e.g. "3h ago" or "5d ago".
+func sinceLastKill(at time.Time) string {
+	switch age := time.Since(at); {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+}
+
+// renderLabelEditor renders the label being edited, with a cursor in the field being typed.
+func (m Model) renderLabelEditor() string {
+	labelCursor, noteCursor := "_", ""
+	if m.LabelEditingNote {
+		labelCursor, noteCursor = "", "_"
+	}
+	scope := m.LabelDraft.Scope()
+	if scope == "" {
+		scope = "this port, whatever listens on it"
+	}
+	return fmt.Sprintf("    Label: %s%s\n    Note: %s%s\n    Applies to: %s\n",
+		m.LabelDraft.Label, labelCursor, m.LabelDraft.Note, noteCursor, scope)
+}
+
 // renderConfirmKillView renders the confirmation dialog before killing a process.
 // It displays detailed information about the selected process and asks for confirmation.
 func (m Model) renderConfirmKillView() string {
@@ -469,48 +1357,461 @@ func (m Model) renderConfirmKillView() string {
 
 	port := m.FilteredPorts[m.SelectedIndex]
 
+	// 'p' on a port under a "confirm" rule borrows this dialog for its typed confirmation
+	action := "kill"
 	var sb strings.Builder
-	sb.WriteString("⚠️  Confirm Kill Process\n\n")
-	fmt.Fprintf(&sb, "Are you sure you want to kill this process?\n\n")
+	if m.ConfirmSuspend {
+		action = "suspend"
+		sb.WriteString("⏸  Confirm Suspend Process\n\n")
+	} else {
+		sb.WriteString("⚠️  Confirm Kill Process\n\n")
+	}
+	fmt.Fprintf(&sb, "Are you sure you want to %s this process?\n\n", action)
 	sb.WriteString(fmt.Sprintf("  Port: %d\n", port.PortNumber))
+	if label, ok := models.LabelFor(m.Labels, port); ok {
+		if label.Label != "" {
+			sb.WriteString(fmt.Sprintf("  Label: %s\n", label.Label))
+		}
+		if label.Note != "" {
+			sb.WriteString(fmt.Sprintf("  Note: %s\n", label.Note))
+		}
+	}
 	sb.WriteString(fmt.Sprintf("  Process: %s\n", port.ProcessName))
 	sb.WriteString(fmt.Sprintf("  PID: %d\n", port.PID))
 	sb.WriteString(fmt.Sprintf("  Command: %s\n", port.Command))
+	if !port.StartTime.IsZero() {
+		sb.WriteString(fmt.Sprintf("  Started: %s\n", port.StartTime.Format("2006-01-02 15:04:05")))
+	}
+	if !port.LastKilled.IsZero() {
+		sb.WriteString(fmt.Sprintf("  Killed: %d times in the last %d days, last %s\n",
+			port.KillCount, models.DefaultKillCountDays, sinceLastKill(port.LastKilled)))
+	}
+	if port.IsRecommended() && port.Recommendation != nil {
+		sb.WriteString(fmt.Sprintf("  Recommended: score %.0f (%s)\n", port.Recommendation.Score, port.Recommendation.Explain()))
+	}
+
+	decision := m.decide(port)
+	switch decision.Action {
+	case policy.ActionBlock:
+		sb.WriteString(fmt.Sprintf("\n  [Protected by policy rule %q - %s refused]\n", decision.Rule, action))
+	case policy.ActionConfirm:
+		sb.WriteString(fmt.Sprintf("\n  [Policy rule %q - type the process name to confirm]\n", decision.Rule))
+	case policy.ActionWarn:
+		sb.WriteString(fmt.Sprintf("\n  [Warning: policy rule %q - be careful]\n", decision.Rule))
+	default:
+		if m.Policy == nil && port.IsSystem {
+			sb.WriteString("\n  [System Process - Be Careful]\n")
+		}
+	}
 
-	if port.IsSystem {
-		sb.WriteString("\n  [System Process - Be Careful]\n")
+	if m.ConfirmSuspend {
+		sb.WriteString(fmt.Sprintf("\nType %q to confirm: %s_\n", port.ProcessName, m.ConfirmInput))
+		sb.WriteString("\nPress Enter to suspend, Esc to cancel")
+		return sb.String()
+	}
+
+	opts := m.killOptions()
+	signal := "default"
+	if opts.Signal != "" {
+		signal = opts.Signal
+	}
+	sb.WriteString(fmt.Sprintf("\n  Signal: %s", signal))
+	if m.Killer != nil {
+		sb.WriteString(fmt.Sprintf(" (%s)", m.Killer.DescribeKill(port, opts)))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(m.renderKillScope())
+
+	if port.IsManaged() {
+		sb.WriteString(fmt.Sprintf("\n  Managed by %s (%s) - a plain kill will likely be respawned\n",
+			port.Manager, managedLabel(port)))
+		if len(port.StopCommand) > 0 {
+			sb.WriteString(fmt.Sprintf("  Press 'm' to run: %s\n", strings.Join(port.StopCommand, " ")))
+		}
+	}
+
+	if m.ConfirmTyping {
+		sb.WriteString(fmt.Sprintf("\nType %q to confirm: %s_\n", port.ProcessName, m.ConfirmInput))
+		sb.WriteString("\nPress Enter to kill, Esc to stop typing")
+		return sb.String()
 	}
 
-	sb.WriteString("\nPress 'y' to kill, 'n' or Esc to cancel")
+	sb.WriteString("\nPress 'y' to kill, 'R' to kill and restart, 's' to change signal, 'g' to change scope, 'n' or Esc to cancel")
 
 	return sb.String()
 }
 
-// renderHistoryView displays the history of previously killed processes.
-// It shows a list of all processes that have been terminated, with timestamps.
-func (m Model) renderHistoryView() string {
+// renderConfirmKillRecommendedView lists the recommended processes 'K' is about to kill, and the
+// ones it skips because the policy protects them or asks for their name to be typed.
+func (m Model) renderConfirmKillRecommendedView() string {
 	var sb strings.Builder
-
-	sb.WriteString("Kill History\n\n")
-
-	if len(m.History) == 0 {
-		sb.WriteString("No history available.\n")
+	sb.WriteString("⚠️  Kill Recommended Processes\n\n")
+	sb.WriteString("These processes will be killed with their default strategy:\n\n")
+	for _, port := range m.KillTargets {
+		sb.WriteString(fmt.Sprintf("  %d%s - %s (PID: %d)\n",
+			port.PortNumber, labelSuffix(m.Labels, port), port.ProcessName, port.PID))
+		if port.Recommendation != nil {
+			sb.WriteString(fmt.Sprintf("    score %.0f: %s\n", port.Recommendation.Score, port.Recommendation.Explain()))
+		}
+	}
+	if len(m.KillSkipped) > 0 {
+		sb.WriteString("\nSkipped (kill them one at a time):\n\n")
+		for _, port := range m.KillSkipped {
+			sb.WriteString(fmt.Sprintf("  %d - %s (PID: %d) [%s]\n",
+				port.PortNumber, port.ProcessName, port.PID, m.policyMarker(port)))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("\nPress 'y' to kill %d processes, 'n' or Esc to cancel", len(m.KillTargets)))
+	return sb.String()
+}
+
+// labelSuffix returns " (LABEL)" for a port that has a label, or "".
+func labelSuffix(labels []models.PortLabel, port models.PortInfo) string {
+	if l, ok := models.LabelFor(labels, port); ok && l.Label != "" {
+		return " (" + l.Label + ")"
+	}
+	return ""
+}
+
+// decide evaluates the protection policy for port; without a policy everything is allowed.
+func (m Model) decide(port models.PortInfo) policy.Decision {
+	if m.Policy == nil {
+		return policy.Decision{Action: policy.ActionAllow}
+	}
+	return m.Policy.Evaluate(&port)
+}
+
+// policyMarker returns the main view marker for a protected port, or "" if the policy allows it.
+func (m Model) policyMarker(port models.PortInfo) string {
+	decision := m.decide(port)
+	switch decision.Action {
+	case policy.ActionBlock:
+		return "Protected: " + decision.Rule
+	case policy.ActionConfirm:
+		return "Confirm: " + decision.Rule
+	case policy.ActionWarn:
+		return "Warning: " + decision.Rule
+	}
+	if m.Policy == nil && port.IsSystem {
+		return "System Process"
+	}
+	return ""
+}
+
+// renderConfirmQuitView warns that quitting would leave processes suspended.
+// Stopped processes keep their ports, so forgetting them looks like a hung service later.
+func (m Model) renderConfirmQuitView() string {
+	var sb strings.Builder
+	sb.WriteString("⏸  Processes Still Suspended\n\n")
+
+	pids := make([]int, 0, len(m.Suspended))
+	for pid := range m.Suspended {
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+	for _, pid := range pids {
+		port := m.Suspended[pid]
+		sb.WriteString(fmt.Sprintf("  Port %d - %s (PID: %d)\n", port.PortNumber, port.ProcessName, pid))
+	}
+
+	sb.WriteString("\nThey keep their ports but won't respond until resumed.\n")
+	sb.WriteString("\nPress 'r' to resume all and quit, 'y' to quit anyway, 'n' or Esc to cancel")
+
+	return sb.String()
+}
+
+// renderConfirmEscalateView displays the dialog offering an elevated retry of a permission-denied kill.
+// The exact command is shown so the user knows what will run as another user.
+func (m Model) renderConfirmEscalateView() string {
+	if m.Escalation == nil {
+		m.ViewMode = ViewModeMain
+		return m.renderMainView()
+	}
+
+	port := m.Escalation.Port
+
+	var sb strings.Builder
+	sb.WriteString("🔒 Permission Denied\n\n")
+	sb.WriteString(fmt.Sprintf("%s (PID %d) on port %d belongs to another user.\n", port.ProcessName, port.PID, port.PortNumber))
+	sb.WriteString(fmt.Sprintf("  Error: %s\n", m.Escalation.Reason))
+	sb.WriteString("\nRetry with elevated privileges? This runs:\n\n")
+	sb.WriteString(fmt.Sprintf("  %s\n", strings.Join(m.Escalation.Command, " ")))
+	sb.WriteString("\nPress 'y' to retry, 'n' or Esc to cancel")
+
+	return sb.String()
+}
+
+// renderKillScope renders the kill scope line and, for group and session kills, the member list.
+func (m Model) renderKillScope() string {
+	if m.KillScope == "" {
+		return "  Scope: process only\n"
+	}
+
+	var sb strings.Builder
+	switch {
+	case m.KillMembersErr != nil:
+		sb.WriteString(fmt.Sprintf("  Scope: process %s\n", m.KillScope))
+		sb.WriteString(fmt.Sprintf("    Cannot list members: %v\n", m.KillMembersErr))
+	case m.KillMembers == nil:
+		sb.WriteString(fmt.Sprintf("  Scope: process %s\n", m.KillScope))
+		sb.WriteString("    Listing members...\n")
+	default:
+		sb.WriteString(fmt.Sprintf("  Scope: process %s (%d processes will be signalled)\n", m.KillScope, len(m.KillMembers)))
+		for _, member := range m.KillMembers {
+			sb.WriteString(fmt.Sprintf("    %-7d %-15s %s\n", member.PID, truncateString(member.Name, 15), truncateString(member.Command, 40)))
+		}
+	}
+	return sb.String()
+}
+
+// renderHistoryView displays the history of previously killed processes.
+// It shows a list of all processes that have been terminated, with timestamps.
+func (m Model) renderHistoryView() string {
+	var sb strings.Builder
+
+	sb.WriteString("Kill History\n\n")
+
+	if m.HistoryFiltering {
+		sb.WriteString(fmt.Sprintf("Filter: %s_\n\n", m.HistoryFilter))
+	} else if m.HistoryFilter != "" {
+		sb.WriteString(fmt.Sprintf("Filter: %s\n\n", m.HistoryFilter))
+	}
+
+	if m.StatusMessage != "" {
+		sb.WriteString(m.StatusMessage + "\n\n")
+	}
+
+	if len(m.History) == 0 && m.HistoryFilter != "" {
+		sb.WriteString("No matching history.\n")
+	} else if len(m.History) == 0 {
+		sb.WriteString("No history available.\n")
 	} else {
 		for i, entry := range m.History {
 			// Format: entry number, port, process name, PID, timestamp
 			timestamp := entry.KilledAt.Format("2006-01-02 15:04:05")
-			sb.WriteString(fmt.Sprintf("%d. Port %d - %s (PID: %d)\n",
-				i+1, entry.PortNumber, entry.ProcessName, entry.PID))
+			prefix := "  "
+			if i == m.HistoryIndex {
+				prefix = "> "
+			}
+			sb.WriteString(fmt.Sprintf("%s%d. Port %d - %s (PID: %d)\n",
+				prefix, i+1, entry.PortNumber, entry.ProcessName, entry.PID))
 			sb.WriteString(fmt.Sprintf("   Command: %s\n", truncateString(entry.Command, 60)))
-			sb.WriteString(fmt.Sprintf("   Killed: %s\n\n", timestamp))
+			if entry.Manager != "" {
+				sb.WriteString(fmt.Sprintf("   Manager: %s\n", entry.Manager))
+			}
+			if entry.Outcome == models.OutcomeRespawned {
+				sb.WriteString(fmt.Sprintf("   Respawned: PID %d (%s)\n", entry.RespawnPID, entry.Supervisor))
+			}
+			if entry.Outcome == models.OutcomePortHeld {
+				sb.WriteString("   Port was still in use after the kill\n")
+			}
+			if entry.Failed {
+				sb.WriteString(fmt.Sprintf("   Failed: %s\n", entry.Error))
+			}
+			if entry.Escalated {
+				sb.WriteString("   Killed with elevated privileges\n")
+			}
+			if entry.Method != "" {
+				sb.WriteString(fmt.Sprintf("   Method: %s in %s\n", entry.Method, entry.Duration.Round(time.Millisecond)))
+			}
+			if entry.ContainerName != "" {
+				sb.WriteString(fmt.Sprintf("   Container: %s (%s)\n", entry.ContainerName, entry.ImageName))
+			}
+			if entry.Launch != nil && entry.Launch.Cwd != "" {
+				sb.WriteString(fmt.Sprintf("   Cwd: %s\n", entry.Launch.Cwd))
+			}
+			if entry.KilledBy != "" {
+				sb.WriteString(fmt.Sprintf("   Killed: %s by %s\n\n", timestamp, entry.KilledBy))
+			} else {
+				sb.WriteString(fmt.Sprintf("   Killed: %s\n\n", timestamp))
+			}
+		}
+	}
+
+	if m.HistoryCursor != "" {
+		sb.WriteString("More entries below; ↓ at the end loads them\n")
+	}
+
+	if m.HistoryFiltering {
+		sb.WriteString("Filter by port, range (3000-3999), process name, cmd:TEXT, since:7d, failed or ok; Enter applies, esc cancels")
+	} else {
+		sb.WriteString("Press / to filter, R to restart the selected process, q, esc, or h to return")
+	}
+
+	return sb.String()
+}
+
+// renderStatsView displays which ports and processes get killed most, when, and how.
+func (m Model) renderStatsView() string {
+	var sb strings.Builder
+
+	period := "all time"
+	if age := statsWindows[m.StatsWindow]; age > 0 {
+		period = fmt.Sprintf("last %d days", int(age.Hours()/24))
+	}
+	sb.WriteString(fmt.Sprintf("Kill Stats (%s)\n\n", period))
+
+	switch {
+	case m.StatsError != nil:
+		sb.WriteString(fmt.Sprintf("Failed to load stats: %v\n\n", m.StatsError))
+	case m.Stats == nil:
+		sb.WriteString("Loading...\n\n")
+	case m.Stats.Attempts == 0:
+		sb.WriteString("No kills in this period.\n\n")
+	default:
+		stats := m.Stats
+		sb.WriteString(fmt.Sprintf("Kills: %d (%d failed attempts)\n", stats.Kills, stats.Failed))
+		if stats.MedianDuration > 0 {
+			sb.WriteString(fmt.Sprintf("Median time to terminate: %s\n", stats.MedianDuration.Round(time.Millisecond)))
+		}
+		if len(stats.Methods) > 0 {
+			sb.WriteString(fmt.Sprintf("SIGKILL vs SIGTERM: %d : %d (%.0f%% of kills needed SIGKILL)\n",
+				stats.MethodKills("SIGKILL"), stats.MethodKills("SIGTERM"), stats.ForcedRatio*100))
+		}
+
+		if len(stats.TopPorts) > 0 {
+			sb.WriteString("\nTop ports:\n")
+			for _, c := range stats.TopPorts {
+				sb.WriteString(fmt.Sprintf("  %-6d %-20s %d\n", c.Port, truncateString(c.ProcessName, 20), c.Kills))
+			}
+		}
+		if len(stats.TopProcesses) > 0 {
+			sb.WriteString("\nTop processes:\n")
+			for _, c := range stats.TopProcesses {
+				sb.WriteString(fmt.Sprintf("  %-27s %d\n", truncateString(c.ProcessName, 27), c.Kills))
+			}
+		}
+
+		if len(stats.PerDay) > 0 {
+			// The most recent two weeks with kills fit on screen
+			days := stats.PerDay
+			if len(days) > 14 {
+				days = days[len(days)-14:]
+			}
+			max := 0
+			for _, d := range days {
+				if d.Kills > max {
+					max = d.Kills
+				}
+			}
+			sb.WriteString("\nKills per day:\n")
+			for _, d := range days {
+				sb.WriteString(fmt.Sprintf("  %s %s %d\n", d.Day, bar(d.Kills, max, 30), d.Kills))
+			}
+		}
+
+		max := 0
+		for _, n := range stats.PerHour {
+			if n > max {
+				max = n
+			}
+		}
+		if max > 0 {
+			sb.WriteString("\nTime of day:\n")
+			for hour, n := range stats.PerHour {
+				if n > 0 {
+					sb.WriteString(fmt.Sprintf("  %02d:00 %s %d\n", hour, bar(n, max, 30), n))
+				}
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("Press w to change the period, q, esc, or S to return")
+
+	return sb.String()
+}
+
+// renderTimelineView lists who listened on TimelinePort in the selected period, newest first.
+func (m Model) renderTimelineView() string {
+	var sb strings.Builder
+
+	period := "everything recorded"
+	switch age := timelineWindows[m.TimelineWindow]; {
+	case age == 24*time.Hour:
+		period = "last 24 hours"
+	case age > 0:
+		period = fmt.Sprintf("last %d days", int(age.Hours()/24))
+	}
+	sb.WriteString(fmt.Sprintf("Port %d Timeline (%s)\n\n", m.TimelinePort, period))
+
+	holdings := m.TimelineHoldings
+	switch {
+	case m.TimelineError != nil:
+		sb.WriteString(fmt.Sprintf("Failed to load the timeline: %v\n\n", m.TimelineError))
+	case m.TimelineLoading:
+		sb.WriteString("Loading...\n\n")
+	case len(holdings) == 0:
+		sb.WriteString("Nothing listened on this port in this period.\n\n")
+	default:
+		// Newest first, as many as fit
+		limit := m.Height - 6
+		if limit < 5 {
+			limit = 5
+		}
+		shown := 0
+		for i := len(holdings) - 1; i >= 0 && shown < limit; i-- {
+			sb.WriteString(timelineLine(holdings[i]))
+			shown++
+		}
+		if more := len(holdings) - shown; more > 0 {
+			sb.WriteString(fmt.Sprintf("  ... %d earlier\n", more))
 		}
+		sb.WriteString("\n")
 	}
 
-	sb.WriteString("Press q, esc, or h to return")
+	sb.WriteString("Press w to change the period, q, esc, or t to return")
 
 	return sb.String()
 }
 
+// timelineLine renders one listener's span on the port: when, how long, and which process.
+func timelineLine(h models.PortHolding) string {
+	const layout = "2006-01-02 15:04:05"
+	from, until, length := "(earlier)", "now", ""
+	if !h.From.IsZero() {
+		from = h.From.Format(layout)
+	}
+	if !h.Open() {
+		until = h.Until.Format(layout)
+	}
+	if !h.From.IsZero() {
+		end := h.Until
+		if h.Open() {
+			end = time.Now()
+		}
+		length = end.Sub(h.From).Round(time.Second).String()
+	}
+
+	process := fmt.Sprintf("%s (PID %d)", h.ProcessName, h.PID)
+	if h.ContainerName != "" {
+		process += " [" + h.ContainerName + "]"
+	}
+	return fmt.Sprintf("  %s → %-19s %9s  %-30s %s\n",
+		from, until, length, truncateString(process, 30), truncateString(h.Command, 40))
+}
+
+// bar renders n as a horizontal bar, scaled so that max fills width.
+func bar(n, max, width int) string {
+	if max <= 0 || n <= 0 {
+		return ""
+	}
+	length := n * width / max
+	if length == 0 {
+		length = 1
+	}
+	return strings.Repeat("█", length)
+}
+
+// managedLabel returns the name a process manager knows the port's process by,
+// falling back to the process name when the manager did not report one.
+func managedLabel(port models.PortInfo) string {
+	if port.ManagedName != "" {
+		return port.ManagedName
+	}
+	return port.ProcessName
+}
+
 // truncateString truncates a string to a maximum length, appending "..." if truncated.
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -534,18 +1835,32 @@ func (m Model) renderHelpView() string {
 
 	sb.WriteString("Actions:\n")
 	sb.WriteString("  Enter      Kill selected process\n")
+	sb.WriteString("  s          Cycle kill signal (in kill dialog)\n")
+	sb.WriteString("  g          Cycle kill scope: process, group, session (in kill dialog)\n")
+	sb.WriteString("  y          Retry with sudo/helper after a permission-denied kill\n")
+	sb.WriteString("  R          Kill and restart with the same command, cwd and env (in kill dialog)\n")
+	sb.WriteString("  p          Suspend or resume selected process (SIGSTOP/SIGCONT)\n")
+	sb.WriteString("  K          Kill every recommended process, after confirmation\n")
+	sb.WriteString("  o          Sort by scan order or recommendation score\n")
 	sb.WriteString("  d          Toggle Docker-only filter\n")
+	sb.WriteString("  l          Label the selected port and add a note (Tab switches field, Ctrl+P scope)\n")
 	sb.WriteString("  r/Ctrl+R   Refresh port list\n\n")
 
 	sb.WriteString("Views:\n")
-	sb.WriteString("  h          Show kill history\n")
+	sb.WriteString("  h          Show kill history (R restarts the selected entry, / filters it)\n")
+	sb.WriteString("  S          Show kill statistics (w changes the period)\n")
+	sb.WriteString("  t          Show who listened on the selected port over time (w changes the period)\n")
 	sb.WriteString("  ?          Show this help screen\n")
 	sb.WriteString("  q/Esc      Quit or return to main view\n\n")
 
 	sb.WriteString("Markers:\n")
 	sb.WriteString("  [D]        Docker container port\n")
-	sb.WriteString("  [!]        Frequently killed (recommended)\n")
-	sb.WriteString("  [S]        System process (be careful)\n")
+	sb.WriteString("  [M]        Supervised by a process manager (pm2, supervisord, ...)\n")
+	sb.WriteString("  [!]        Recommended: often or recently killed, orphaned or idle\n")
+	sb.WriteString("  [Protected: rule]  Policy blocks killing this process\n")
+	sb.WriteString("  [Confirm: rule]    Policy asks you to type the process name first\n")
+	sb.WriteString("  [Warning: rule]    Policy warns before killing\n")
+	sb.WriteString("  [PAUSED]   Suspended with p, holding its port\n")
 	sb.WriteString("  [NEW]      New port since last scan\n")
 	sb.WriteString("  [GONE]     Port removed since last scan\n\n")
 
@@ -557,12 +1872,47 @@ func (m Model) renderHelpView() string {
 // handleMainKeyMsg handles keyboard input when in the main port list view.
 // Supports navigation, selection, filtering, and process kill initiation.
 func (m Model) handleMainKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.LabelEditing {
+		return m.handleLabelKeyMsg(msg)
+	}
+
 	switch msg.String() {
 	case "q", "ctrl+c":
+		// Warn first if quitting would leave processes suspended
+		if len(m.Suspended) > 0 {
+			m.ViewMode = ViewModeConfirmQuit
+			return m, nil
+		}
 		// Quit the application
 		m.Quit = true
 		return m, tea.Quit
 
+	case "p":
+		// Suspend the selected process, or resume it if already suspended
+		if !m.isValidSelection() {
+			return m, nil
+		}
+		port := m.FilteredPorts[m.SelectedIndex]
+		if _, suspended := m.Suspended[port.PID]; !suspended {
+			// Suspending is subject to the policy like a kill; resuming never is
+			switch decision := m.decide(port); decision.Action {
+			case policy.ActionBlock:
+				m.StatusMessage = fmt.Sprintf("Suspend refused: %s is protected by policy rule %q", port.ProcessName, decision.Rule)
+				m.StatusMessageTimeout = time.Now().Add(time.Second * 3)
+				return m, nil
+			case policy.ActionConfirm:
+				m.KillConfirmationPort = &m.FilteredPorts[m.SelectedIndex]
+				m.KillSignal = ""
+				m.resetKillScope()
+				m.resetConfirmation()
+				m.ConfirmTyping = true
+				m.ConfirmSuspend = true
+				m.ViewMode = ViewModeConfirmKill
+				return m, nil
+			}
+		}
+		return m, m.toggleSuspendCmd()
+
 	case "up", "k":
 		// Move selection up (vim-style k also supported)
 		m.moveSelection(-1)
@@ -587,6 +1937,9 @@ func (m Model) handleMainKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Open kill confirmation dialog for selected port
 		if m.isValidSelection() {
 			m.KillConfirmationPort = &m.FilteredPorts[m.SelectedIndex]
+			m.KillSignal = ""
+			m.resetKillScope()
+			m.resetConfirmation()
 			m.ViewMode = ViewModeConfirmKill
 		}
 		return m, nil
@@ -597,6 +1950,32 @@ func (m Model) handleMainKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.applyFilters()
 		return m, nil
 
+	case "o":
+		// Switch between scan order and the most recommended ports first
+		if m.SortMode == SortByScan {
+			m.SortMode = SortByScore
+		} else {
+			m.SortMode = SortByScan
+		}
+		m.applyFilters()
+		return m, nil
+
+	case "K":
+		// Confirm killing every recommended process the policy doesn't protect
+		targets, skipped := m.recommendedTargets()
+		if len(targets) == 0 {
+			m.StatusMessage = "No recommended processes to kill"
+			if len(skipped) > 0 {
+				m.StatusMessage = "Every recommended process is protected by policy"
+			}
+			m.StatusMessageTimeout = time.Now().Add(time.Second * 3)
+			return m, nil
+		}
+		m.KillTargets = targets
+		m.KillSkipped = skipped
+		m.ViewMode = ViewModeConfirmKillRecommended
+		return m, nil
+
 	case "h":
 		// Open history view
 		m.ViewMode = ViewModeHistory
@@ -607,6 +1986,49 @@ func (m Model) handleMainKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.ViewMode = ViewModeHelp
 		return m, nil
 
+	case "S":
+		// Open stats view; without storage there is nothing to aggregate
+		if m.Storage == nil {
+			m.StatusMessage = "Stats need kill history, which is disabled"
+			m.StatusMessageTimeout = time.Now().Add(time.Second * 3)
+			return m, nil
+		}
+		m.ViewMode = ViewModeStats
+		m.Stats = nil
+		return m, m.loadStatsCmd()
+
+	case "l":
+		// Edit the label that applies to the selected port, or start a new one for the whole port
+		if m.Storage == nil || !m.isValidSelection() {
+			return m, nil
+		}
+		port := m.FilteredPorts[m.SelectedIndex]
+		draft, ok := models.LabelFor(m.Labels, port)
+		if !ok {
+			draft = models.PortLabel{Port: port.PortNumber}
+		}
+		m.LabelEditing = true
+		m.LabelDraft = draft
+		m.LabelEditingNote = false
+		return m, nil
+
+	case "t":
+		// Open the selected port's timeline; it is only there if recording is enabled
+		if m.Timeline == nil {
+			m.StatusMessage = "Port timeline recording is off (enable timeline in the config)"
+			m.StatusMessageTimeout = time.Now().Add(time.Second * 3)
+			return m, nil
+		}
+		if !m.isValidSelection() {
+			return m, nil
+		}
+		m.ViewMode = ViewModeTimeline
+		m.TimelinePort = m.FilteredPorts[m.SelectedIndex].PortNumber
+		m.TimelineHoldings = nil
+		m.TimelineLoading = true
+		m.TimelineError = nil
+		return m, m.loadTimelineCmd()
+
 	case "r", "ctrl+r":
 		// Manual refresh of port list
 		m.Loading = true
@@ -617,17 +2039,360 @@ func (m Model) handleMainKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 // handleConfirmKeyMsg handles keyboard input in the kill confirmation dialog.
-// 'y' confirms the kill, 'n' or 'Esc' cancels.
+// 'y' confirms the kill, 's' cycles the signal, 'g' cycles the scope, 'n' or 'Esc' cancels.
+// Ports protected by a "block" policy rule can't be killed; "confirm" rules first ask for the process name.
 func (m Model) handleConfirmKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.ConfirmTyping {
+		return m.handleTypedConfirmKeyMsg(msg)
+	}
+
 	switch msg.String() {
-	case "y", "Y":
+	case "y", "Y", "R":
+		restart := msg.String() == "R"
+		if !m.isValidSelection() {
+			return m, nil
+		}
+		port := m.FilteredPorts[m.SelectedIndex]
+		switch decision := m.decide(port); decision.Action {
+		case policy.ActionBlock:
+			m.ViewMode = ViewModeMain
+			m.KillConfirmationPort = nil
+			m.StatusMessage = fmt.Sprintf("Kill refused: %s is protected by policy rule %q", port.ProcessName, decision.Rule)
+			m.StatusMessageTimeout = time.Now().Add(time.Second * 3)
+			return m, nil
+		case policy.ActionConfirm:
+			if !m.KillConfirmed {
+				m.ConfirmTyping = true
+				m.ConfirmInput = ""
+				m.ConfirmRestart = restart
+				return m, nil
+			}
+		}
+		if restart {
+			// Kill, then relaunch with the same command line, working directory and environment
+			return m, m.killAndRestartCmd()
+		}
 		// User confirmed - execute the kill command
 		return m, m.killPortCmd()
 
+	case "s", "S":
+		// Cycle through the selectable signals for this kill
+		m.KillSignal = nextKillSignal(m.KillSignal)
+		return m, nil
+
+	case "g", "G":
+		// Cycle between the process, its process group and its session
+		m.KillScope = nextKillScope(m.KillScope)
+		m.KillMembers = nil
+		m.KillMembersErr = nil
+		if m.KillScope == "" {
+			return m, nil
+		}
+		return m, m.loadMembersCmd()
+
+	case "m", "M":
+		// Stop through the process manager so it doesn't respawn the process
+		if m.isValidSelection() && len(m.FilteredPorts[m.SelectedIndex].StopCommand) > 0 {
+			return m, m.stopManagedCmd()
+		}
+		return m, nil
+
 	case "n", "N", "esc":
 		// User cancelled - return to main view
 		m.ViewMode = ViewModeMain
 		m.KillConfirmationPort = nil
+		m.KillSignal = ""
+		m.resetKillScope()
+		m.resetConfirmation()
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// handleKillRecommendedKeyMsg handles the dialog listing the recommended processes:
+// 'y' kills them all, 'n' or Esc cancels.
+func (m Model) handleKillRecommendedKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		m.ViewMode = ViewModeMain
+		m.StatusMessage = fmt.Sprintf("Killing %d recommended processes...", len(m.KillTargets))
+		m.StatusMessageTimeout = time.Now().Add(time.Minute)
+		return m, m.killRecommendedCmd()
+
+	case "n", "N", "esc":
+		m.ViewMode = ViewModeMain
+		m.KillTargets = nil
+		m.KillSkipped = nil
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// recommendedTargets returns the listed ports recommended for killing, highest score first and
+// one per process, split into those the policy allows and those it blocks or guards with a
+// typed confirmation, which can't be killed in bulk.
+func (m Model) recommendedTargets() (targets, skipped []models.PortInfo) {
+	seen := make(map[int]bool)
+	for _, port := range m.sortByScore(m.FilteredPorts) {
+		if !port.IsRecommended() || port.PID <= 0 || seen[port.PID] {
+			continue
+		}
+		seen[port.PID] = true
+		switch m.decide(port).Action {
+		case policy.ActionBlock, policy.ActionConfirm:
+			skipped = append(skipped, port)
+		default:
+			targets = append(targets, port)
+		}
+	}
+	return targets, skipped
+}
+
+// handleTypedConfirmKeyMsg collects the process name a "confirm" policy rule asks for.
+// Enter kills only if the input matches the name exactly; Esc stops typing.
+func (m Model) handleTypedConfirmKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		if !m.isValidSelection() {
+			return m, nil
+		}
+		port := m.FilteredPorts[m.SelectedIndex]
+		if m.ConfirmInput != port.ProcessName {
+			m.StatusMessage = fmt.Sprintf("Confirmation doesn't match %q", port.ProcessName)
+			m.StatusMessageTimeout = time.Now().Add(time.Second * 3)
+			m.ConfirmInput = ""
+			return m, nil
+		}
+		m.ConfirmTyping = false
+		m.KillConfirmed = true
+		if m.ConfirmSuspend {
+			cmd := m.toggleSuspendCmd()
+			m.ViewMode = ViewModeMain
+			m.KillConfirmationPort = nil
+			m.resetConfirmation()
+			return m, cmd
+		}
+		if m.ConfirmRestart {
+			return m, m.killAndRestartCmd()
+		}
+		return m, m.killPortCmd()
+
+	case tea.KeyEsc:
+		// A suspend has no dialog of its own to return to
+		if m.ConfirmSuspend {
+			m.ViewMode = ViewModeMain
+			m.KillConfirmationPort = nil
+		}
+		m.resetConfirmation()
+		return m, nil
+
+	case tea.KeyBackspace:
+		if runes := []rune(m.ConfirmInput); len(runes) > 0 {
+			m.ConfirmInput = string(runes[:len(runes)-1])
+		}
+		return m, nil
+
+	case tea.KeyRunes, tea.KeySpace:
+		m.ConfirmInput += string(msg.Runes)
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// resetConfirmation clears any typed policy confirmation.
+func (m *Model) resetConfirmation() {
+	m.ConfirmTyping = false
+	m.ConfirmInput = ""
+	m.ConfirmRestart = false
+	m.ConfirmSuspend = false
+	m.KillConfirmed = false
+}
+
+// nextKillSignal returns the signal after current in KillSignals, wrapping around to the strategy default.
+func nextKillSignal(current string) string {
+	for i, sig := range KillSignals {
+		if sig == current {
+			return KillSignals[(i+1)%len(KillSignals)]
+		}
+	}
+	return KillSignals[0]
+}
+
+// nextKillScope returns the scope after current in KillScopes, wrapping around to the single process.
+func nextKillScope(current string) string {
+	for i, scope := range KillScopes {
+		if scope == current {
+			return KillScopes[(i+1)%len(KillScopes)]
+		}
+	}
+	return KillScopes[0]
+}
+
+// resetKillScope returns the dialog to a single-process kill.
+func (m *Model) resetKillScope() {
+	m.KillScope = ""
+	m.KillMembers = nil
+	m.KillMembersErr = nil
+}
+
+// killOptions returns the options for the pending kill based on the dialog state.
+func (m Model) killOptions() KillOptions {
+	return KillOptions{Signal: m.KillSignal, Scope: m.KillScope, Confirmed: m.KillConfirmed}
+}
+
+// loadMembersCmd returns a command that lists the selected port's group or session members.
+// The command runs asynchronously and sends a MembersLoadedMsg when complete.
+func (m Model) loadMembersCmd() tea.Cmd {
+	if !m.isValidSelection() || m.Killer == nil {
+		return nil
+	}
+
+	port := m.FilteredPorts[m.SelectedIndex]
+	scope := m.KillScope
+
+	return func() tea.Msg {
+		members, err := m.Killer.Members(port, scope)
+		return MembersLoadedMsg{Scope: scope, Members: members, Error: err}
+	}
+}
+
+// handleSuspended records a completed suspend or resume and reports it in the status line.
+func (m Model) handleSuspended(msg SuspendedMsg) (tea.Model, tea.Cmd) {
+	if msg.Error != nil {
+		action := "Resume"
+		if msg.Suspended {
+			action = "Suspend"
+		}
+		m.StatusMessage = fmt.Sprintf("%s failed: %v", action, msg.Error)
+		m.StatusMessageTimeout = time.Now().Add(time.Second * 3)
+		return m, nil
+	}
+
+	if msg.Suspended {
+		m.Suspended = withSuspended(m.Suspended, msg.Port)
+		m.StatusMessage = fmt.Sprintf("Suspended %s (PID %d) - press p to resume", msg.Port.ProcessName, msg.Port.PID)
+	} else {
+		m.Suspended = withoutSuspended(m.Suspended, msg.Port.PID)
+		m.StatusMessage = fmt.Sprintf("Resumed %s (PID %d)", msg.Port.ProcessName, msg.Port.PID)
+	}
+	m.StatusMessageTimeout = time.Now().Add(time.Second * 3)
+	return m, nil
+}
+
+// withSuspended returns a copy of suspended with port's PID added.
+// The map is copied rather than mutated because Model is passed by value.
+func withSuspended(suspended map[int]models.PortInfo, port models.PortInfo) map[int]models.PortInfo {
+	next := make(map[int]models.PortInfo, len(suspended)+1)
+	for pid, info := range suspended {
+		next[pid] = info
+	}
+	next[port.PID] = port
+	return next
+}
+
+// withoutSuspended returns a copy of suspended without pid.
+func withoutSuspended(suspended map[int]models.PortInfo, pid int) map[int]models.PortInfo {
+	if _, ok := suspended[pid]; !ok {
+		return suspended
+	}
+	next := make(map[int]models.PortInfo, len(suspended))
+	for p, info := range suspended {
+		if p != pid {
+			next[p] = info
+		}
+	}
+	return next
+}
+
+// pruneSuspended drops suspended PIDs that hold none of the scanned ports.
+// A stopped process keeps its sockets, so it only disappears from a scan once it is gone.
+func pruneSuspended(suspended map[int]models.PortInfo, ports []models.PortInfo) map[int]models.PortInfo {
+	if len(suspended) == 0 {
+		return suspended
+	}
+	alive := make(map[int]bool, len(ports))
+	for _, port := range ports {
+		alive[port.PID] = true
+	}
+	next := make(map[int]models.PortInfo, len(suspended))
+	for pid, info := range suspended {
+		if alive[pid] {
+			next[pid] = info
+		}
+	}
+	return next
+}
+
+// toggleSuspendCmd returns a command that suspends the selected port's process, or resumes it
+// if it is already suspended. It sends a SuspendedMsg when complete.
+func (m Model) toggleSuspendCmd() tea.Cmd {
+	if !m.isValidSelection() || m.Killer == nil {
+		return nil
+	}
+
+	port := m.FilteredPorts[m.SelectedIndex]
+	_, suspended := m.Suspended[port.PID]
+	confirmed := m.KillConfirmed
+
+	return func() tea.Msg {
+		if suspended {
+			return SuspendedMsg{Port: port, Suspended: false, Error: m.Killer.Resume(port)}
+		}
+		return SuspendedMsg{Port: port, Suspended: true, Error: m.Killer.Suspend(port, confirmed)}
+	}
+}
+
+// resumeAllAndQuitCmd returns a command that resumes every suspended process and then quits.
+// Resume errors are ignored: the process may have exited meanwhile, and quitting must not hang.
+func (m Model) resumeAllAndQuitCmd() tea.Cmd {
+	suspended := m.Suspended
+	killer := m.Killer
+
+	return func() tea.Msg {
+		for _, port := range suspended {
+			killer.Resume(port)
+		}
+		return tea.Quit()
+	}
+}
+
+// handleQuitKeyMsg handles keyboard input in the quit warning shown while processes are suspended.
+// 'r' resumes them all and quits, 'y' or Ctrl+C quits and leaves them stopped, 'n' or 'Esc' stays.
+func (m Model) handleQuitKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "r", "R":
+		m.Quit = true
+		return m, m.resumeAllAndQuitCmd()
+
+	case "y", "Y", "ctrl+c":
+		m.Quit = true
+		return m, tea.Quit
+
+	case "n", "N", "esc":
+		m.ViewMode = ViewModeMain
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// handleEscalateKeyMsg handles keyboard input in the escalation dialog.
+// 'y' retries the kill with elevated privileges, 'n' or 'Esc' gives up.
+func (m Model) handleEscalateKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		cmd := m.escalateKillCmd()
+		m.ViewMode = ViewModeMain
+		m.Escalation = nil
+		m.Loading = true
+		return m, cmd
+
+	case "n", "N", "esc":
+		m.ViewMode = ViewModeMain
+		m.Escalation = nil
 		return m, nil
 	}
 
@@ -635,12 +2400,234 @@ func (m Model) handleConfirmKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 // handleHistoryKeyMsg handles keyboard input in the history view.
-// Any of q, esc, or h returns to the main view.
+// Arrow keys or k/j select an entry (moving past the last one loads the next page), / edits the filter,
+// R restarts the selected entry, and any of q, esc, or h returns to the main view.
 func (m Model) handleHistoryKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.HistoryFiltering {
+		return m.handleHistoryFilterKeyMsg(msg)
+	}
+
 	switch msg.String() {
 	case "q", "esc", "h":
 		m.ViewMode = ViewModeMain
 		return m, nil
+
+	case "up", "k":
+		if m.HistoryIndex > 0 {
+			m.HistoryIndex--
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.HistoryIndex < len(m.History)-1 {
+			m.HistoryIndex++
+			return m, nil
+		}
+		if m.HistoryCursor != "" && !m.HistoryLoading && m.Storage != nil {
+			m.HistoryLoading = true
+			return m, m.loadMoreHistoryCmd()
+		}
+		return m, nil
+
+	case "/":
+		m.HistoryFiltering = true
+		return m, nil
+
+	case "R":
+		// Relaunch the selected entry's process with its recorded context
+		if m.HistoryIndex < 0 || m.HistoryIndex >= len(m.History) {
+			return m, nil
+		}
+		entry := m.History[m.HistoryIndex]
+		if entry.Launch == nil || m.Launcher == nil {
+			m.StatusMessage = fmt.Sprintf("Can't restart %s: no launch context was recorded", entry.ProcessName)
+			m.StatusMessageTimeout = time.Now().Add(time.Second * 3)
+			return m, nil
+		}
+		// Entries stored redacted (imported from an export) would start the process with the mask as a value
+		if entry.Launch.IsRedacted() {
+			m.StatusMessage = fmt.Sprintf("Can't restart %s: its launch context was stored redacted", entry.ProcessName)
+			m.StatusMessageTimeout = time.Now().Add(time.Second * 3)
+			return m, nil
+		}
+		m.ViewMode = ViewModeMain
+		m.StatusMessage = fmt.Sprintf("Restarting %s...", entry.ProcessName)
+		m.StatusMessageTimeout = time.Now().Add(time.Second * 3)
+		return m, m.restartCmd(*entry.Launch, entry.PortNumber, entry.ProcessName)
+	}
+	return m, nil
+}
+
+// handleHistoryFilterKeyMsg edits the history filter. Enter applies it and reloads history from
+// the first page (an empty filter shows everything again); Esc stops editing without applying.
+func (m Model) handleHistoryFilterKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		query, err := parseHistoryFilter(m.HistoryFilter, time.Now())
+		if err != nil {
+			m.StatusMessage = fmt.Sprintf("Invalid filter: %v", err)
+			m.StatusMessageTimeout = time.Now().Add(time.Second * 3)
+			return m, nil
+		}
+		m.HistoryFiltering = false
+		m.HistoryQuery = query
+		m.HistoryCursor = ""
+		if m.Storage == nil {
+			return m, nil
+		}
+		m.HistoryLoading = true
+		return m, m.loadHistoryCmd()
+
+	case tea.KeyEsc:
+		m.HistoryFiltering = false
+		return m, nil
+
+	case tea.KeyBackspace:
+		if runes := []rune(m.HistoryFilter); len(runes) > 0 {
+			m.HistoryFilter = string(runes[:len(runes)-1])
+		}
+		return m, nil
+
+	case tea.KeyRunes, tea.KeySpace:
+		m.HistoryFilter += string(msg.Runes)
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// parseHistoryFilter turns the history filter text into a query. Each word narrows the results:
+// a port (3000) or port range (3000-3999), cmd:TEXT for a command substring, since:DURATION
+// (e.g. 7d or 12h before now), failed or ok, and anything else is a process name.
+func parseHistoryFilter(text string, now time.Time) (models.HistoryQuery, error) {
+	var q models.HistoryQuery
+	for _, word := range strings.Fields(text) {
+		switch lower := strings.ToLower(word); {
+		case lower == "failed":
+			q.Status = models.StatusFailed
+		case lower == "ok" || lower == "succeeded":
+			q.Status = models.StatusSucceeded
+		case strings.HasPrefix(lower, "cmd:"):
+			q.Command = word[len("cmd:"):]
+		case strings.HasPrefix(lower, "since:"):
+			age, err := config.ParseDuration(word[len("since:"):])
+			if err != nil {
+				return models.HistoryQuery{}, err
+			}
+			q.Since = now.Add(-age)
+		case word[0] >= '0' && word[0] <= '9':
+			ports, err := policy.ParseRange(word)
+			if err != nil {
+				return models.HistoryQuery{}, err
+			}
+			if ports.Min == ports.Max {
+				q.Port = ports.Min
+			} else {
+				q.PortMin, q.PortMax = ports.Min, ports.Max
+			}
+		default:
+			q.ProcessName = word
+		}
+	}
+	return q, nil
+}
+
+// handleStatsKeyMsg handles keyboard input in the stats view.
+// w cycles the period the statistics cover, and any of q, esc, or S returns to the main view.
+func (m Model) handleStatsKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc", "S":
+		m.ViewMode = ViewModeMain
+		return m, nil
+
+	case "w":
+		m.StatsWindow = (m.StatsWindow + 1) % len(statsWindows)
+		m.Stats = nil
+		return m, m.loadStatsCmd()
+	}
+	return m, nil
+}
+
+// handleLabelKeyMsg edits the selected port's label inline. Tab switches between the label and
+// the note, Ctrl+P changes what the label applies to, Enter saves it (emptying both deletes it),
+// and Esc stops editing without saving.
+func (m Model) handleLabelKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	field := &m.LabelDraft.Label
+	if m.LabelEditingNote {
+		field = &m.LabelDraft.Note
+	}
+
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.LabelEditing = false
+		return m, m.saveLabelCmd(m.LabelDraft)
+
+	case tea.KeyEsc:
+		m.LabelEditing = false
+		return m, nil
+
+	case tea.KeyTab:
+		m.LabelEditingNote = !m.LabelEditingNote
+		return m, nil
+
+	case tea.KeyCtrlP:
+		if m.isValidSelection() {
+			m.LabelDraft = nextLabelScope(m.LabelDraft, m.FilteredPorts[m.SelectedIndex])
+		}
+		return m, nil
+
+	case tea.KeyBackspace:
+		if runes := []rune(*field); len(runes) > 0 {
+			*field = string(runes[:len(runes)-1])
+		}
+		return m, nil
+
+	case tea.KeyRunes, tea.KeySpace:
+		*field += string(msg.Runes)
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// nextLabelScope moves draft to the next scope it can apply to for port: the whole port, the
+// process name, the project (the process's working directory), then both; the text is kept.
+func nextLabelScope(draft models.PortLabel, port models.PortInfo) models.PortLabel {
+	scopes := []models.PortLabel{
+		{Port: port.PortNumber},
+		{Port: port.PortNumber, ProcessName: port.ProcessName},
+	}
+	if port.WorkDir != "" {
+		scopes = append(scopes,
+			models.PortLabel{Port: port.PortNumber, Project: port.WorkDir},
+			models.PortLabel{Port: port.PortNumber, ProcessName: port.ProcessName, Project: port.WorkDir})
+	}
+
+	next := scopes[0]
+	for i, scope := range scopes {
+		if scope.SameScope(draft) {
+			next = scopes[(i+1)%len(scopes)]
+			break
+		}
+	}
+	draft.ProcessName, draft.Project = next.ProcessName, next.Project
+	return draft
+}
+
+// handleTimelineKeyMsg handles keyboard input in the timeline view.
+// w cycles the period the timeline covers, and any of q, esc, or t returns to the main view.
+func (m Model) handleTimelineKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc", "t":
+		m.ViewMode = ViewModeMain
+		return m, nil
+
+	case "w":
+		m.TimelineWindow = (m.TimelineWindow + 1) % len(timelineWindows)
+		m.TimelineHoldings = nil
+		m.TimelineLoading = true
+		m.TimelineError = nil
+		return m, m.loadTimelineCmd()
 	}
 	return m, nil
 }
@@ -693,6 +2680,7 @@ func (m *Model) applyFilters() {
 		}
 		m.FilteredPorts = dockerPorts
 	}
+	m.FilteredPorts = m.sortPorts(m.FilteredPorts)
 
 	// Adjust selection index if filter reduced the list
 	if len(m.FilteredPorts) > 0 {
@@ -704,6 +2692,24 @@ func (m *Model) applyFilters() {
 	}
 }
 
+// sortPorts returns ports in the order of the sort mode; the scan order returns them as they are.
+func (m Model) sortPorts(ports []models.PortInfo) []models.PortInfo {
+	if m.SortMode == SortByScore {
+		return m.sortByScore(ports)
+	}
+	return ports
+}
+
+// sortByScore returns a copy of ports with the highest recommendation score first,
+// keeping the scan order among equal scores.
+func (m Model) sortByScore(ports []models.PortInfo) []models.PortInfo {
+	sorted := append([]models.PortInfo(nil), ports...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].RecommendationScore() > sorted[j].RecommendationScore()
+	})
+	return sorted
+}
+
 // killPortCmd returns a command that kills the currently selected port's process.
 // The command runs asynchronously and sends a PortKilledMsg when complete.
 func (m Model) killPortCmd() tea.Cmd {
@@ -712,23 +2718,197 @@ func (m Model) killPortCmd() tea.Cmd {
 	}
 
 	port := m.FilteredPorts[m.SelectedIndex]
+	opts := m.killOptions()
+	_, suspended := m.Suspended[port.PID]
 
 	return func() tea.Msg {
-		err := m.Killer.Kill(port)
+		return m.killPort(port, opts, suspended)
+	}
+}
 
+// killPort runs the pre-kill hooks, kills port's process with opts and runs the post-kill hooks.
+// A suspended process is resumed first.
+func (m Model) killPort(port models.PortInfo, opts KillOptions, suspended bool) PortKilledMsg {
+	runs, err := m.runHooks(hooks.PreKill, port, nil)
+	if err != nil {
+		return PortKilledMsg{Port: port, Message: err.Error(), Vetoed: true, Hooks: runs, Options: opts}
+	}
+
+	// A stopped process can't handle a graceful signal, so continue it first
+	if suspended {
+		m.Killer.Resume(port)
+	}
+	start := time.Now()
+	report, err := m.Killer.Kill(port, opts)
+
+	if err != nil {
+		return m.withPostKillHooks(PortKilledMsg{
+			Port:             port,
+			Success:          false,
+			Message:          err.Error(),
+			Stale:            errors.Is(err, ErrStaleTarget),
+			PermissionDenied: errors.Is(err, ErrPermissionDenied),
+			Options:          opts,
+			Hooks:            runs,
+			Duration:         time.Since(start),
+		})
+	}
+
+	return m.withPostKillHooks(PortKilledMsg{
+		Port:     port,
+		Success:  true,
+		Message:  "Process killed successfully",
+		Report:   report,
+		Options:  opts,
+		Hooks:    runs,
+		Duration: time.Since(start),
+	})
+}
+
+// killRecommendedCmd returns a command that kills the processes in KillTargets one after another
+// with their default strategy. It sends a RecommendedKilledMsg when they are all done.
+func (m Model) killRecommendedCmd() tea.Cmd {
+	targets := m.KillTargets
+	return func() tea.Msg {
+		results := make([]PortKilledMsg, len(targets))
+		for i, port := range targets {
+			_, suspended := m.Suspended[port.PID]
+			results[i] = m.killPort(port, KillOptions{}, suspended)
+		}
+		return RecommendedKilledMsg{Results: results}
+	}
+}
+
+// runHooks runs the hooks for event; without a hook runner nothing runs.
+func (m Model) runHooks(event hooks.Event, port models.PortInfo, result *hooks.Result) ([]models.HookRun, error) {
+	if m.Hooks == nil {
+		return nil, nil
+	}
+	return m.Hooks.Run(context.Background(), event, port, result)
+}
+
+// withPostKillHooks runs the post-kill hooks with the outcome of msg's kill and adds their runs to msg.
+// Post-kill hooks can't undo a kill, so their failures only show up in the runs.
+func (m Model) withPostKillHooks(msg PortKilledMsg) PortKilledMsg {
+	result := &hooks.Result{Success: msg.Success, Message: msg.Message}
+	if msg.Report != nil {
+		result.Report = msg.Report
+	}
+	runs, _ := m.runHooks(hooks.PostKill, msg.Port, result)
+	msg.Hooks = append(msg.Hooks, runs...)
+	return msg
+}
+
+// renderHookRuns renders one line per hook run plus the last lines of its output.
+func renderHookRuns(runs []models.HookRun) string {
+	var sb strings.Builder
+	for _, run := range runs {
+		status := fmt.Sprintf("ok in %s", run.Duration.Round(time.Millisecond))
+		if run.Failed() {
+			status = "failed: " + run.Error
+		}
+		sb.WriteString(fmt.Sprintf("Hook %s (%s): %s\n", run.Hook, run.Event, status))
+
+		lines := strings.Split(strings.TrimSpace(run.Output), "\n")
+		if len(lines) > 3 {
+			lines = lines[len(lines)-3:]
+		}
+		for _, line := range lines {
+			if line != "" {
+				sb.WriteString("  | " + line + "\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// killAndRestartCmd returns a command that kills the selected port's process like killPortCmd
+// and marks the result for a relaunch.
+func (m Model) killAndRestartCmd() tea.Cmd {
+	kill := m.killPortCmd()
+	if kill == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		msg := kill().(PortKilledMsg)
+		msg.Restart = true
+		return msg
+	}
+}
+
+// escalateKillCmd returns a command that retries the pending permission-denied kill through
+// the escalation command. It sends a PortKilledMsg marked Escalated when complete.
+func (m Model) escalateKillCmd() tea.Cmd {
+	if m.Escalation == nil || m.Killer == nil {
+		return nil
+	}
+
+	escalation := *m.Escalation
+
+	return func() tea.Msg {
+		// The retry is a new kill attempt, so it gets its own pre-kill and post-kill hooks
+		runs, err := m.runHooks(hooks.PreKill, escalation.Port, nil)
 		if err != nil {
+			return PortKilledMsg{Port: escalation.Port, Message: err.Error(), Vetoed: true, Escalated: true, Hooks: runs, Options: escalation.Options}
+		}
+
+		start := time.Now()
+		report, err := m.Killer.KillEscalated(escalation.Port, escalation.Options)
+		if err != nil {
+			return m.withPostKillHooks(PortKilledMsg{
+				Port:      escalation.Port,
+				Success:   false,
+				Message:   err.Error(),
+				Stale:     errors.Is(err, ErrStaleTarget),
+				Escalated: true,
+				Options:   escalation.Options,
+				Hooks:     runs,
+				Duration:  time.Since(start),
+			})
+		}
+
+		return m.withPostKillHooks(PortKilledMsg{
+			Port:      escalation.Port,
+			Success:   true,
+			Message:   "Process killed successfully",
+			Report:    report,
+			Escalated: true,
+			Options:   escalation.Options,
+			Restart:   escalation.Restart,
+			Hooks:     runs,
+			Duration:  time.Since(start),
+		})
+	}
+}
+
+// stopManagedCmd returns a command that stops the selected port's process through its manager.
+// The command runs asynchronously and sends a PortKilledMsg when complete.
+func (m Model) stopManagedCmd() tea.Cmd {
+	if !m.isValidSelection() {
+		return nil
+	}
+
+	port := m.FilteredPorts[m.SelectedIndex]
+
+	return func() tea.Msg {
+		start := time.Now()
+		if err := m.Killer.StopManaged(port); err != nil {
 			return PortKilledMsg{
-				Port:    port,
-				Success: false,
-				Message: err.Error(),
+				Port:       port,
+				Success:    false,
+				Message:    err.Error(),
+				ViaManager: true,
+				Duration:   time.Since(start),
 			}
 		}
 
 		return PortKilledMsg{
-			Port:    port,
-			Success: true,
-			Message: "Process killed successfully",
+			Port:       port,
+			Success:    true,
+			Message:    "Process stopped via " + port.Manager,
+			ViaManager: true,
+			Duration:   time.Since(start),
 		}
 	}
 }
-
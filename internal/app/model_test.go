@@ -1,11 +1,19 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/manson/port-chaser/internal/hooks"
 	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/policy"
 )
 
 type MockScanner struct {
@@ -23,12 +31,220 @@ func (m *MockScanner) Scan() ([]models.PortInfo, error) {
 type MockKiller struct {
 	Success bool
 	Message string
+	Err     error
+	// Escalate is the escalation command returned by EscalateCommand (nil disables escalation)
+	Escalate    []string
+	EscalateErr error
+	// SuspendErr is returned by Suspend; SuspendConfirmed records its confirmed argument,
+	// and Resumed the PIDs passed to Resume
+	SuspendErr       error
+	SuspendConfirmed []bool
+	Resumed          []int
+	// Launch is reported as the captured launch context of killed processes
+	Launch *models.LaunchContext
+	// Killed records the options of every kill, and KilledPIDs their targets
+	Killed     []KillOptions
+	KilledPIDs []int
+	// FailPIDs fails the kills of these PIDs with their error
+	FailPIDs map[int]error
 }
 
-func (m *MockKiller) Kill(port models.PortInfo) error {
+func (m *MockKiller) Kill(port models.PortInfo, opts KillOptions) (*KillReport, error) {
+	m.Killed = append(m.Killed, opts)
+	m.KilledPIDs = append(m.KilledPIDs, port.PID)
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	if err := m.FailPIDs[port.PID]; err != nil {
+		return nil, err
+	}
+	return &KillReport{Method: opts.Signal, Launch: m.Launch}, nil
+}
+
+func (m *MockKiller) DescribeKill(port models.PortInfo, opts KillOptions) string {
+	if opts.Signal != "" {
+		return opts.Signal + " 3s → SIGKILL"
+	}
+	return "SIGTERM 3s → SIGKILL"
+}
+
+func (m *MockKiller) Members(port models.PortInfo, scope string) ([]models.ProcessMember, error) {
+	return []models.ProcessMember{
+		{PID: port.PID, PGID: port.PID, Name: port.ProcessName, Command: port.Command},
+		{PID: port.PID + 1, PGID: port.PID, Name: "esbuild", Command: "esbuild --watch"},
+	}, nil
+}
+
+func (m *MockKiller) StopManaged(port models.PortInfo) error {
+	return nil
+}
+
+func (m *MockKiller) EscalateCommand(port models.PortInfo, opts KillOptions) []string {
+	return m.Escalate
+}
+
+func (m *MockKiller) Suspend(port models.PortInfo, confirmed bool) error {
+	m.SuspendConfirmed = append(m.SuspendConfirmed, confirmed)
+	return m.SuspendErr
+}
+
+func (m *MockKiller) Resume(port models.PortInfo) error {
+	m.Resumed = append(m.Resumed, port.PID)
+	return nil
+}
+
+func (m *MockKiller) KillEscalated(port models.PortInfo, opts KillOptions) (*KillReport, error) {
+	if m.EscalateErr != nil {
+		return nil, m.EscalateErr
+	}
+	return &KillReport{Method: "SIGTERM"}, nil
+}
+
+type MockLauncher struct {
+	Result   *models.RestartResult
+	Err      error
+	Launched []models.LaunchContext
+}
+
+func (m *MockLauncher) Restart(launch models.LaunchContext, port int) (*models.RestartResult, error) {
+	m.Launched = append(m.Launched, launch)
+	return m.Result, m.Err
+}
+
+// MockHooks records hook events; Veto makes pre-kill hooks cancel the kill.
+type MockHooks struct {
+	Veto    error
+	Events  []hooks.Event
+	Results []*hooks.Result
+}
+
+func (m *MockHooks) Run(ctx context.Context, event hooks.Event, port models.PortInfo, result *hooks.Result) ([]models.HookRun, error) {
+	m.Events = append(m.Events, event)
+	m.Results = append(m.Results, result)
+	run := models.HookRun{Hook: "test", Event: string(event), Output: "ran " + string(event)}
+	if event == hooks.PreKill && m.Veto != nil {
+		run.Error = "exit status 1"
+		return []models.HookRun{run}, m.Veto
+	}
+	return []models.HookRun{run}, nil
+}
+
+type MockStorage struct {
+	Entries []models.HistoryEntry
+	// Pruned counts Prune calls
+	Pruned int
+	// KillStats is returned by Stats, and StatsQueries records its queries
+	KillStats    models.KillStats
+	StatsQueries []models.StatsQuery
+	// PortLabels are the stored labels
+	PortLabels []models.PortLabel
+}
+
+func (m *MockStorage) RecordKill(entry models.HistoryEntry) error {
+	m.Entries = append([]models.HistoryEntry{entry}, m.Entries...)
+	return nil
+}
+
+// QueryHistory pages through the matching entries; the cursor is the index of the next one.
+func (m *MockStorage) QueryHistory(q models.HistoryQuery) (models.HistoryPage, error) {
+	var matching []models.HistoryEntry
+	for _, entry := range m.Entries {
+		if q.Matches(entry) {
+			matching = append(matching, entry)
+		}
+	}
+	start, _ := strconv.Atoi(q.Cursor)
+	if start > len(matching) {
+		start = len(matching)
+	}
+	limit := q.Limit
+	if limit == 0 {
+		limit = models.DefaultHistoryPageSize
+	}
+	page := models.HistoryPage{Entries: matching[start:]}
+	if len(page.Entries) > limit {
+		page.Entries = page.Entries[:limit]
+		page.NextCursor = strconv.Itoa(start + limit)
+	}
+	return page, nil
+}
+
+func (m *MockStorage) GetKillCount(port int, days int) (int, error) {
+	count := 0
+	for _, entry := range m.Entries {
+		if entry.PortNumber == port {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockStorage) Stats(q models.StatsQuery) (models.KillStats, error) {
+	m.StatsQueries = append(m.StatsQueries, q)
+	return m.KillStats, nil
+}
+
+func (m *MockStorage) Prune(maxAge time.Duration, maxRows int) (int, error) {
+	m.Pruned++
+	if maxRows <= 0 || maxRows >= len(m.Entries) {
+		return 0, nil
+	}
+	removed := len(m.Entries) - maxRows
+	m.Entries = m.Entries[:maxRows]
+	return removed, nil
+}
+
+func (m *MockStorage) Labels() ([]models.PortLabel, error) {
+	return m.PortLabels, nil
+}
+
+func (m *MockStorage) SaveLabel(label models.PortLabel) error {
+	m.DeleteLabel(label)
+	m.PortLabels = append(m.PortLabels, label)
+	return nil
+}
+
+func (m *MockStorage) DeleteLabel(label models.PortLabel) (bool, error) {
+	for i, l := range m.PortLabels {
+		if l.SameScope(label) {
+			m.PortLabels = append(m.PortLabels[:i], m.PortLabels[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockStorage) Close() error {
 	return nil
 }
 
+// MockTimeline records observed scans and returns Held for any port.
+type MockTimeline struct {
+	Observed [][]models.PortInfo
+	// Events are returned by Observe, and Err fails it
+	Events []models.PortEvent
+	Err    error
+	Held   []models.PortHolding
+	// Queries records the ports and since bounds Holdings was called with
+	Queries []models.PortEventQuery
+	Pruned  int
+}
+
+func (m *MockTimeline) Observe(ports []models.PortInfo, at time.Time) ([]models.PortEvent, error) {
+	m.Observed = append(m.Observed, ports)
+	return m.Events, m.Err
+}
+
+func (m *MockTimeline) Holdings(port int, since, until time.Time) ([]models.PortHolding, error) {
+	m.Queries = append(m.Queries, models.PortEventQuery{Port: port, Since: since, Until: until})
+	return m.Held, nil
+}
+
+func (m *MockTimeline) Prune(maxAge time.Duration) (int, error) {
+	m.Pruned++
+	return 0, nil
+}
+
 func TestModel_Init(t *testing.T) {
 	model := Model{
 		Scanner: &MockScanner{
@@ -258,8 +474,1341 @@ func TestModel_WindowSizeMsg(t *testing.T) {
 	}
 }
 
+func TestModel_handlePortKilled_Respawn(t *testing.T) {
+	sto := &MockStorage{}
+	model := Model{Storage: sto, Scanner: &MockScanner{}}
+
+	port := models.PortInfo{PortNumber: 3000, ProcessName: "node", PID: 1001, Manager: "pm2"}
+	msg := PortKilledMsg{
+		Port:    port,
+		Success: true,
+		Report: &KillReport{
+			Respawn: &models.RespawnInfo{PID: 1050, ProcessName: "node", Supervisor: "pm2"},
+		},
+	}
+
+	newModel, _ := model.Update(msg)
+	newModelTyped := newModel.(Model)
+
+	if len(sto.Entries) != 1 {
+		t.Fatalf("recorded entries = %d, want 1", len(sto.Entries))
+	}
+	entry := sto.Entries[0]
+	if entry.Outcome != models.OutcomeRespawned {
+		t.Errorf("Outcome = %q, want %q", entry.Outcome, models.OutcomeRespawned)
+	}
+	if entry.RespawnPID != 1050 || entry.Supervisor != "pm2" {
+		t.Errorf("respawn details = (%d, %q), want (1050, pm2)", entry.RespawnPID, entry.Supervisor)
+	}
+	if entry.Manager != "pm2" {
+		t.Errorf("Manager = %q, want pm2", entry.Manager)
+	}
+	if len(newModelTyped.History) != 1 {
+		t.Errorf("in-memory history = %d, want 1", len(newModelTyped.History))
+	}
+
+	got := respawnMessage(port, msg.Report.Respawn)
+	want := "Killed node, but port 3000 respawned as PID 1050 (node) - likely restarted by pm2"
+	if got != want {
+		t.Errorf("respawnMessage() = %q, want %q", got, want)
+	}
+}
+
+func TestModel_handlePortKilled_Outcome(t *testing.T) {
+	tests := []struct {
+		name       string
+		viaManager bool
+		want       string
+	}{
+		{"plain kill", false, models.OutcomeKilled},
+		{"manager stop", true, models.OutcomeStopped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sto := &MockStorage{}
+			model := Model{Storage: sto, Scanner: &MockScanner{}}
+
+			model.Update(PortKilledMsg{
+				Port:       models.PortInfo{PortNumber: 8080, ProcessName: "python", PID: 2002},
+				Success:    true,
+				ViaManager: tt.viaManager,
+				Report:     &KillReport{},
+			})
+
+			if len(sto.Entries) != 1 || sto.Entries[0].Outcome != tt.want {
+				t.Errorf("entries = %+v, want one with Outcome %q", sto.Entries, tt.want)
+			}
+		})
+	}
+}
+
+func TestModel_ConfirmSignalCycle(t *testing.T) {
+	port := models.PortInfo{PortNumber: 5432, ProcessName: "postgres", PID: 3003}
+	model := Model{
+		FilteredPorts: []models.PortInfo{port},
+		SelectedIndex: 0,
+		ViewMode:      ViewModeConfirmKill,
+		Killer:        &MockKiller{},
+	}
+
+	key := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")}
+	for _, want := range []string{"SIGTERM", "SIGINT"} {
+		newModel, _ := model.handleConfirmKeyMsg(key)
+		model = newModel.(Model)
+		if model.KillSignal != want {
+			t.Fatalf("KillSignal = %q, want %q", model.KillSignal, want)
+		}
+	}
+
+	if view := model.renderConfirmKillView(); !strings.Contains(view, "Signal: SIGINT (SIGINT 3s → SIGKILL)") {
+		t.Errorf("confirm view missing chosen signal:\n%s", view)
+	}
+
+	msg := model.killPortCmd()().(PortKilledMsg)
+	if msg.Report == nil || msg.Report.Method != "SIGINT" {
+		t.Errorf("Kill() report = %+v, want Method SIGINT", msg.Report)
+	}
+	if got := killedMessage(port, msg.Report); got != "Killed postgres (SIGINT)" {
+		t.Errorf("killedMessage() = %q", got)
+	}
+
+	if got := nextKillSignal("SIGKILL"); got != "" {
+		t.Errorf("nextKillSignal(SIGKILL) = %q, want strategy default", got)
+	}
+}
+
+func TestModel_ConfirmScopeCycle(t *testing.T) {
+	port := models.PortInfo{PortNumber: 5173, ProcessName: "node", PID: 4004, Command: "node vite"}
+	model := Model{
+		FilteredPorts: []models.PortInfo{port},
+		SelectedIndex: 0,
+		ViewMode:      ViewModeConfirmKill,
+		Killer:        &MockKiller{},
+	}
+
+	key := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")}
+	newModel, cmd := model.handleConfirmKeyMsg(key)
+	model = newModel.(Model)
+	if model.KillScope != "group" || cmd == nil {
+		t.Fatalf("KillScope = %q (cmd %v), want group with a member lookup", model.KillScope, cmd != nil)
+	}
+	if view := model.renderConfirmKillView(); !strings.Contains(view, "Listing members...") {
+		t.Errorf("confirm view should show the pending member lookup:\n%s", view)
+	}
+
+	// A late result for a different scope is ignored
+	newModel, _ = model.Update(MembersLoadedMsg{Scope: "session", Members: []models.ProcessMember{{PID: 1}}})
+	model = newModel.(Model)
+	if model.KillMembers != nil {
+		t.Fatalf("KillMembers = %+v, want stale result ignored", model.KillMembers)
+	}
+
+	newModel, _ = model.Update(cmd())
+	model = newModel.(Model)
+	if len(model.KillMembers) != 2 {
+		t.Fatalf("KillMembers = %d, want 2", len(model.KillMembers))
+	}
+	view := model.renderConfirmKillView()
+	if !strings.Contains(view, "process group (2 processes will be signalled)") || !strings.Contains(view, "esbuild") {
+		t.Errorf("confirm view missing member list:\n%s", view)
+	}
+	if opts := model.killOptions(); opts.Scope != "group" {
+		t.Errorf("killOptions().Scope = %q, want group", opts.Scope)
+	}
+
+	report := &KillReport{Method: "SIGTERM", Members: model.KillMembers}
+	if got := killedMessage(port, report); got != "Killed node (SIGTERM) and 1 other process" {
+		t.Errorf("killedMessage() = %q", got)
+	}
+
+	// Cycling back to the process clears the member list; Esc resets the scope
+	model.KillScope = "session"
+	newModel, cmd = model.handleConfirmKeyMsg(key)
+	model = newModel.(Model)
+	if model.KillScope != "" || model.KillMembers != nil || cmd != nil {
+		t.Errorf("after wrap: scope %q members %v", model.KillScope, model.KillMembers)
+	}
+}
+
+func TestModel_handlePortKilled_PortHeld(t *testing.T) {
+	sto := &MockStorage{}
+	model := Model{Storage: sto, Scanner: &MockScanner{}}
+
+	port := models.PortInfo{PortNumber: 3000, ProcessName: "npm", PID: 1001}
+	msg := PortKilledMsg{
+		Port:    port,
+		Success: true,
+		Report: &KillReport{
+			Method:  "SIGTERM",
+			Release: &models.PortRelease{Released: false, HolderPID: 1002, HolderName: "node"},
+		},
+	}
+
+	model.Update(msg)
+
+	if len(sto.Entries) != 1 || sto.Entries[0].Outcome != models.OutcomePortHeld {
+		t.Errorf("entries = %+v, want one with Outcome %q", sto.Entries, models.OutcomePortHeld)
+	}
+
+	got := portHeldMessage(port, msg.portHeld())
+	want := "Killed npm, but port 3000 is still in use by PID 1002 (node)"
+	if got != want {
+		t.Errorf("portHeldMessage() = %q, want %q", got, want)
+	}
+
+	msg.Report.Release = &models.PortRelease{Released: true}
+	if msg.portHeld() != nil {
+		t.Error("portHeld() should be nil once the port is released")
+	}
+}
+
+func TestModel_StaleTarget(t *testing.T) {
+	port := models.PortInfo{PortNumber: 3000, ProcessName: "node", PID: 5005}
+	model := Model{
+		FilteredPorts: []models.PortInfo{port},
+		SelectedIndex: 0,
+		Scanner:       &MockScanner{},
+		Killer:        &MockKiller{Err: fmt.Errorf("%w: PID 5005 now runs /usr/bin/python3", ErrStaleTarget)},
+	}
+
+	msg := model.killPortCmd()().(PortKilledMsg)
+	if msg.Success || !msg.Stale {
+		t.Fatalf("PortKilledMsg = %+v, want a stale failure", msg)
+	}
+
+	newModel, cmd := model.Update(msg)
+	if !newModel.(Model).Loading {
+		t.Error("a stale target should trigger a rescan")
+	}
+	if cmd == nil {
+		t.Fatal("Update() should return status and rescan commands")
+	}
+
+	want := "Not killed: node (PID 5005) exited after the last scan and the PID now belongs to another process - list refreshed"
+	if got := staleMessage(port); got != want {
+		t.Errorf("staleMessage() = %q, want %q", got, want)
+	}
+}
+
+func TestModel_PermissionDeniedEscalation(t *testing.T) {
+	port := models.PortInfo{PortNumber: 80, ProcessName: "nginx", PID: 4242}
+	storage := &MockStorage{}
+	model := Model{
+		FilteredPorts: []models.PortInfo{port},
+		SelectedIndex: 0,
+		Scanner:       &MockScanner{},
+		Storage:       storage,
+		Killer: &MockKiller{
+			Err:      fmt.Errorf("%w: SIGTERM send failed: operation not permitted", ErrPermissionDenied),
+			Escalate: []string{"sudo", "-n", "/usr/local/bin/port-chaser", "kill", "-pid", "4242", "80"},
+		},
+	}
+
+	msg := model.killPortCmd()().(PortKilledMsg)
+	if msg.Success || !msg.PermissionDenied {
+		t.Fatalf("PortKilledMsg = %+v, want a permission-denied failure", msg)
+	}
+
+	newModel, _ := model.Update(msg)
+	m := newModel.(Model)
+	if m.ViewMode != ViewModeConfirmEscalate || m.Escalation == nil {
+		t.Fatalf("ViewMode = %v, want the escalation dialog", m.ViewMode)
+	}
+	if view := m.View(); !strings.Contains(view, "sudo -n /usr/local/bin/port-chaser kill -pid 4242 80") {
+		t.Errorf("escalation dialog should show the exact command:\n%s", view)
+	}
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	m = newModel.(Model)
+	if cmd == nil || m.Escalation != nil || m.ViewMode != ViewModeMain {
+		t.Fatal("'y' should start the escalated kill and close the dialog")
+	}
+
+	escalated := cmd().(PortKilledMsg)
+	if !escalated.Success || !escalated.Escalated {
+		t.Fatalf("escalated PortKilledMsg = %+v, want an escalated success", escalated)
+	}
+	m.Update(escalated)
+	// The denied attempt is recorded as a failure, then the escalated kill
+	if len(storage.Entries) != 2 || !storage.Entries[0].Escalated || !storage.Entries[1].Failed {
+		t.Errorf("history = %+v, want the failed attempt and one escalated entry", storage.Entries)
+	}
+}
+
+func TestModel_PermissionDeniedWithoutEscalation(t *testing.T) {
+	port := models.PortInfo{PortNumber: 80, ProcessName: "nginx", PID: 4242}
+	model := Model{
+		FilteredPorts: []models.PortInfo{port},
+		SelectedIndex: 0,
+		Scanner:       &MockScanner{},
+		Killer:        &MockKiller{Err: fmt.Errorf("%w: operation not permitted", ErrPermissionDenied)},
+	}
+
+	newModel, _ := model.Update(model.killPortCmd()())
+	if m := newModel.(Model); m.ViewMode != ViewModeMain || m.Escalation != nil {
+		t.Errorf("without an escalation command the kill should just fail, got ViewMode %v", m.ViewMode)
+	}
+
+	model.Killer = &MockKiller{
+		Err:         fmt.Errorf("%w: operation not permitted", ErrPermissionDenied),
+		Escalate:    []string{"sudo", "-n", "port-chaser", "kill", "80"},
+		EscalateErr: fmt.Errorf("escalated kill failed: sudo: a password is required"),
+	}
+	model.Escalation = &Escalation{Port: port, Command: []string{"sudo", "-n"}}
+	msg := model.escalateKillCmd()().(PortKilledMsg)
+	if msg.Success || !msg.Escalated {
+		t.Fatalf("PortKilledMsg = %+v, want an escalated failure", msg)
+	}
+	newModel, _ = model.Update(msg)
+	if m := newModel.(Model); m.ViewMode != ViewModeMain {
+		t.Errorf("a failed escalation must not offer another escalation, got ViewMode %v", m.ViewMode)
+	}
+}
+
+func TestModel_SuspendResume(t *testing.T) {
+	port := models.PortInfo{PortNumber: 3000, ProcessName: "node", PID: 1234}
+	killer := &MockKiller{}
+	model := Model{
+		FilteredPorts: []models.PortInfo{port},
+		SelectedIndex: 0,
+		Scanner:       &MockScanner{},
+		Killer:        killer,
+	}
+
+	newModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	if cmd == nil {
+		t.Fatal("'p' should return a suspend command")
+	}
+	msg := cmd().(SuspendedMsg)
+	if !msg.Suspended || msg.Error != nil {
+		t.Fatalf("SuspendedMsg = %+v, want a successful suspend", msg)
+	}
+	newModel, _ = newModel.Update(msg)
+	m := newModel.(Model)
+	if _, ok := m.Suspended[1234]; !ok {
+		t.Fatal("PID 1234 should be tracked as suspended")
+	}
+	if _, ok := model.Suspended[1234]; ok {
+		t.Error("suspending must not mutate the previous model's map")
+	}
+	if view := m.View(); !strings.Contains(view, "[PAUSED]") {
+		t.Errorf("main view should mark the suspended process:\n%s", view)
+	}
+
+	// 'p' again resumes
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	msg = cmd().(SuspendedMsg)
+	if msg.Suspended {
+		t.Fatal("'p' on a suspended process should resume it")
+	}
+	newModel, _ = m.Update(msg)
+	if len(newModel.(Model).Suspended) != 0 {
+		t.Error("resumed process should no longer be tracked")
+	}
+
+	// Failures are reported and leave the state unchanged
+	newModel, _ = model.Update(SuspendedMsg{Port: port, Suspended: true, Error: assertError("operation not permitted")})
+	m = newModel.(Model)
+	if len(m.Suspended) != 0 || !strings.Contains(m.StatusMessage, "Suspend failed") {
+		t.Errorf("failed suspend: Suspended = %v, status = %q", m.Suspended, m.StatusMessage)
+	}
+}
+
+func TestModel_SuspendedQuitWarning(t *testing.T) {
+	port := models.PortInfo{PortNumber: 3000, ProcessName: "node", PID: 1234}
+	killer := &MockKiller{}
+	model := Model{
+		FilteredPorts: []models.PortInfo{port},
+		SelectedIndex: 0,
+		Scanner:       &MockScanner{},
+		Killer:        killer,
+		Suspended:     map[int]models.PortInfo{1234: port},
+	}
+
+	newModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	m := newModel.(Model)
+	if m.ViewMode != ViewModeConfirmQuit || m.Quit || cmd != nil {
+		t.Fatalf("'q' with suspended processes should warn, got ViewMode %v, Quit %v", m.ViewMode, m.Quit)
+	}
+	if view := m.View(); !strings.Contains(view, "Port 3000 - node (PID: 1234)") {
+		t.Errorf("quit warning should list the suspended process:\n%s", view)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if newModel.(Model).ViewMode != ViewModeMain {
+		t.Error("Esc should cancel the quit")
+	}
+
+	newModel, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if !newModel.(Model).Quit || cmd == nil {
+		t.Fatal("'r' should resume all and quit")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("resume-all command should end with a quit")
+	}
+	if len(killer.Resumed) != 1 || killer.Resumed[0] != 1234 {
+		t.Errorf("Resumed = %v, want [1234]", killer.Resumed)
+	}
+}
+
+func TestModel_SuspendedTracking(t *testing.T) {
+	port := models.PortInfo{PortNumber: 3000, ProcessName: "node", PID: 1234}
+	killer := &MockKiller{}
+	model := Model{
+		FilteredPorts: []models.PortInfo{port},
+		SelectedIndex: 0,
+		Scanner:       &MockScanner{},
+		Killer:        killer,
+		Suspended:     map[int]models.PortInfo{1234: port},
+	}
+
+	// Killing a suspended process continues it first so it can handle the graceful signal
+	msg := model.killPortCmd()().(PortKilledMsg)
+	if len(killer.Resumed) != 1 {
+		t.Errorf("kill of a suspended process should resume it first, Resumed = %v", killer.Resumed)
+	}
+	newModel, _ := model.Update(msg)
+	if len(newModel.(Model).Suspended) != 0 {
+		t.Error("a killed process should no longer be tracked as suspended")
+	}
+
+	// A suspended process that vanished from the scan is forgotten
+	newModel, _ = model.Update(PortsScannedMsg{Ports: []models.PortInfo{{PortNumber: 8080, PID: 99}}})
+	if len(newModel.(Model).Suspended) != 0 {
+		t.Error("suspended PIDs missing from the scan should be pruned")
+	}
+}
+
+func TestModel_KillAndRestart(t *testing.T) {
+	port := models.PortInfo{PortNumber: 3000, ProcessName: "node", PID: 1234}
+	launch := &models.LaunchContext{Args: []string{"node", "server.js"}, Cwd: "/home/dev/app", Env: []string{"NODE_ENV=development"}}
+	launcher := &MockLauncher{Result: &models.RestartResult{PID: 5678, Bound: true, ListenerPID: 5678}}
+	storage := &MockStorage{}
+	model := Model{
+		FilteredPorts: []models.PortInfo{port},
+		SelectedIndex: 0,
+		ViewMode:      ViewModeConfirmKill,
+		Scanner:       &MockScanner{},
+		Killer:        &MockKiller{Launch: launch},
+		Launcher:      launcher,
+		Storage:       storage,
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+	if cmd == nil {
+		t.Fatal("'R' in the kill dialog should return a kill command")
+	}
+	killed := cmd().(PortKilledMsg)
+	if !killed.Restart {
+		t.Fatal("kill-and-restart should mark the PortKilledMsg for restart")
+	}
+
+	newModel, _ := model.Update(killed)
+	if len(storage.Entries) != 1 || !reflect.DeepEqual(storage.Entries[0].Launch, launch) {
+		t.Errorf("history should record the launch context, got %+v", storage.Entries)
+	}
+
+	restarted := newModel.(Model).restartCmd(*launch, 3000, "node")().(RestartedMsg)
+	if len(launcher.Launched) != 1 || launcher.Launched[0].Cwd != "/home/dev/app" {
+		t.Errorf("Launched = %+v, want the captured context", launcher.Launched)
+	}
+	newModel, _ = newModel.Update(restarted)
+	if got := newModel.(Model).StatusMessage; got != "Restarted node as PID 5678, listening on port 3000" {
+		t.Errorf("StatusMessage = %q", got)
+	}
+
+	unbound := RestartedMsg{Port: 3000, ProcessName: "node", Result: &models.RestartResult{PID: 5678, LogPath: "/tmp/x.log"}}
+	if got := restartMessage(unbound); !strings.Contains(got, "not listening yet (output: /tmp/x.log)") {
+		t.Errorf("restartMessage(unbound) = %q", got)
+	}
+}
+
+func TestModel_HistoryRestart(t *testing.T) {
+	launch := &models.LaunchContext{Args: []string{"python", "-m", "http.server"}, Cwd: "/srv"}
+	launcher := &MockLauncher{Result: &models.RestartResult{PID: 99, Bound: true}}
+	model := Model{
+		ViewMode: ViewModeHistory,
+		Scanner:  &MockScanner{},
+		Launcher: launcher,
+		History: []models.HistoryEntry{
+			{PortNumber: 3000, ProcessName: "node", PID: 1},
+			{PortNumber: 8000, ProcessName: "python", PID: 2, Launch: launch},
+			{PortNumber: 9000, ProcessName: "api", PID: 3, Launch: &models.LaunchContext{
+				Args: []string{"api", "--token=" + models.RedactionMask}, Cwd: "/srv/api"}},
+		},
+	}
+
+	// The first entry has no launch context
+	newModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+	if cmd != nil || !strings.Contains(newModel.(Model).StatusMessage, "no launch context") {
+		t.Errorf("restart without a launch context should explain why, got %q", newModel.(Model).StatusMessage)
+	}
+
+	newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m := newModel.(Model)
+	if m.HistoryIndex != 1 {
+		t.Fatalf("HistoryIndex = %d, want 1", m.HistoryIndex)
+	}
+	if view := m.View(); !strings.Contains(view, "> 2. Port 8000 - python") || !strings.Contains(view, "Cwd: /srv") {
+		t.Errorf("history view should mark the selection and show the cwd:\n%s", view)
+	}
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+	if cmd == nil {
+		t.Fatal("'R' should restart the selected entry")
+	}
+	msg := cmd().(RestartedMsg)
+	if msg.Port != 8000 || msg.Result.PID != 99 || len(launcher.Launched) != 1 {
+		t.Errorf("RestartedMsg = %+v, Launched = %+v", msg, launcher.Launched)
+	}
+
+	// A redacted launch context would restart the process with the mask as its token
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	newModel, cmd = newModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+	if cmd != nil || !strings.Contains(newModel.(Model).StatusMessage, "redacted") {
+		t.Errorf("restart of a redacted launch should explain why, got %q", newModel.(Model).StatusMessage)
+	}
+}
+
 type assertError string
 
 func (e assertError) Error() string {
 	return string(e)
 }
+
+func TestModel_Policy(t *testing.T) {
+	rules := policy.New(
+		policy.Rule{Name: "ssh", Action: policy.ActionBlock, ProcessNames: []string{"sshd"}},
+		policy.Rule{Name: "db", Action: policy.ActionConfirm, ProcessNames: []string{"postgres"}},
+		policy.Rule{Name: "root", Action: policy.ActionWarn, Users: []string{"root"}},
+	)
+	ports := []models.PortInfo{
+		{PortNumber: 22, ProcessName: "sshd", PID: 900},
+		{PortNumber: 5432, ProcessName: "postgres", PID: 7},
+		{PortNumber: 80, ProcessName: "nginx", PID: 1, User: "root"},
+		{PortNumber: 3000, ProcessName: "node", PID: 1, IsSystem: true},
+	}
+	killer := &MockKiller{}
+	model := Model{
+		Ports:         ports,
+		FilteredPorts: ports,
+		Scanner:       &MockScanner{},
+		Killer:        killer,
+		Policy:        rules,
+	}
+
+	view := model.renderMainView()
+	for _, marker := range []string{"[Protected: ssh]", "[Confirm: db]", "[Warning: root]"} {
+		if !strings.Contains(view, marker) {
+			t.Errorf("main view missing %s", marker)
+		}
+	}
+	if strings.Contains(view, "[System Process]") {
+		t.Error("the policy should replace the IsSystem marker")
+	}
+
+	typeKeys := func(m tea.Model, keys string) tea.Model {
+		for _, r := range keys {
+			m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+		return m
+	}
+
+	t.Run("block refuses the kill", func(t *testing.T) {
+		m := model
+		m.SelectedIndex = 0
+		m.ViewMode = ViewModeConfirmKill
+		newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+		if cmd != nil {
+			t.Fatal("a blocked port should not be killed")
+		}
+		if got := newModel.(Model).StatusMessage; !strings.Contains(got, `policy rule "ssh"`) {
+			t.Errorf("StatusMessage = %q, want the blocking rule", got)
+		}
+	})
+
+	t.Run("confirm requires the process name", func(t *testing.T) {
+		m := model
+		m.SelectedIndex = 1
+		m.ViewMode = ViewModeConfirmKill
+		newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+		if cmd != nil || !newModel.(Model).ConfirmTyping {
+			t.Fatal("'y' on a confirm rule should ask for the process name")
+		}
+
+		newModel = typeKeys(newModel, "postgrex")
+		newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		newModel = typeKeys(newModel, "z")
+		newModel, cmd = newModel.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		if cmd != nil || newModel.(Model).ConfirmInput != "" {
+			t.Fatal("a wrong name should clear the input without killing")
+		}
+
+		newModel = typeKeys(newModel, "postgres")
+		if view := newModel.(Model).renderConfirmKillView(); !strings.Contains(view, `Type "postgres" to confirm: postgres_`) {
+			t.Errorf("confirm view should echo the input, got:\n%s", view)
+		}
+		newModel, cmd = newModel.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		if cmd == nil {
+			t.Fatal("the matching name should kill")
+		}
+		cmd()
+		if len(killer.Killed) != 1 || !killer.Killed[0].Confirmed {
+			t.Errorf("Killed = %+v, want one confirmed kill", killer.Killed)
+		}
+
+		newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		if newModel.(Model).ViewMode != ViewModeMain || newModel.(Model).KillConfirmed {
+			t.Error("cancelling should reset the confirmation")
+		}
+	})
+
+	t.Run("block refuses a suspend", func(t *testing.T) {
+		m := model
+		m.SelectedIndex = 0
+		newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+		if cmd != nil {
+			t.Fatal("a blocked port should not be suspended")
+		}
+		if got := newModel.(Model).StatusMessage; !strings.Contains(got, "Suspend refused") {
+			t.Errorf("StatusMessage = %q, want the suspend refused", got)
+		}
+	})
+
+	t.Run("confirm asks for the process name before a suspend", func(t *testing.T) {
+		suspender := &MockKiller{}
+		m := model
+		m.Killer = suspender
+		m.SelectedIndex = 1
+		newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+		if cmd != nil || !newModel.(Model).ConfirmTyping || newModel.(Model).ViewMode != ViewModeConfirmKill {
+			t.Fatal("'p' on a confirm rule should ask for the process name")
+		}
+		if view := newModel.(Model).View(); !strings.Contains(view, "Confirm Suspend Process") || !strings.Contains(view, "Press Enter to suspend") {
+			t.Errorf("the dialog should be about a suspend, got:\n%s", view)
+		}
+
+		// Esc goes straight back to the list
+		if cancelled, _ := newModel.Update(tea.KeyMsg{Type: tea.KeyEsc}); cancelled.(Model).ViewMode != ViewModeMain || cancelled.(Model).ConfirmSuspend {
+			t.Error("Esc should cancel the suspend")
+		}
+
+		newModel = typeKeys(newModel, "postgres")
+		newModel, cmd = newModel.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		if cmd == nil {
+			t.Fatal("the matching name should suspend")
+		}
+		if msg := cmd().(SuspendedMsg); !msg.Suspended || msg.Error != nil {
+			t.Errorf("SuspendedMsg = %+v, want a successful suspend", msg)
+		}
+		if len(suspender.SuspendConfirmed) != 1 || !suspender.SuspendConfirmed[0] {
+			t.Errorf("Suspend confirmed = %v, want one confirmed suspend", suspender.SuspendConfirmed)
+		}
+		if m := newModel.(Model); m.ViewMode != ViewModeMain || m.KillConfirmed || len(suspender.Killed) != 0 {
+			t.Errorf("after the suspend: ViewMode %v, KillConfirmed %v, Killed %v", m.ViewMode, m.KillConfirmed, suspender.Killed)
+		}
+	})
+
+	t.Run("warn kills directly", func(t *testing.T) {
+		m := model
+		m.SelectedIndex = 2
+		m.ViewMode = ViewModeConfirmKill
+		if view := m.renderConfirmKillView(); !strings.Contains(view, `Warning: policy rule "root"`) {
+			t.Errorf("confirm view should show the warning, got:\n%s", view)
+		}
+		if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}}); cmd == nil {
+			t.Error("a warn rule should not prevent the kill")
+		}
+	})
+
+	t.Run("no policy keeps the system marker", func(t *testing.T) {
+		m := model
+		m.Policy = nil
+		if !strings.Contains(m.renderMainView(), "[System Process]") {
+			t.Error("without a policy IsSystem ports should be marked")
+		}
+	})
+}
+
+func TestModel_KillHooks(t *testing.T) {
+	port := models.PortInfo{PortNumber: 8080, ProcessName: "api", PID: 4242}
+	newModel := func(h *MockHooks, killer *MockKiller) Model {
+		return Model{
+			FilteredPorts: []models.PortInfo{port},
+			SelectedIndex: 0,
+			ViewMode:      ViewModeConfirmKill,
+			Scanner:       &MockScanner{},
+			Killer:        killer,
+			Hooks:         h,
+		}
+	}
+
+	t.Run("pre and post hooks run around the kill", func(t *testing.T) {
+		h := &MockHooks{}
+		model := newModel(h, &MockKiller{})
+		msg := model.killPortCmd()().(PortKilledMsg)
+		if !msg.Success || !reflect.DeepEqual(h.Events, []hooks.Event{hooks.PreKill, hooks.PostKill}) {
+			t.Fatalf("Success = %v, events = %v; want a kill between pre and post hooks", msg.Success, h.Events)
+		}
+		if post := h.Results[1]; post == nil || !post.Success || post.Report == nil {
+			t.Errorf("post-kill result = %+v, want the successful kill report", post)
+		}
+		if len(msg.Hooks) != 2 {
+			t.Errorf("Hooks = %+v, want both runs", msg.Hooks)
+		}
+
+		updated, _ := model.Update(msg)
+		view := updated.(Model).renderMainView()
+		if !strings.Contains(view, "Hook test (pre_kill): ok") || !strings.Contains(view, "| ran post_kill") {
+			t.Errorf("main view should show the hook output, got:\n%s", view)
+		}
+	})
+
+	t.Run("failed kill still runs post hooks", func(t *testing.T) {
+		h := &MockHooks{}
+		msg := newModel(h, &MockKiller{Err: fmt.Errorf("no such process")}).killPortCmd()().(PortKilledMsg)
+		if msg.Success || len(h.Results) != 2 || h.Results[1].Success || h.Results[1].Message != "no such process" {
+			t.Errorf("post-kill result = %+v, want the failure", h.Results)
+		}
+	})
+
+	t.Run("a failing pre hook vetoes the kill", func(t *testing.T) {
+		h := &MockHooks{Veto: fmt.Errorf("%w %q: queue not empty", hooks.ErrVetoed, "flush")}
+		killer := &MockKiller{}
+		model := newModel(h, killer)
+		msg := model.killPortCmd()().(PortKilledMsg)
+		if !msg.Vetoed || msg.Success || len(killer.Killed) != 0 {
+			t.Fatalf("msg = %+v, killed = %v; want a vetoed kill that never signals", msg, killer.Killed)
+		}
+		if !reflect.DeepEqual(h.Events, []hooks.Event{hooks.PreKill}) {
+			t.Errorf("events = %v, want no post-kill hooks after a veto", h.Events)
+		}
+
+		updated, cmd := model.Update(msg)
+		updated, _ = updated.Update(findStatusMsg(t, cmd))
+		if got := updated.(Model).StatusMessage; !strings.Contains(got, "Kill cancelled: vetoed by hook \"flush\"") {
+			t.Errorf("StatusMessage = %q", got)
+		}
+	})
+}
+
+// findStatusMsg runs a batched command and returns the status message it produces.
+func findStatusMsg(t *testing.T, cmd tea.Cmd) StatusMsg {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("expected a command")
+	}
+	msg := cmd()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		batch = tea.BatchMsg{func() tea.Msg { return msg }}
+	}
+	for _, c := range batch {
+		if c == nil {
+			continue
+		}
+		if status, ok := c().(StatusMsg); ok {
+			return status
+		}
+	}
+	t.Fatal("no StatusMsg in command")
+	return StatusMsg{}
+}
+
+func TestModel_KillAudit(t *testing.T) {
+	port := models.PortInfo{
+		PortNumber: 8080, ProcessName: "web", PID: 4242, User: "www", Protocol: "tcp", Address: "0.0.0.0",
+		IsDocker: true, ContainerID: "abc123", ContainerName: "web-1", ImageName: "nginx:1.25",
+	}
+	storage := &MockStorage{}
+	model := Model{
+		FilteredPorts: []models.PortInfo{port},
+		SelectedIndex: 0,
+		Scanner:       &MockScanner{},
+		Storage:       storage,
+		Operator:      "dev",
+	}
+
+	model.Killer = &MockKiller{}
+	msg := model.killPortCmd()().(PortKilledMsg)
+	msg.Report.Method = "SIGQUIT"
+	msg.Duration = 1200 * time.Millisecond
+	updated, _ := model.Update(msg)
+
+	model.Killer = &MockKiller{Err: fmt.Errorf("no such process")}
+	updated.(Model).Update(model.killPortCmd()())
+
+	if len(storage.Entries) != 2 {
+		t.Fatalf("history = %+v, want the kill and the failed attempt", storage.Entries)
+	}
+	failed, killed := storage.Entries[0], storage.Entries[1]
+	if killed.Failed || killed.Method != "SIGQUIT" || killed.Duration != 1200*time.Millisecond ||
+		killed.KilledBy != "dev" || killed.Owner != "www" || killed.Protocol != "tcp" || killed.Address != "0.0.0.0" ||
+		killed.ContainerID != "abc123" || killed.ContainerName != "web-1" || killed.ImageName != "nginx:1.25" {
+		t.Errorf("kill entry = %+v, want every audit field filled in", killed)
+	}
+	if !failed.Failed || failed.Outcome != models.OutcomeFailed || failed.Error != "no such process" {
+		t.Errorf("failed entry = %+v", failed)
+	}
+
+	view := updated.(Model)
+	view.History = storage.Entries
+	view.ViewMode = ViewModeHistory
+	out := view.View()
+	for _, want := range []string{"Failed: no such process", "Method: SIGQUIT in 1.2s", "Container: web-1 (nginx:1.25)", "by dev"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("history view missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestModel_HistoryRetention(t *testing.T) {
+	storage := &MockStorage{Entries: make([]models.HistoryEntry, 5)}
+	tl := &MockTimeline{}
+	model := Model{
+		Scanner:   &MockScanner{},
+		Storage:   storage,
+		Timeline:  tl,
+		Retention: Retention{MaxRows: 3, Interval: time.Hour},
+	}
+
+	msg, ok := model.pruneHistoryCmd()().(HistoryPrunedMsg)
+	if !ok || msg.Removed != 2 || msg.Error != nil {
+		t.Fatalf("pruneHistoryCmd() = %+v, want 2 entries removed", msg)
+	}
+	if len(storage.Entries) != 3 {
+		t.Errorf("storage has %d entries, want 3", len(storage.Entries))
+	}
+	if tl.Pruned != 1 {
+		t.Errorf("timeline pruned %d times, want once with the history", tl.Pruned)
+	}
+
+	// Removing entries reloads history and schedules the next run
+	_, cmd := model.Update(msg)
+	if batch, ok := cmd().(tea.BatchMsg); !ok || len(batch) != 2 {
+		t.Errorf("Update(HistoryPrunedMsg) = %#v, want a history reload and the next prune", cmd())
+	}
+
+	// Without an interval nothing is scheduled
+	model.Retention.Interval = 0
+	if _, cmd := model.Update(HistoryPrunedMsg{}); cmd != nil && cmd() != nil {
+		t.Errorf("Update(HistoryPrunedMsg) without an interval = %#v, want no command", cmd())
+	}
+
+	// Disabled retention never prunes
+	if (Retention{Interval: time.Hour}).enabled() {
+		t.Error("Retention without limits should be disabled")
+	}
+}
+
+func TestModel_HistoryPaging(t *testing.T) {
+	storage := &MockStorage{}
+	for i := 0; i < 60; i++ {
+		entry := models.HistoryEntry{PortNumber: 3000 + i%2, ProcessName: "node", PID: i + 1}
+		if i == 7 {
+			entry.ProcessName, entry.Failed = "vite", true
+		}
+		storage.Entries = append(storage.Entries, entry)
+	}
+	model := Model{Storage: storage, ViewMode: ViewModeHistory}
+
+	updated, _ := model.Update(model.loadHistoryCmd()())
+	model = updated.(Model)
+	if len(model.History) != models.DefaultHistoryPageSize || model.HistoryCursor == "" {
+		t.Fatalf("first page = %d entries, cursor %q; want a full page and a cursor", len(model.History), model.HistoryCursor)
+	}
+	if !strings.Contains(model.View(), "More entries below") {
+		t.Error("view should say more entries can be loaded")
+	}
+
+	// Moving past the last entry loads the next page
+	model.HistoryIndex = len(model.History) - 1
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model = updated.(Model)
+	if cmd == nil || !model.HistoryLoading {
+		t.Fatal("down at the last entry should load the next page")
+	}
+	if _, again := model.Update(tea.KeyMsg{Type: tea.KeyDown}); again != nil {
+		t.Error("a page that is already loading shouldn't be requested again")
+	}
+	updated, _ = model.Update(cmd())
+	model = updated.(Model)
+	if len(model.History) != 60 || model.HistoryCursor != "" || model.History[59].PID != 60 {
+		t.Fatalf("after paging: %d entries, cursor %q; want all 60 and no cursor", len(model.History), model.HistoryCursor)
+	}
+
+	// Typing a filter and pressing Enter reloads the first page with it
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	model = updated.(Model)
+	for _, r := range "3001 FAILED" {
+		updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		model = updated.(Model)
+	}
+	updated, cmd = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if model.HistoryFiltering || cmd == nil {
+		t.Fatal("Enter should apply the filter and reload history")
+	}
+	stale := HistoryLoadedMsg{History: storage.Entries, Query: models.HistoryQuery{}}
+	updated, _ = model.Update(stale)
+	model = updated.(Model)
+	if len(model.History) != 60 {
+		t.Error("a page loaded for an older filter should be ignored")
+	}
+	updated, _ = model.Update(cmd())
+	model = updated.(Model)
+	if len(model.History) != 1 || model.History[0].ProcessName != "vite" || model.HistoryIndex != 0 {
+		t.Errorf("filtered history = %+v, want only the failed kill on port 3001", model.History)
+	}
+	if !strings.Contains(model.View(), "Filter: 3001 FAILED") {
+		t.Error("view should show the active filter")
+	}
+
+	// New kills that the filter hides don't show up
+	model.Killer = &MockKiller{}
+	model.FilteredPorts = []models.PortInfo{{PortNumber: 3000, ProcessName: "node", PID: 99}}
+	model.Scanner = &MockScanner{}
+	updated, _ = model.Update(model.killPortCmd()())
+	if got := len(updated.(Model).History); got != 1 {
+		t.Errorf("history has %d entries after a kill the filter excludes, want 1", got)
+	}
+}
+
+func TestParseHistoryFilter(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		text    string
+		want    models.HistoryQuery
+		wantErr bool
+	}{
+		{"", models.HistoryQuery{}, false},
+		{"3000", models.HistoryQuery{Port: 3000}, false},
+		{"3000-3999 node", models.HistoryQuery{PortMin: 3000, PortMax: 3999, ProcessName: "node"}, false},
+		{"cmd:Vite failed", models.HistoryQuery{Command: "Vite", Status: models.StatusFailed}, false},
+		{"ok since:2d", models.HistoryQuery{Status: models.StatusSucceeded, Since: now.Add(-48 * time.Hour)}, false},
+		{"since:soon", models.HistoryQuery{}, true},
+		{"3000-", models.HistoryQuery{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := parseHistoryFilter(tt.text, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHistoryFilter(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseHistoryFilter(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModel_StatsView(t *testing.T) {
+	storage := &MockStorage{KillStats: models.KillStats{
+		Attempts:       5,
+		Kills:          4,
+		Failed:         1,
+		TopPorts:       []models.PortKillCount{{Port: 3000, ProcessName: "node", Kills: 3}},
+		TopProcesses:   []models.ProcessKillCount{{ProcessName: "node", Kills: 3}},
+		PerDay:         []models.DayKillCount{{Day: "2026-10-10", Kills: 4}},
+		Methods:        []models.MethodKillCount{{Method: "SIGTERM", Kills: 3}, {Method: "SIGKILL", Kills: 1}},
+		ForcedRatio:    0.25,
+		MedianDuration: 250 * time.Millisecond,
+	}}
+	storage.KillStats.PerHour[9] = 4
+	model := Model{Storage: storage}
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}})
+	model = updated.(Model)
+	if model.ViewMode != ViewModeStats || cmd == nil {
+		t.Fatalf("S should open the stats view and load stats")
+	}
+	if !strings.Contains(model.View(), "Loading") {
+		t.Error("stats view should show it is loading")
+	}
+	updated, _ = model.Update(cmd())
+	model = updated.(Model)
+
+	q := storage.StatsQueries[0]
+	if since := time.Since(q.Since); since < 7*24*time.Hour-time.Minute || since > 7*24*time.Hour+time.Minute {
+		t.Errorf("first window starts %v ago, want 7 days", since)
+	}
+	out := model.View()
+	for _, want := range []string{
+		"Kill Stats (last 7 days)", "Kills: 4 (1 failed attempts)", "Median time to terminate: 250ms",
+		"SIGKILL vs SIGTERM: 1 : 3 (25% of kills needed SIGKILL)", "3000   node", "2026-10-10 ", "09:00 ",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("stats view missing %q:\n%s", want, out)
+		}
+	}
+
+	// w cycles the window; results for the old window are dropped
+	stale := cmd()
+	updated, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	model = updated.(Model)
+	updated, _ = model.Update(stale)
+	if updated.(Model).Stats != nil {
+		t.Error("stats for the previous window should be ignored")
+	}
+	for i := 0; i < 2; i++ {
+		updated, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+		model = updated.(Model)
+	}
+	cmd()
+	if last := storage.StatsQueries[len(storage.StatsQueries)-1]; !last.Since.IsZero() {
+		t.Errorf("all-time window Since = %v, want zero", last.Since)
+	}
+
+	// Without storage the view doesn't open
+	updated, _ = Model{}.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}})
+	if updated.(Model).ViewMode == ViewModeStats {
+		t.Error("stats view shouldn't open without storage")
+	}
+}
+
+func TestModel_TimelineRecordsScans(t *testing.T) {
+	tl := &MockTimeline{}
+	model := Model{Timeline: tl, PreviousPorts: map[int]models.PortInfo{}}
+	ports := []models.PortInfo{{PortNumber: 3000, PID: 101, ProcessName: "node"}}
+
+	_, cmd := model.Update(PortsScannedMsg{Ports: ports, ScannedAt: time.Now()})
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatal("scan should batch the highlight timer with recording")
+	}
+	// The first command is the highlight timer
+	if _, ok := batch[1]().(TimelineRecordedMsg); !ok || len(tl.Observed) != 1 || tl.Observed[0][0].PortNumber != 3000 {
+		t.Fatalf("scan wasn't recorded: %+v", tl.Observed)
+	}
+
+	// A failure is reported once, not on every scan
+	updated, _ := model.Update(TimelineRecordedMsg{Error: errors.New("disk full")})
+	model = updated.(Model)
+	if !strings.Contains(model.StatusMessage, "disk full") {
+		t.Errorf("StatusMessage = %q, want the recording error", model.StatusMessage)
+	}
+	model.StatusMessage = ""
+	updated, _ = model.Update(TimelineRecordedMsg{Error: errors.New("disk full")})
+	if updated.(Model).StatusMessage != "" {
+		t.Error("a repeated failure shouldn't be reported again")
+	}
+}
+
+func TestModel_TimelineView(t *testing.T) {
+	now := time.Now()
+	tl := &MockTimeline{Held: []models.PortHolding{
+		{Port: 3000, PID: 101, ProcessName: "node", Command: "node server.js", From: now.Add(-3 * time.Hour), Until: now.Add(-2 * time.Hour)},
+		{Port: 3000, PID: 202, ProcessName: "vite", ContainerName: "web", From: now.Add(-time.Hour)},
+	}}
+	model := Model{
+		Timeline:      tl,
+		Height:        24,
+		FilteredPorts: []models.PortInfo{{PortNumber: 3000, PID: 202, ProcessName: "vite"}},
+		SelectedIndex: 0,
+	}
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	model = updated.(Model)
+	if model.ViewMode != ViewModeTimeline || model.TimelinePort != 3000 || cmd == nil {
+		t.Fatalf("t should open the selected port's timeline and load it")
+	}
+	if !strings.Contains(model.View(), "Loading") {
+		t.Error("timeline view should show it is loading")
+	}
+	updated, _ = model.Update(cmd())
+	model = updated.(Model)
+
+	if since := time.Since(tl.Queries[0].Since); tl.Queries[0].Port != 3000 || since < 24*time.Hour-time.Minute || since > 24*time.Hour+time.Minute {
+		t.Errorf("first query = %+v, want port 3000 over the last 24 hours", tl.Queries[0])
+	}
+	out := model.View()
+	for _, want := range []string{"Port 3000 Timeline (last 24 hours)", "vite (PID 202) [web]", "now", "node (PID 101)", "1h0m0s", "node server.js"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("timeline view missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "vite") > strings.Index(out, "node") {
+		t.Errorf("timeline should list the newest listener first:\n%s", out)
+	}
+
+	// A recorded change to this port reloads the timeline; other ports don't
+	if _, cmd := model.Update(TimelineRecordedMsg{Events: []models.PortEvent{{Port: 8080}}}); cmd != nil {
+		t.Error("a change to another port shouldn't reload the timeline")
+	}
+	if _, cmd := model.Update(TimelineRecordedMsg{Events: []models.PortEvent{{Port: 3000}}}); cmd == nil {
+		t.Error("a change to this port should reload the timeline")
+	}
+
+	// w cycles the window; results for the old window are dropped
+	stale := TimelineLoadedMsg{Port: 3000, Window: 0, Holdings: tl.Held}
+	updated, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	model = updated.(Model)
+	updated, _ = model.Update(stale)
+	if updated.(Model).TimelineHoldings != nil {
+		t.Error("a timeline for the previous window should be ignored")
+	}
+	for i := 0; i < 2; i++ {
+		updated, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+		model = updated.(Model)
+	}
+	cmd()
+	if last := tl.Queries[len(tl.Queries)-1]; !last.Since.IsZero() {
+		t.Errorf("everything-recorded window Since = %v, want zero", last.Since)
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if updated.(Model).ViewMode != ViewModeMain {
+		t.Error("esc should return to the main view")
+	}
+
+	// Without a timeline the view doesn't open and says why
+	updated, _ = Model{FilteredPorts: model.FilteredPorts}.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	if m := updated.(Model); m.ViewMode == ViewModeTimeline || !strings.Contains(m.StatusMessage, "off") {
+		t.Errorf("without a timeline: mode %v, status %q", m.ViewMode, m.StatusMessage)
+	}
+}
+
+func TestModel_EditLabel(t *testing.T) {
+	storage := &MockStorage{}
+	port := models.PortInfo{PortNumber: 3000, PID: 101, ProcessName: "node", WorkDir: "/src/shop"}
+	model := Model{Storage: storage, FilteredPorts: []models.PortInfo{port}, SelectedIndex: 0}
+	press := func(keys ...tea.KeyMsg) tea.Cmd {
+		var cmd tea.Cmd
+		for _, key := range keys {
+			var updated tea.Model
+			updated, cmd = model.Update(key)
+			model = updated.(Model)
+		}
+		return cmd
+	}
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	press(runes("l"))
+	if !model.LabelEditing || model.LabelDraft.Port != 3000 {
+		t.Fatalf("l should start editing a label for port 3000, draft = %+v", model.LabelDraft)
+	}
+	// Typed keys go to the label, not the main view's bindings
+	press(runes("storefront"), tea.KeyMsg{Type: tea.KeyBackspace}, runes("t"), tea.KeyMsg{Type: tea.KeyTab}, runes("q"), tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}, runes("team"))
+	if model.Quit || model.LabelDraft.Label != "storefront" || model.LabelDraft.Note != "q team" {
+		t.Fatalf("draft = %+v, want label storefront and note %q", model.LabelDraft, "q team")
+	}
+	if out := model.View(); !strings.Contains(out, "Note: q team_") || !strings.Contains(out, "Applies to: this port") {
+		t.Errorf("main view should show the editor:\n%s", out)
+	}
+
+	// Ctrl+P cycles the scope through the process name and project
+	press(tea.KeyMsg{Type: tea.KeyCtrlP}, tea.KeyMsg{Type: tea.KeyCtrlP})
+	if model.LabelDraft.ProcessName != "" || model.LabelDraft.Project != "/src/shop" {
+		t.Errorf("scope = %q; want the project", model.LabelDraft.Scope())
+	}
+
+	cmd := press(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.LabelEditing || cmd == nil {
+		t.Fatal("Enter should stop editing and save")
+	}
+	updated, cmd := model.Update(cmd())
+	model = updated.(Model)
+	if len(storage.PortLabels) != 1 || storage.PortLabels[0].Project != "/src/shop" || !strings.Contains(model.StatusMessage, "storefront") {
+		t.Fatalf("stored labels = %+v, status %q", storage.PortLabels, model.StatusMessage)
+	}
+	updated, _ = model.Update(cmd())
+	model = updated.(Model)
+
+	out := model.View()
+	if !strings.Contains(out, "3000 (storefront) - node") || !strings.Contains(out, "Note: q team") {
+		t.Errorf("main view should show the label and note:\n%s", out)
+	}
+	model.ViewMode = ViewModeConfirmKill
+	if out := model.View(); !strings.Contains(out, "Label: storefront") {
+		t.Errorf("kill dialog should show the label:\n%s", out)
+	}
+	model.ViewMode = ViewModeMain
+
+	// Editing picks up the existing label; emptying it removes it
+	press(runes("l"))
+	if model.LabelDraft.Label != "storefront" || model.LabelDraft.Project != "/src/shop" {
+		t.Fatalf("draft = %+v, want the saved label", model.LabelDraft)
+	}
+	for i := 0; i < len("storefront"); i++ {
+		press(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	press(tea.KeyMsg{Type: tea.KeyTab})
+	for i := 0; i < len("q team"); i++ {
+		press(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	cmd = press(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(cmd())
+	if len(storage.PortLabels) != 0 {
+		t.Errorf("labels = %+v, want the emptied label deleted", storage.PortLabels)
+	}
+
+	// Esc discards the edit
+	press(runes("l"), runes("x"), tea.KeyMsg{Type: tea.KeyEsc})
+	if model.LabelEditing || len(storage.PortLabels) != 0 {
+		t.Error("esc should stop editing without saving")
+	}
+}
+
+func TestModel_KillHistoryMarkers(t *testing.T) {
+	ports := []models.PortInfo{
+		{PortNumber: 3000, PID: 101, ProcessName: "node", KillCount: 4, LastKilled: time.Now().Add(-3 * time.Hour)},
+		{PortNumber: 8080, PID: 202, ProcessName: "java", KillCount: 1, LastKilled: time.Now().Add(-50 * time.Hour)},
+		{PortNumber: 5432, PID: 303, ProcessName: "postgres"},
+	}
+	model := Model{FilteredPorts: ports, SelectedIndex: 0}
+
+	out := model.View()
+	for _, want := range []string{"[!]\033[0m 3000 - node", "Kill Count: 4 (last killed 3h ago)", "Kill Count: 1 (last killed 2d ago)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("main view missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "[!]") != 1 || strings.Count(out, "Kill Count") != 2 {
+		t.Errorf("only port 3000 is recommended and only killed ports show a count:\n%s", out)
+	}
+
+	model.ViewMode = ViewModeConfirmKill
+	if out := model.View(); !strings.Contains(out, "Killed: 4 times in the last 30 days, last 3h ago") {
+		t.Errorf("kill dialog should show the kill history:\n%s", out)
+	}
+}
+
+// scored returns a port with the given recommendation score.
+func scored(port models.PortInfo, score float64, detail string) models.PortInfo {
+	port.Recommendation = &models.Recommendation{
+		Score:   score,
+		Reasons: []models.RecommendationReason{{Factor: "frequency", Points: score, Detail: detail}},
+	}
+	return port
+}
+
+func TestModel_SortByScore(t *testing.T) {
+	ports := []models.PortInfo{
+		scored(models.PortInfo{PortNumber: 3000, PID: 101, ProcessName: "node"}, 20, "killed once"),
+		scored(models.PortInfo{PortNumber: 8080, PID: 202, ProcessName: "java"}, 70, "killed 5 times"),
+		{PortNumber: 5432, PID: 303, ProcessName: "postgres"},
+		scored(models.PortInfo{PortNumber: 4000, PID: 404, ProcessName: "ruby"}, 45, "idle"),
+	}
+	model := Model{Ports: ports, FilteredPorts: ports, SelectedIndex: 0}
+
+	portOrder := func(m Model) []int {
+		var order []int
+		for _, port := range m.FilteredPorts {
+			order = append(order, port.PortNumber)
+		}
+		return order
+	}
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	model = updated.(Model)
+	if model.SortMode != SortByScore || !reflect.DeepEqual(portOrder(model), []int{8080, 4000, 3000, 5432}) {
+		t.Errorf("sorted by score = %v (%v), want 8080, 4000, 3000, 5432", portOrder(model), model.SortMode)
+	}
+	if out := model.View(); !strings.Contains(out, "Active Ports (by recommendation score):") ||
+		!strings.Contains(out, "Recommended (score 70): killed 5 times") || strings.Contains(out, "score 20") {
+		t.Errorf("main view should name the sort and explain only recommended ports:\n%s", out)
+	}
+	if ports[0].PortNumber != 3000 {
+		t.Error("sorting shouldn't reorder the scanned ports")
+	}
+
+	// The order survives a rescan
+	updated, _ = model.Update(PortsScannedMsg{Ports: ports, ScannedAt: time.Now()})
+	model = updated.(Model)
+	if !reflect.DeepEqual(portOrder(model), []int{8080, 4000, 3000, 5432}) {
+		t.Errorf("after a rescan = %v, want the score order kept", portOrder(model))
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	model = updated.(Model)
+	if model.SortMode != SortByScan || !reflect.DeepEqual(portOrder(model), []int{3000, 8080, 5432, 4000}) {
+		t.Errorf("back to scan order = %v (%v)", portOrder(model), model.SortMode)
+	}
+}
+
+func TestModel_KillRecommended(t *testing.T) {
+	ports := []models.PortInfo{
+		scored(models.PortInfo{PortNumber: 3000, PID: 101, ProcessName: "node"}, 60, "killed 5 times"),
+		scored(models.PortInfo{PortNumber: 3001, PID: 101, ProcessName: "node"}, 60, "killed 5 times"),
+		scored(models.PortInfo{PortNumber: 8080, PID: 202, ProcessName: "java"}, 90, "orphaned (its parent exited)"),
+		scored(models.PortInfo{PortNumber: 5432, PID: 303, ProcessName: "postgres"}, 80, "idle"),
+		scored(models.PortInfo{PortNumber: 4000, PID: 404, ProcessName: "ruby"}, 10, "idle"),
+		scored(models.PortInfo{PortNumber: 9000, PID: 505, ProcessName: "php"}, 50, "killed 3 times"),
+	}
+	killer := &MockKiller{FailPIDs: map[int]error{505: errors.New("permission denied")}}
+	sto := &MockStorage{}
+	model := Model{
+		Ports:         ports,
+		FilteredPorts: ports,
+		SelectedIndex: 0,
+		Scanner:       &MockScanner{},
+		Killer:        killer,
+		Storage:       sto,
+		Policy:        policy.New(policy.Rule{Name: "db", Action: policy.ActionConfirm, ProcessNames: []string{"postgres"}}),
+		Suspended:     map[int]models.PortInfo{202: ports[2]},
+	}
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("K")})
+	model = updated.(Model)
+	if model.ViewMode != ViewModeConfirmKillRecommended {
+		t.Fatalf("ViewMode = %v, want the kill-recommended dialog", model.ViewMode)
+	}
+	var targets []int
+	for _, port := range model.KillTargets {
+		targets = append(targets, port.PID)
+	}
+	if !reflect.DeepEqual(targets, []int{202, 101, 505}) || len(model.KillSkipped) != 1 || model.KillSkipped[0].PID != 303 {
+		t.Errorf("targets = %v, skipped = %+v; want one per process, highest score first, without the guarded one", targets, model.KillSkipped)
+	}
+	out := model.View()
+	for _, want := range []string{"8080 - java (PID: 202)", "score 90: orphaned (its parent exited)", "5432 - postgres (PID: 303) [Confirm: db]", "Press 'y' to kill 3 processes"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dialog missing %q:\n%s", want, out)
+		}
+	}
+	if len(killer.KilledPIDs) != 0 {
+		t.Fatal("nothing should be killed before confirming")
+	}
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	model = updated.(Model)
+	if cmd == nil {
+		t.Fatal("confirming should return the kill command")
+	}
+	msg, ok := cmd().(RecommendedKilledMsg)
+	if !ok || len(msg.Results) != 3 {
+		t.Fatalf("kill command returned %+v, want a result per target", msg)
+	}
+	if !reflect.DeepEqual(killer.KilledPIDs, []int{202, 101, 505}) || !reflect.DeepEqual(killer.Resumed, []int{202}) {
+		t.Errorf("killed %v, resumed %v; want the targets in order and the suspended one resumed first", killer.KilledPIDs, killer.Resumed)
+	}
+	for _, opts := range killer.Killed {
+		if opts != (KillOptions{}) {
+			t.Errorf("kill options = %+v, want the default strategy", opts)
+		}
+	}
+
+	updated, _ = model.Update(msg)
+	model = updated.(Model)
+	if model.ViewMode != ViewModeMain || model.KillTargets != nil || !model.Loading {
+		t.Errorf("after the kills: view %v, targets %v, loading %v; want a rescan of the main view", model.ViewMode, model.KillTargets, model.Loading)
+	}
+	if want := "Killed 2 of 3 recommended processes; php on 9000 failed: permission denied"; model.StatusMessage != want {
+		t.Errorf("StatusMessage = %q, want %q", model.StatusMessage, want)
+	}
+	if len(sto.Entries) != 3 || !sto.Entries[0].Failed {
+		t.Errorf("history = %+v, want every attempt recorded", sto.Entries)
+	}
+	if _, ok := model.Suspended[202]; ok {
+		t.Error("a killed process shouldn't stay suspended")
+	}
+
+	// Nothing recommended outside the policy
+	model.FilteredPorts = ports[3:5]
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("K")})
+	if model = updated.(Model); model.ViewMode != ViewModeMain || model.StatusMessage != "Every recommended process is protected by policy" {
+		t.Errorf("K with only guarded recommendations: view %v, status %q", model.ViewMode, model.StatusMessage)
+	}
+}
@@ -0,0 +1,230 @@
+// Package config loads user settings for Port Chaser.
+// Settings live in config.json under the platform config directory; a missing file means defaults.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manson/port-chaser/internal/platform"
+	"github.com/manson/port-chaser/internal/policy"
+)
+
+// Config holds all user-configurable settings.
+type Config struct {
+	// Kill controls how processes are terminated and observed afterwards
+	Kill KillConfig `json:"kill"`
+	// Policy controls which processes are protected from kills
+	Policy PolicyConfig `json:"policy"`
+	// Hooks are external commands run before and after kills
+	Hooks []HookConfig `json:"hooks,omitempty"`
+	// History controls how much kill history is kept
+	History HistoryConfig `json:"history"`
+	// Storage selects where the kill history is kept
+	Storage StorageConfig `json:"storage"`
+	// Timeline controls recording which processes listen on which ports over time
+	Timeline TimelineConfig `json:"timeline"`
+	// Redaction controls how secrets are masked in command lines
+	Redaction RedactionConfig `json:"redaction"`
+}
+
+// RedactionConfig controls secret redaction. Command lines and launch environments are
+// redacted before they are shown, stored, exported or passed to hooks.
+type RedactionConfig struct {
+	// Disabled turns redaction off, built-in patterns included (on by default)
+	Disabled bool `json:"disabled"`
+	// Patterns are extra regular expressions to mask; with a capturing group only the group is masked
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+// TimelineConfig controls the port occupancy timeline.
+type TimelineConfig struct {
+	// Enabled records every listener that appears or disappears while the TUI runs (off by default)
+	Enabled bool `json:"enabled"`
+}
+
+// StorageConfig selects the history storage backend.
+type StorageConfig struct {
+	// Backend is "sqlite" (the default), "jsonl" (a pure-Go JSON Lines file) or "memory" (no persistence)
+	Backend string `json:"backend"`
+	// Path is the database or JSON Lines file (empty means the default location)
+	Path string `json:"path"`
+}
+
+// HistoryConfig holds the kill history retention limits.
+type HistoryConfig struct {
+	// MaxAge deletes entries older than this (0 keeps them forever)
+	MaxAge Duration `json:"max_age"`
+	// MaxRows keeps only this many of the newest entries (0 means no limit)
+	MaxRows int `json:"max_rows"`
+	// PruneInterval is how often a running TUI applies the limits again (0 prunes at startup only)
+	PruneInterval Duration `json:"prune_interval"`
+}
+
+// HookConfig describes a command run at a kill event ("pre_kill" or "post_kill").
+// It applies to every port unless ProcessNames or Ports narrow it down.
+type HookConfig struct {
+	Name         string   `json:"name"`
+	Event        string   `json:"event"`
+	Command      []string `json:"command"`
+	Timeout      Duration `json:"timeout"`
+	ProcessNames []string `json:"process_names,omitempty"`
+	Ports        []int    `json:"ports,omitempty"`
+}
+
+// PolicyConfig holds the protection policy.
+type PolicyConfig struct {
+	// Rules are checked in order before the built-in rules; the first match decides
+	Rules []policy.Rule `json:"rules,omitempty"`
+	// DisableDefaults drops the built-in rules (low PIDs, privileged ports, sshd, root)
+	DisableDefaults bool `json:"disable_defaults"`
+}
+
+// Effective returns the protection rules to enforce: the configured ones, then the built-in ones.
+func (c PolicyConfig) Effective() []policy.Rule {
+	rules := append([]policy.Rule{}, c.Rules...)
+	if !c.DisableDefaults {
+		rules = append(rules, policy.DefaultRules()...)
+	}
+	return rules
+}
+
+// KillConfig holds settings for the kill flow.
+type KillConfig struct {
+	// RespawnWindow is how long to watch a port after a kill for a replacement listener (0 disables)
+	RespawnWindow Duration `json:"respawn_window"`
+	// ReleaseTimeout is how long to wait for the port to be freed after a kill (0 disables the check)
+	ReleaseTimeout Duration `json:"release_timeout"`
+	// GracePeriod is how long the default strategy waits after SIGTERM before SIGKILL
+	GracePeriod Duration `json:"grace_period"`
+	// RestartTimeout is how long a restarted process gets to listen on its port again
+	RestartTimeout Duration `json:"restart_timeout"`
+	// Strategies are per-process signal sequences, checked in order before the built-in ones
+	Strategies []StrategyConfig `json:"strategies,omitempty"`
+	// EscalateCommand is the command prefix used to retry a permission-denied kill with elevated
+	// privileges, e.g. ["sudo", "-n"] or a helper like ["doas"]; an empty list disables escalation
+	EscalateCommand []string `json:"escalate_command"`
+}
+
+// StrategyConfig describes a kill strategy: which processes it matches and which signals it sends.
+// A process matches if any of ProcessNames, CommandContains or Ports match.
+type StrategyConfig struct {
+	Name            string             `json:"name"`
+	ProcessNames    []string           `json:"process_names,omitempty"`
+	CommandContains []string           `json:"command_contains,omitempty"`
+	Ports           []int              `json:"ports,omitempty"`
+	Signals         []SignalStepConfig `json:"signals"`
+}
+
+// SignalStepConfig is one step of a strategy: the signal name and how long to wait for an exit afterwards.
+type SignalStepConfig struct {
+	Signal string   `json:"signal"`
+	Wait   Duration `json:"wait"`
+}
+
+// Duration is a time.Duration that reads and writes JSON as a string like "1.5s" or "500ms".
+type Duration struct {
+	time.Duration
+}
+
+// MarshalJSON encodes the duration as a Go duration string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts a Go duration string, a number of days like "90d", or a number of seconds.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		parsed, err := ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", s, err)
+		}
+		d.Duration = parsed
+		return nil
+	}
+
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return fmt.Errorf("invalid duration %s", string(data))
+	}
+	d.Duration = time.Duration(seconds * float64(time.Second))
+	return nil
+}
+
+// ParseDuration parses a Go duration string ("1.5s", "72h") or a whole number of days ("90d").
+func ParseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid number of days %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// Default returns a Config with sensible default settings.
+// The respawn window is 2 seconds, long enough for pm2 or systemd to restart a process,
+// and the grace period matches the killer's historical 3 seconds.
+func Default() *Config {
+	return &Config{
+		Kill: KillConfig{
+			RespawnWindow:  Duration{2 * time.Second},
+			ReleaseTimeout: Duration{2 * time.Second},
+			GracePeriod:    Duration{3 * time.Second},
+			RestartTimeout: Duration{10 * time.Second},
+			// -n makes sudo fail instead of prompting, since the TUI owns the terminal
+			EscalateCommand: []string{"sudo", "-n"},
+		},
+		History: HistoryConfig{
+			MaxAge:        Duration{90 * 24 * time.Hour},
+			MaxRows:       10000,
+			PruneInterval: Duration{time.Hour},
+		},
+	}
+}
+
+// DefaultPath returns the location of config.json in the platform config directory.
+func DefaultPath() string {
+	return filepath.Join(platform.GetConfigPath(), "config.json")
+}
+
+// Load reads the config file at path on top of the defaults.
+// A missing file is not an error; the defaults are returned instead.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return Default(), fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	// A rule without an action would silently allow everything it matches
+	for i, rule := range cfg.Policy.Rules {
+		if rule.Action == "" {
+			return Default(), fmt.Errorf("invalid config %s: policy rule %d (%q) has no action", path, i+1, rule.Name)
+		}
+	}
+	// A pattern that doesn't compile would leave the secrets it was meant for in the clear
+	for _, pattern := range cfg.Redaction.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return Default(), fmt.Errorf("invalid config %s: redaction pattern %q: %w", path, pattern, err)
+		}
+	}
+
+	return cfg, nil
+}
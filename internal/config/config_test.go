@@ -0,0 +1,298 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/policy"
+)
+
+func TestLoad_MissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Kill.RespawnWindow.Duration != 2*time.Second {
+		t.Errorf("RespawnWindow = %v, want 2s", cfg.Kill.RespawnWindow)
+	}
+	if cfg.Kill.ReleaseTimeout.Duration != 2*time.Second {
+		t.Errorf("ReleaseTimeout = %v, want 2s", cfg.Kill.ReleaseTimeout)
+	}
+	if cfg.Kill.RestartTimeout.Duration != 10*time.Second {
+		t.Errorf("RestartTimeout = %v, want 10s", cfg.Kill.RestartTimeout)
+	}
+}
+
+func TestLoad_Overrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"kill": {"respawn_window": "750ms"}}`), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Kill.RespawnWindow.Duration != 750*time.Millisecond {
+		t.Errorf("RespawnWindow = %v, want 750ms", cfg.Kill.RespawnWindow)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"kill": {"respawn_window": "soon"}}`), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err == nil {
+		t.Error("Load() should fail on an invalid duration")
+	}
+	if cfg == nil || cfg.Kill.RespawnWindow.Duration != 2*time.Second {
+		t.Error("Load() should fall back to defaults on error")
+	}
+}
+
+func TestLoad_EscalateCommand(t *testing.T) {
+	if got := Default().Kill.EscalateCommand; !reflect.DeepEqual(got, []string{"sudo", "-n"}) {
+		t.Errorf("default EscalateCommand = %v, want [sudo -n]", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"kill": {"escalate_command": []}}`), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.Kill.EscalateCommand) != 0 {
+		t.Errorf("EscalateCommand = %v, want empty (disabled)", cfg.Kill.EscalateCommand)
+	}
+}
+
+func TestLoad_Policy(t *testing.T) {
+	if rules := Default().Policy.Effective(); !reflect.DeepEqual(rules, policy.DefaultRules()) {
+		t.Errorf("default rules = %+v, want the built-in rules", rules)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"policy": {"rules": [{"name": "app", "action": "allow", "pids": [1]}]}}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	rules := cfg.Policy.Effective()
+	if len(rules) != len(policy.DefaultRules())+1 || rules[0].Name != "app" {
+		t.Errorf("rules = %+v, want the configured rule before the built-in ones", rules)
+	}
+
+	cfg.Policy.DisableDefaults = true
+	if rules := cfg.Policy.Effective(); len(rules) != 1 {
+		t.Errorf("rules with defaults disabled = %+v, want only the configured rule", rules)
+	}
+
+	for _, bad := range []string{
+		`{"policy": {"rules": [{"name": "x", "action": "nuke"}]}}`,
+		`{"policy": {"rules": [{"name": "x", "ports": [22]}]}}`,
+	} {
+		if err := os.WriteFile(path, []byte(bad), 0600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("Load(%s) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestDuration_UnmarshalSeconds(t *testing.T) {
+	var d Duration
+	if err := d.UnmarshalJSON([]byte("1.5")); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	if d.Duration != 1500*time.Millisecond {
+		t.Errorf("Duration = %v, want 1.5s", d.Duration)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"1.5s", 1500 * time.Millisecond, false},
+		{"72h", 72 * time.Hour, false},
+		{"90d", 90 * 24 * time.Hour, false},
+		{"0d", 0, false},
+		{"1.5d", 0, true},
+		{"-3d", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDuration(tt.in)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseDuration(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_History(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"history": {"max_age": "30d", "max_rows": 0}}`), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.History.MaxAge.Duration != 30*24*time.Hour || cfg.History.MaxRows != 0 {
+		t.Errorf("History = %+v, want 30 days and no row limit", cfg.History)
+	}
+	if cfg.History.PruneInterval.Duration != time.Hour {
+		t.Errorf("PruneInterval = %v, want the 1h default", cfg.History.PruneInterval)
+	}
+}
+
+func TestLoad_Storage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"storage": {"backend": "jsonl", "path": "/tmp/history.jsonl"}}`), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Storage.Backend != "jsonl" || cfg.Storage.Path != "/tmp/history.jsonl" {
+		t.Errorf("Storage = %+v, want the jsonl backend at /tmp/history.jsonl", cfg.Storage)
+	}
+	if Default().Storage.Backend != "" {
+		t.Errorf("default backend = %q, want empty (SQLite)", Default().Storage.Backend)
+	}
+}
+
+func TestLoad_Timeline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"timeline": {"enabled": true}}`), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Timeline.Enabled {
+		t.Error("Timeline.Enabled = false, want true")
+	}
+	if Default().Timeline.Enabled {
+		t.Error("the timeline should be off by default")
+	}
+}
+
+func TestLoad_Redaction(t *testing.T) {
+	if cfg := Default(); cfg.Redaction.Disabled || len(cfg.Redaction.Patterns) != 0 {
+		t.Errorf("default redaction = %+v, want the built-in patterns only", cfg.Redaction)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"redaction": {"disabled": true, "patterns": ["--db-pass (\\S+)"]}}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Redaction.Disabled || !reflect.DeepEqual(cfg.Redaction.Patterns, []string{`--db-pass (\S+)`}) {
+		t.Errorf("Redaction = %+v", cfg.Redaction)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"redaction": {"patterns": ["([unclosed"]}}`), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() with an invalid pattern succeeded, want error")
+	}
+}
+
+func TestLoad_Strategies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"kill": {
+		"grace_period": "5s",
+		"strategies": [
+			{"name": "java", "command_contains": ["java -jar"], "signals": [
+				{"signal": "SIGTERM", "wait": "15s"},
+				{"signal": "SIGKILL", "wait": 1}
+			]}
+		]
+	}}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Kill.GracePeriod.Duration != 5*time.Second {
+		t.Errorf("GracePeriod = %v, want 5s", cfg.Kill.GracePeriod)
+	}
+	if cfg.Kill.RespawnWindow.Duration != 2*time.Second {
+		t.Errorf("RespawnWindow = %v, want default 2s", cfg.Kill.RespawnWindow)
+	}
+	if len(cfg.Kill.Strategies) != 1 {
+		t.Fatalf("strategy count = %d, want 1", len(cfg.Kill.Strategies))
+	}
+
+	strategy := cfg.Kill.Strategies[0]
+	if strategy.Name != "java" || len(strategy.Signals) != 2 {
+		t.Fatalf("strategy = %+v", strategy)
+	}
+	if strategy.Signals[0].Signal != "SIGTERM" || strategy.Signals[0].Wait.Duration != 15*time.Second {
+		t.Errorf("first step = %+v, want SIGTERM 15s", strategy.Signals[0])
+	}
+	if strategy.Signals[1].Wait.Duration != time.Second {
+		t.Errorf("second step wait = %v, want 1s", strategy.Signals[1].Wait)
+	}
+}
+
+func TestLoad_Hooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"hooks": [
+		{"name": "flush", "event": "pre_kill", "command": ["./flush-queue.sh"], "timeout": "30s", "ports": [8080]},
+		{"name": "env", "event": "post_kill", "command": ["update-env"], "process_names": ["ssh"]}
+	]}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.Hooks) != 2 {
+		t.Fatalf("hook count = %d, want 2", len(cfg.Hooks))
+	}
+	flush := cfg.Hooks[0]
+	if flush.Event != "pre_kill" || flush.Timeout.Duration != 30*time.Second || !reflect.DeepEqual(flush.Ports, []int{8080}) {
+		t.Errorf("first hook = %+v", flush)
+	}
+	if cfg.Hooks[1].Timeout.Duration != 0 {
+		t.Errorf("second hook timeout = %v, want unset", cfg.Hooks[1].Timeout)
+	}
+}
@@ -0,0 +1,78 @@
+package detector
+
+import (
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// ActivityDetector fills in each port's ParentPID and marks processes that were orphaned
+// (adopted by init after their parent exited) and ports no client is connected to.
+// Both feed the recommendation score.
+type ActivityDetector struct {
+	// ParentPID returns the parent of a process (false if it can't be read)
+	ParentPID func(pid int) (int, bool)
+	// Clients returns how many connections to port the process has established (false if unknown)
+	Clients func(pid, port int) (int, bool)
+}
+
+// NewActivityDetector creates an ActivityDetector backed by the live process and socket tables.
+func NewActivityDetector() *ActivityDetector {
+	return &ActivityDetector{
+		ParentPID: parentPID,
+		Clients:   establishedClients,
+	}
+}
+
+// Detect sets ParentPID, Orphaned and Idle; what can't be read is left unset.
+func (d *ActivityDetector) Detect(ports []models.PortInfo) ([]models.PortInfo, error) {
+	result := make([]models.PortInfo, len(ports))
+	for i, port := range ports {
+		result[i] = port
+		if port.PID <= 1 {
+			continue
+		}
+		if ppid, ok := d.ParentPID(port.PID); ok {
+			result[i].ParentPID = ppid
+			result[i].Orphaned = ppid == 1
+		}
+		if clients, ok := d.Clients(port.PID, port.PortNumber); ok {
+			result[i].Idle = clients == 0
+		}
+	}
+	return result, nil
+}
+
+// IsAvailable always returns true since detection only needs the local process table.
+func (d *ActivityDetector) IsAvailable() bool {
+	return true
+}
+
+// parentPID reads the parent PID of a live process.
+func parentPID(pid int) (int, bool) {
+	p, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return 0, false
+	}
+	ppid, err := p.Ppid()
+	if err != nil {
+		return 0, false
+	}
+	return int(ppid), true
+}
+
+// establishedClients counts the process's established TCP connections on the local port.
+func establishedClients(pid, port int) (int, bool) {
+	conns, err := net.ConnectionsPid("tcp", int32(pid))
+	if err != nil {
+		return 0, false
+	}
+	clients := 0
+	for _, conn := range conns {
+		if conn.Laddr.Port == uint32(port) && conn.Status == "ESTABLISHED" {
+			clients++
+		}
+	}
+	return clients, true
+}
@@ -0,0 +1,65 @@
+package detector
+
+import (
+	"testing"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+func TestActivityDetector_Detect(t *testing.T) {
+	d := &ActivityDetector{
+		ParentPID: func(pid int) (int, bool) {
+			switch pid {
+			case 100:
+				return 1, true
+			case 200:
+				return 42, true
+			}
+			return 0, false
+		},
+		Clients: func(pid, port int) (int, bool) {
+			switch port {
+			case 3000:
+				return 0, true
+			case 8080:
+				return 3, true
+			}
+			return 0, false
+		},
+	}
+	ports := []models.PortInfo{
+		{PortNumber: 3000, PID: 100},
+		{PortNumber: 8080, PID: 200},
+		{PortNumber: 5432, PID: 300},
+		{PortNumber: 22, PID: 1},
+	}
+
+	got, err := d.Detect(ports)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		parentPID int
+		orphaned  bool
+		idle      bool
+	}{
+		{"adopted by init, no clients", 1, true, true},
+		{"running under a shell, serving clients", 42, false, false},
+		{"unreadable process", 0, false, false},
+		{"init itself is skipped", 0, false, false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := got[i]
+			if p.ParentPID != tt.parentPID || p.Orphaned != tt.orphaned || p.Idle != tt.idle {
+				t.Errorf("port %d = parent %d, orphaned %v, idle %v; want %d, %v, %v",
+					p.PortNumber, p.ParentPID, p.Orphaned, p.Idle, tt.parentPID, tt.orphaned, tt.idle)
+			}
+		})
+	}
+	if ports[0].Orphaned {
+		t.Error("Detect() shouldn't modify its input")
+	}
+}
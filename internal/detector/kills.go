@@ -0,0 +1,59 @@
+package detector
+
+import (
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// KillHistory summarizes the kill history of many ports at once; every storage backend implements it.
+type KillHistory interface {
+	// KillSummaries returns the kill count within days and the last kill time of each of the
+	// ports that was ever killed
+	KillSummaries(ports []int, days int) (map[int]models.PortKillSummary, error)
+}
+
+// KillHistoryDetector fills in KillCount, KillHours and LastKilled from the kill history, with a single
+// query per scan however many ports there are.
+type KillHistoryDetector struct {
+	// History is where kills are recorded (nil disables the detector)
+	History KillHistory
+	// Days is the window KillCount covers
+	Days int
+}
+
+// NewKillHistoryDetector creates a detector that counts kills of the last models.DefaultKillCountDays days.
+func NewKillHistoryDetector(history KillHistory) *KillHistoryDetector {
+	return &KillHistoryDetector{History: history, Days: models.DefaultKillCountDays}
+}
+
+// Detect sets each port's KillCount, KillHours and LastKilled; ports never killed get zero values.
+// If the history can't be read, the ports are returned unchanged with the error.
+func (d *KillHistoryDetector) Detect(ports []models.PortInfo) ([]models.PortInfo, error) {
+	numbers := make([]int, 0, len(ports))
+	seen := make(map[int]bool, len(ports))
+	for _, port := range ports {
+		if !seen[port.PortNumber] {
+			seen[port.PortNumber] = true
+			numbers = append(numbers, port.PortNumber)
+		}
+	}
+
+	summaries, err := d.History.KillSummaries(numbers, d.Days)
+	if err != nil {
+		return ports, err
+	}
+
+	result := make([]models.PortInfo, len(ports))
+	for i, port := range ports {
+		summary := summaries[port.PortNumber]
+		port.KillCount = summary.Kills
+		port.KillHours = summary.Hours
+		port.LastKilled = summary.LastKilled
+		result[i] = port
+	}
+	return result, nil
+}
+
+// IsAvailable reports whether there is a history to read.
+func (d *KillHistoryDetector) IsAvailable() bool {
+	return d.History != nil
+}
@@ -0,0 +1,68 @@
+package detector
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// fakeKillHistory returns canned summaries and records every query.
+type fakeKillHistory struct {
+	summaries map[int]models.PortKillSummary
+	err       error
+	queries   [][]int
+	days      []int
+}
+
+func (f *fakeKillHistory) KillSummaries(ports []int, days int) (map[int]models.PortKillSummary, error) {
+	f.queries = append(f.queries, ports)
+	f.days = append(f.days, days)
+	return f.summaries, f.err
+}
+
+func TestKillHistoryDetector_Detect(t *testing.T) {
+	last := time.Now().Add(-time.Hour)
+	history := &fakeKillHistory{summaries: map[int]models.PortKillSummary{
+		3000: {Port: 3000, Kills: 4, LastKilled: last, Hours: []int{14: 4}},
+		8080: {Port: 8080, LastKilled: last.AddDate(0, -2, 0)},
+	}}
+	d := NewKillHistoryDetector(history)
+	ports := []models.PortInfo{
+		{PortNumber: 3000, PID: 1},
+		{PortNumber: 8080, PID: 2},
+		{PortNumber: 3000, PID: 3},
+		{PortNumber: 5432, PID: 4, KillCount: 9},
+	}
+
+	got, err := d.Detect(ports)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(history.queries) != 1 || !reflect.DeepEqual(history.queries[0], []int{3000, 8080, 5432}) || history.days[0] != models.DefaultKillCountDays {
+		t.Errorf("queries = %v over %v days, want one query for each distinct port", history.queries, history.days)
+	}
+	if !got[0].IsRecommended() || !got[0].LastKilled.Equal(last) || got[2].KillCount != 4 || got[2].KillHours[14] != 4 {
+		t.Errorf("port 3000 = %+v, %+v; want 4 kills and the last kill time on both", got[0], got[2])
+	}
+	if got[1].KillCount != 0 || got[1].LastKilled.IsZero() {
+		t.Errorf("port 8080 = %+v, want no recent kills but a last kill time", got[1])
+	}
+	if got[3].KillCount != 0 || !got[3].LastKilled.IsZero() {
+		t.Errorf("port 5432 = %+v, want a port never killed reset to zero", got[3])
+	}
+	if ports[0].KillCount != 0 {
+		t.Error("Detect() shouldn't modify its input")
+	}
+
+	history.err = errors.New("database is locked")
+	if got, err := d.Detect(ports); err == nil || !reflect.DeepEqual(got, ports) {
+		t.Errorf("Detect() with a failing history = %+v, %v; want the ports unchanged and the error", got, err)
+	}
+
+	if (&KillHistoryDetector{}).IsAvailable() {
+		t.Error("a detector without history shouldn't be available")
+	}
+}
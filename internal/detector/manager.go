@@ -0,0 +1,418 @@
+package detector
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// Supported process manager names as stored in PortInfo.Manager and HistoryEntry.Manager.
+const (
+	ManagerPM2         = "pm2"
+	ManagerSupervisord = "supervisord"
+	ManagerForeman     = "foreman"
+	ManagerOvermind    = "overmind"
+	ManagerNodemon     = "nodemon"
+)
+
+// maxAncestryDepth bounds how far up the parent chain the detector walks.
+const maxAncestryDepth = 32
+
+// cliTimeout bounds how long a manager CLI (pm2 jlist, supervisorctl status) may run.
+const cliTimeout = 2 * time.Second
+
+// ProcessNode is a single process in an ancestry chain.
+type ProcessNode struct {
+	PID     int    // process ID
+	Name    string // short process name
+	Cmdline string // full command line
+	Cwd     string // working directory (may be empty if unreadable)
+}
+
+// ManagerDetector detects ports whose process is supervised by a process manager.
+// It walks the parent chain of each listener and consults the manager's state files or CLI
+// to find the app name and the manager-native stop command.
+type ManagerDetector struct {
+	// Ancestry returns the process chain for a PID, starting with the process itself
+	Ancestry func(pid int) []ProcessNode
+	// Environ returns the environment of a process as KEY=VALUE strings
+	Environ func(pid int) []string
+	// PM2Apps returns a mapping of PID to pm2 app name
+	PM2Apps func() map[int]string
+	// SupervisorPrograms returns a mapping of PID to supervisord program name
+	SupervisorPrograms func() map[int]string
+}
+
+// NewManagerDetector creates a ManagerDetector backed by the live process table.
+func NewManagerDetector() *ManagerDetector {
+	return &ManagerDetector{
+		Ancestry:           processAncestry,
+		Environ:            processEnviron,
+		PM2Apps:            pm2Apps,
+		SupervisorPrograms: supervisorPrograms,
+	}
+}
+
+// Detect enriches ports with process manager details when a manager is found in the ancestry.
+// Manager state is looked up at most once per call and only if a manager was seen.
+func (d *ManagerDetector) Detect(ports []models.PortInfo) ([]models.PortInfo, error) {
+	var pm2Map, supervisorMap map[int]string
+
+	result := make([]models.PortInfo, len(ports))
+	for i, port := range ports {
+		result[i] = port
+		if port.PID <= 0 {
+			continue
+		}
+
+		chain := d.Ancestry(port.PID)
+		info, ok := d.identify(port.PID, chain)
+		if !ok {
+			continue
+		}
+
+		switch info.Manager {
+		case ManagerPM2:
+			if pm2Map == nil {
+				pm2Map = d.PM2Apps()
+			}
+			info.ManagedName = lookupChain(pm2Map, chain)
+		case ManagerSupervisord:
+			if supervisorMap == nil {
+				supervisorMap = d.SupervisorPrograms()
+			}
+			info.ManagedName = lookupChain(supervisorMap, chain)
+		}
+
+		info.StopCommand = stopCommand(info, chain)
+		result[i] = EnrichManagerInfo(port, info)
+	}
+
+	return result, nil
+}
+
+// IsAvailable always returns true since detection only needs the local process table.
+func (d *ManagerDetector) IsAvailable() bool {
+	return true
+}
+
+// identify inspects the ancestry chain (skipping the process itself) for a known manager.
+// The closest manager wins, so nodemon under pm2 is reported as nodemon.
+func (d *ManagerDetector) identify(pid int, chain []ProcessNode) (models.ManagerInfo, bool) {
+	for i := 1; i < len(chain); i++ {
+		node := chain[i]
+		manager := matchManager(node)
+		if manager == "" {
+			continue
+		}
+
+		info := models.ManagerInfo{
+			Manager:    manager,
+			ManagerPID: node.PID,
+		}
+
+		switch manager {
+		case ManagerForeman, ManagerOvermind:
+			// Both export PS=<process>.<n> to the processes they spawn
+			info.ManagedName = envValue(d.Environ(pid), "PS")
+		case ManagerNodemon:
+			info.ManagedName = nodemonScript(node.Cmdline)
+		}
+		return info, true
+	}
+	return models.ManagerInfo{}, false
+}
+
+// matchManager returns the manager name for an ancestor process, or "" if it is not a manager.
+func matchManager(node ProcessNode) string {
+	name := strings.ToLower(node.Name)
+	cmdline := strings.ToLower(node.Cmdline)
+
+	switch {
+	case strings.HasPrefix(name, "pm2") || strings.Contains(cmdline, "pm2 v") || strings.Contains(cmdline, "god daemon"):
+		return ManagerPM2
+	case name == "supervisord" || containsWord(cmdline, "supervisord"):
+		return ManagerSupervisord
+	case name == "overmind" || containsWord(cmdline, "overmind") || overmindSocket(cmdline):
+		// Overmind runs processes inside tmux; the tmux server carries the overmind socket name
+		return ManagerOvermind
+	case name == "foreman" || containsWord(cmdline, "foreman"):
+		return ManagerForeman
+	case name == "nodemon" || containsWord(cmdline, "nodemon"):
+		return ManagerNodemon
+	}
+	return ""
+}
+
+// stopCommand returns the manager-native command that stops the process without a respawn.
+// It returns nil when the manager needs an app name that could not be determined.
+func stopCommand(info models.ManagerInfo, chain []ProcessNode) []string {
+	switch info.Manager {
+	case ManagerPM2:
+		if info.ManagedName == "" {
+			return nil
+		}
+		return []string{"pm2", "stop", info.ManagedName}
+	case ManagerSupervisord:
+		if info.ManagedName == "" {
+			return nil
+		}
+		return []string{"supervisorctl", "stop", info.ManagedName}
+	case ManagerOvermind:
+		if info.ManagedName == "" {
+			return nil
+		}
+		name := strings.SplitN(info.ManagedName, ".", 2)[0]
+		cmd := []string{"overmind", "stop"}
+		if dir := managerCwd(info.ManagerPID, chain); dir != "" {
+			cmd = append(cmd, "-s", filepath.Join(dir, ".overmind.sock"))
+		}
+		return append(cmd, name)
+	case ManagerForeman:
+		// Foreman has no per-process stop; interrupting it shuts the whole Procfile down
+		return []string{"kill", "-INT", strconv.Itoa(info.ManagerPID)}
+	case ManagerNodemon:
+		return []string{"kill", "-TERM", strconv.Itoa(info.ManagerPID)}
+	}
+	return nil
+}
+
+// EnrichManagerInfo creates a new PortInfo with process manager details merged in.
+func EnrichManagerInfo(port models.PortInfo, info models.ManagerInfo) models.PortInfo {
+	port.Manager = info.Manager
+	port.ManagedName = info.ManagedName
+	port.ManagerPID = info.ManagerPID
+	port.StopCommand = info.StopCommand
+	return port
+}
+
+// lookupChain returns the first name in names keyed by any PID in the chain.
+// pm2 and supervisord record the PID they spawned, which may be a parent of the listener.
+func lookupChain(names map[int]string, chain []ProcessNode) string {
+	for _, node := range chain {
+		if name, ok := names[node.PID]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// managerCwd returns the working directory of the manager process in the chain.
+func managerCwd(managerPID int, chain []ProcessNode) string {
+	for _, node := range chain {
+		if node.PID == managerPID {
+			return node.Cwd
+		}
+	}
+	return ""
+}
+
+// nodemonScript extracts the watched script from a nodemon command line.
+// Option values (--watch src) are hard to tell apart from the script, so an argument
+// with a file extension wins over the first bare argument.
+func nodemonScript(cmdline string) string {
+	fields := strings.Fields(cmdline)
+	for i, field := range fields {
+		if !strings.HasSuffix(field, "nodemon") && !strings.HasSuffix(field, "nodemon.js") {
+			continue
+		}
+
+		var firstBare string
+		for _, arg := range fields[i+1:] {
+			if strings.HasPrefix(arg, "-") {
+				continue
+			}
+			if filepath.Ext(arg) != "" {
+				return arg
+			}
+			if firstBare == "" {
+				firstBare = arg
+			}
+		}
+		return firstBare
+	}
+	return ""
+}
+
+// containsWord reports whether one of the whitespace-separated fields of s is the program word,
+// bare or as the last path component (/usr/local/bin/foreman). A directory that merely contains
+// the word (~/foreman-demo/run.sh) doesn't count.
+func containsWord(s, word string) bool {
+	for _, field := range strings.Fields(s) {
+		if filepath.Base(field) == word {
+			return true
+		}
+	}
+	return false
+}
+
+// overmindSocket reports whether cmdline is a tmux server started by overmind,
+// which names its socket overmind-<app>-<id> (tmux -L overmind-shop-abc123).
+func overmindSocket(cmdline string) bool {
+	fields := strings.Fields(cmdline)
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == "-l" && strings.HasPrefix(fields[i+1], "overmind-") {
+			return true
+		}
+	}
+	return false
+}
+
+// envValue returns the value of key in a KEY=VALUE environment list.
+func envValue(env []string, key string) string {
+	prefix := key + "="
+	for _, kv := range env {
+		if strings.HasPrefix(kv, prefix) {
+			return strings.TrimPrefix(kv, prefix)
+		}
+	}
+	return ""
+}
+
+// processAncestry walks the live process table from pid up to init.
+func processAncestry(pid int) []ProcessNode {
+	var chain []ProcessNode
+	for depth := 0; pid > 1 && depth < maxAncestryDepth; depth++ {
+		p, err := process.NewProcess(int32(pid))
+		if err != nil {
+			break
+		}
+
+		node := ProcessNode{PID: pid}
+		node.Name, _ = p.Name()
+		node.Cmdline, _ = p.Cmdline()
+		node.Cwd, _ = p.Cwd()
+		chain = append(chain, node)
+
+		ppid, err := p.Ppid()
+		if err != nil {
+			break
+		}
+		pid = int(ppid)
+	}
+	return chain
+}
+
+// processEnviron reads the environment of a live process.
+func processEnviron(pid int) []string {
+	p, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return nil
+	}
+	env, _ := p.Environ()
+	return env
+}
+
+// pm2Apps maps PIDs to pm2 app names using the pid files pm2 keeps in $PM2_HOME/pids,
+// falling back to `pm2 jlist` when no pid files are found.
+func pm2Apps() map[int]string {
+	apps := make(map[int]string)
+
+	home := os.Getenv("PM2_HOME")
+	if home == "" {
+		if userHome, err := os.UserHomeDir(); err == nil {
+			home = filepath.Join(userHome, ".pm2")
+		}
+	}
+	if home != "" {
+		files, _ := filepath.Glob(filepath.Join(home, "pids", "*.pid"))
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				continue
+			}
+			pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+			if err != nil {
+				continue
+			}
+			apps[pid] = parsePM2PidFile(filepath.Base(file))
+		}
+	}
+	if len(apps) > 0 {
+		return apps
+	}
+
+	output, err := runCLI("pm2", "jlist")
+	if err != nil {
+		return apps
+	}
+	return parsePM2JList(output)
+}
+
+// parsePM2PidFile extracts the app name from a pm2 pid file name ("<name>-<id>.pid").
+func parsePM2PidFile(base string) string {
+	name := strings.TrimSuffix(base, ".pid")
+	if idx := strings.LastIndex(name, "-"); idx > 0 {
+		if _, err := strconv.Atoi(name[idx+1:]); err == nil {
+			return name[:idx]
+		}
+	}
+	return name
+}
+
+// parsePM2JList parses the JSON printed by `pm2 jlist` into a PID to app name mapping.
+func parsePM2JList(output []byte) map[int]string {
+	apps := make(map[int]string)
+
+	var list []struct {
+		Name string `json:"name"`
+		PID  int    `json:"pid"`
+	}
+	if err := json.Unmarshal(output, &list); err != nil {
+		return apps
+	}
+	for _, app := range list {
+		if app.PID > 0 {
+			apps[app.PID] = app.Name
+		}
+	}
+	return apps
+}
+
+// supervisorPrograms maps PIDs to supervisord program names using `supervisorctl status`.
+func supervisorPrograms() map[int]string {
+	// supervisorctl exits non-zero when any program is not running, but still prints the table
+	output, _ := runCLI("supervisorctl", "status")
+	return parseSupervisorStatus(output)
+}
+
+// parseSupervisorStatus parses lines like "web   RUNNING   pid 1234, uptime 0:01:02".
+func parseSupervisorStatus(output []byte) map[int]string {
+	programs := make(map[int]string)
+
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[1] != "RUNNING" || fields[2] != "pid" {
+			continue
+		}
+		pid, err := strconv.Atoi(strings.TrimSuffix(fields[3], ","))
+		if err != nil {
+			continue
+		}
+		programs[pid] = fields[0]
+	}
+	return programs
+}
+
+// runCLI runs a manager CLI with a short timeout and returns its standard output.
+func runCLI(name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cliTimeout)
+	defer cancel()
+
+	return exec.CommandContext(ctx, name, args...).Output()
+}
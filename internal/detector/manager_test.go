@@ -0,0 +1,197 @@
+package detector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+func newTestManagerDetector(chains map[int][]ProcessNode, env map[int][]string) *ManagerDetector {
+	return &ManagerDetector{
+		Ancestry: func(pid int) []ProcessNode {
+			return chains[pid]
+		},
+		Environ: func(pid int) []string {
+			return env[pid]
+		},
+		PM2Apps: func() map[int]string {
+			return map[int]string{2001: "api"}
+		},
+		SupervisorPrograms: func() map[int]string {
+			return map[int]string{3001: "worker"}
+		},
+	}
+}
+
+func TestManagerDetector_Detect(t *testing.T) {
+	chains := map[int][]ProcessNode{
+		2001: {
+			{PID: 2001, Name: "node", Cmdline: "node /srv/api/index.js"},
+			{PID: 2000, Name: "PM2 v5.3.0: God", Cmdline: "PM2 v5.3.0: God Daemon (/home/dev/.pm2)"},
+		},
+		3001: {
+			{PID: 3001, Name: "python", Cmdline: "python worker.py"},
+			{PID: 3000, Name: "python3", Cmdline: "/usr/bin/python3 /usr/bin/supervisord -n"},
+		},
+		4001: {
+			{PID: 4001, Name: "ruby", Cmdline: "puma -p 5000"},
+			{PID: 4000, Name: "sh", Cmdline: "/bin/sh -c bundle exec puma"},
+			{PID: 3999, Name: "ruby", Cmdline: "/usr/bin/ruby /usr/local/bin/foreman start"},
+		},
+		5001: {
+			{PID: 5001, Name: "node", Cmdline: "node server.js"},
+			{PID: 5000, Name: "tmux: server", Cmdline: "tmux -L overmind-shop-abc123 new -d", Cwd: "/home/dev/shop"},
+		},
+		6001: {
+			{PID: 6001, Name: "node", Cmdline: "node server.js"},
+			{PID: 6000, Name: "node", Cmdline: "node /usr/local/bin/nodemon --watch src server.js"},
+		},
+		7001: {
+			{PID: 7001, Name: "python", Cmdline: "python -m http.server"},
+			{PID: 7000, Name: "zsh", Cmdline: "-zsh"},
+		},
+		7101: {
+			{PID: 7101, Name: "ruby", Cmdline: "ruby app.rb"},
+			{PID: 7100, Name: "bash", Cmdline: "bash /home/dev/foreman-x/run.sh"},
+		},
+		7201: {
+			{PID: 7201, Name: "node", Cmdline: "node /home/dev/nodemon-playground/app.js"},
+			{PID: 7200, Name: "zsh", Cmdline: "-zsh"},
+		},
+	}
+	env := map[int][]string{
+		4001: {"HOME=/home/dev", "PS=web.1"},
+		5001: {"PS=web.1"},
+	}
+
+	tests := []struct {
+		name    string
+		pid     int
+		want    models.ManagerInfo
+		managed bool
+	}{
+		{
+			name:    "pm2 app",
+			pid:     2001,
+			want:    models.ManagerInfo{Manager: ManagerPM2, ManagedName: "api", ManagerPID: 2000, StopCommand: []string{"pm2", "stop", "api"}},
+			managed: true,
+		},
+		{
+			name:    "supervisord program",
+			pid:     3001,
+			want:    models.ManagerInfo{Manager: ManagerSupervisord, ManagedName: "worker", ManagerPID: 3000, StopCommand: []string{"supervisorctl", "stop", "worker"}},
+			managed: true,
+		},
+		{
+			name:    "foreman process",
+			pid:     4001,
+			want:    models.ManagerInfo{Manager: ManagerForeman, ManagedName: "web.1", ManagerPID: 3999, StopCommand: []string{"kill", "-INT", "3999"}},
+			managed: true,
+		},
+		{
+			name:    "overmind process",
+			pid:     5001,
+			want:    models.ManagerInfo{Manager: ManagerOvermind, ManagedName: "web.1", ManagerPID: 5000, StopCommand: []string{"overmind", "stop", "-s", "/home/dev/shop/.overmind.sock", "web"}},
+			managed: true,
+		},
+		{
+			name:    "nodemon child",
+			pid:     6001,
+			want:    models.ManagerInfo{Manager: ManagerNodemon, ManagedName: "server.js", ManagerPID: 6000, StopCommand: []string{"kill", "-TERM", "6000"}},
+			managed: true,
+		},
+		{
+			name:    "unmanaged shell child",
+			pid:     7001,
+			managed: false,
+		},
+		{
+			name:    "script in a directory named after a manager",
+			pid:     7101,
+			managed: false,
+		},
+		{
+			name:    "app in a directory named after a manager",
+			pid:     7201,
+			managed: false,
+		},
+	}
+
+	detector := newTestManagerDetector(chains, env)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := detector.Detect([]models.PortInfo{{PortNumber: 3000, PID: tt.pid}})
+			if err != nil {
+				t.Fatalf("Detect() error = %v", err)
+			}
+
+			port := result[0]
+			if port.IsManaged() != tt.managed {
+				t.Fatalf("IsManaged() = %v, want %v", port.IsManaged(), tt.managed)
+			}
+			if !tt.managed {
+				return
+			}
+
+			got := models.ManagerInfo{
+				Manager:     port.Manager,
+				ManagedName: port.ManagedName,
+				ManagerPID:  port.ManagerPID,
+				StopCommand: port.StopCommand,
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("manager info = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManagerDetector_UnknownPM2AppHasNoStopCommand(t *testing.T) {
+	chains := map[int][]ProcessNode{
+		9001: {
+			{PID: 9001, Name: "node", Cmdline: "node index.js"},
+			{PID: 9000, Name: "PM2 v5.3.0: God", Cmdline: "PM2 v5.3.0: God Daemon (/root/.pm2)"},
+		},
+	}
+	detector := newTestManagerDetector(chains, nil)
+
+	result, err := detector.Detect([]models.PortInfo{{PortNumber: 8080, PID: 9001}})
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	if result[0].Manager != ManagerPM2 {
+		t.Errorf("Manager = %q, want %q", result[0].Manager, ManagerPM2)
+	}
+	if result[0].StopCommand != nil {
+		t.Errorf("StopCommand = %v, want nil", result[0].StopCommand)
+	}
+}
+
+func TestParseSupervisorStatus(t *testing.T) {
+	output := []byte(`api                              RUNNING   pid 1234, uptime 0:10:02
+worker                           STOPPED   Oct 18 09:12 AM
+queue:queue_00                   RUNNING   pid 1240, uptime 0:10:01
+`)
+
+	got := parseSupervisorStatus(output)
+	want := map[int]string{1234: "api", 1240: "queue:queue_00"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSupervisorStatus() = %v, want %v", got, want)
+	}
+}
+
+func TestParsePM2(t *testing.T) {
+	if got := parsePM2PidFile("my-api-0.pid"); got != "my-api" {
+		t.Errorf("parsePM2PidFile() = %q, want my-api", got)
+	}
+
+	got := parsePM2JList([]byte(`[{"name":"api","pid":4242},{"name":"stopped","pid":0}]`))
+	want := map[int]string{4242: "api"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePM2JList() = %v, want %v", got, want)
+	}
+}
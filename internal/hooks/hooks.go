@@ -0,0 +1,225 @@
+// Package hooks runs user-configured commands before and after a kill.
+// Each hook gets the port (and, after the kill, its result) as JSON on stdin and as
+// PORT_CHASER_* environment variables. A failing pre-kill hook vetoes the kill.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// Event is the point in the kill flow a hook runs at.
+type Event string
+
+const (
+	// PreKill hooks run before any signal is sent; a failure vetoes the kill
+	PreKill Event = "pre_kill"
+	// PostKill hooks run after every kill attempt, successful or not
+	PostKill Event = "post_kill"
+)
+
+// ParseEvent converts a configured event name into an Event.
+func ParseEvent(name string) (Event, error) {
+	switch event := Event(strings.ToLower(strings.TrimSpace(name))); event {
+	case PreKill, PostKill:
+		return event, nil
+	}
+	return "", fmt.Errorf("unknown hook event %q (want pre_kill or post_kill)", name)
+}
+
+// DefaultTimeout bounds hooks that don't configure their own timeout.
+const DefaultTimeout = 10 * time.Second
+
+// maxOutput is how much of a hook's combined output is kept (the tail, where errors usually are).
+const maxOutput = 4096
+
+// ErrVetoed is returned (wrapped) by Run when a pre-kill hook fails.
+var ErrVetoed = errors.New("vetoed by hook")
+
+// Hook is an external command run at a kill event.
+// It applies to every port unless ProcessNames or Ports narrow it down (either may match).
+type Hook struct {
+	// Name identifies the hook in the TUI and in errors
+	Name string
+	// Event is when the hook runs
+	Event Event
+	// Command is the program and its arguments; it is run directly, not through a shell
+	Command []string
+	// Timeout bounds the run (0 means DefaultTimeout); a timed out pre-kill hook vetoes the kill
+	Timeout time.Duration
+	// ProcessNames restricts the hook to processes with these names (case-insensitive)
+	ProcessNames []string
+	// Ports restricts the hook to these port numbers
+	Ports []int
+}
+
+// Matches reports whether the hook applies to the port's process.
+func (h Hook) Matches(port *models.PortInfo) bool {
+	if len(h.ProcessNames) == 0 && len(h.Ports) == 0 {
+		return true
+	}
+	for _, name := range h.ProcessNames {
+		if strings.EqualFold(name, port.ProcessName) {
+			return true
+		}
+	}
+	for _, p := range h.Ports {
+		if p == port.PortNumber {
+			return true
+		}
+	}
+	return false
+}
+
+// Result is the outcome of a kill attempt, passed to post-kill hooks.
+type Result struct {
+	// Success is true if the process was terminated
+	Success bool `json:"success"`
+	// Message describes the outcome or the error
+	Message string `json:"message,omitempty"`
+	// Report holds the kill details (signal used, port release, respawn) when the kill succeeded
+	Report interface{} `json:"report,omitempty"`
+}
+
+// Payload is the JSON document written to a hook's stdin.
+type Payload struct {
+	// Event is when the hook runs
+	Event Event `json:"event"`
+	// Hook is the hook's configured name
+	Hook string `json:"hook"`
+	// Port is the port and process being killed
+	Port models.PortInfo `json:"port"`
+	// Result is the kill outcome (post-kill hooks only)
+	Result *Result `json:"result,omitempty"`
+}
+
+// Runner runs the configured hooks.
+type Runner struct {
+	Hooks []Hook
+}
+
+// NewRunner creates a runner for hooks, run in order.
+func NewRunner(hooks ...Hook) *Runner {
+	return &Runner{Hooks: hooks}
+}
+
+// Run runs every hook for event that matches port, in order, and returns their runs.
+// For PreKill the first failing hook stops the run and Run returns an error wrapping ErrVetoed;
+// PostKill hooks all run and their failures are only recorded. A nil runner runs nothing.
+func (r *Runner) Run(ctx context.Context, event Event, port models.PortInfo, result *Result) ([]models.HookRun, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	var runs []models.HookRun
+	for _, hook := range r.Hooks {
+		if hook.Event != event || !hook.Matches(&port) {
+			continue
+		}
+		run := runHook(ctx, hook, Payload{Event: event, Hook: hook.Name, Port: port, Result: result})
+		runs = append(runs, run)
+		if event == PreKill && run.Failed() {
+			return runs, fmt.Errorf("%w %q: %s", ErrVetoed, hook.Name, vetoReason(run))
+		}
+	}
+	return runs, nil
+}
+
+// runHook runs one hook with the payload on stdin and returns what happened.
+func runHook(ctx context.Context, hook Hook, payload Payload) models.HookRun {
+	run := models.HookRun{Hook: hook.Name, Event: string(hook.Event), ExitCode: -1}
+	if len(hook.Command) == 0 {
+		run.Error = "no command configured"
+		return run
+	}
+
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stdin, err := json.Marshal(payload)
+	if err != nil {
+		run.Error = err.Error()
+		return run
+	}
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, hook.Command[0], hook.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.Env = append(os.Environ(), Env(payload)...)
+	// Don't wait for background children that inherited the output pipe
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err = cmd.Run()
+	run.Duration = time.Since(start)
+	run.Output = tail(output.String(), maxOutput)
+	if cmd.ProcessState != nil {
+		run.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		run.Error = fmt.Sprintf("timed out after %s", timeout)
+	case err != nil:
+		run.Error = err.Error()
+	}
+	return run
+}
+
+// Env returns the PORT_CHASER_* variables describing the payload, e.g. PORT_CHASER_PORT=3000.
+// Result variables are only set for post-kill hooks.
+func Env(payload Payload) []string {
+	env := []string{
+		"PORT_CHASER_EVENT=" + string(payload.Event),
+		"PORT_CHASER_HOOK=" + payload.Hook,
+		"PORT_CHASER_PORT=" + strconv.Itoa(payload.Port.PortNumber),
+		"PORT_CHASER_PID=" + strconv.Itoa(payload.Port.PID),
+		"PORT_CHASER_PROCESS=" + payload.Port.ProcessName,
+		"PORT_CHASER_COMMAND=" + payload.Port.Command,
+		"PORT_CHASER_USER=" + payload.Port.User,
+	}
+	if payload.Result != nil {
+		env = append(env,
+			"PORT_CHASER_KILL_SUCCESS="+strconv.FormatBool(payload.Result.Success),
+			"PORT_CHASER_KILL_MESSAGE="+payload.Result.Message)
+	}
+	return env
+}
+
+// vetoReason summarizes a failed pre-kill hook: its error and the last line of its output.
+func vetoReason(run models.HookRun) string {
+	if line := LastLine(run.Output); line != "" {
+		return run.Error + ": " + line
+	}
+	return run.Error
+}
+
+// LastLine returns the last non-empty line of output.
+func LastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// tail returns at most the last n bytes of s.
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return "..." + s[len(s)-n:]
+}
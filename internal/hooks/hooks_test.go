@@ -0,0 +1,179 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Event
+		wantErr bool
+	}{
+		{"pre_kill", PreKill, false},
+		{" POST_KILL ", PostKill, false},
+		{"before", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEvent(tt.name)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseEvent(%q) = %q, %v; want %q, error %v", tt.name, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHook_Matches(t *testing.T) {
+	port := &models.PortInfo{PortNumber: 3000, ProcessName: "node"}
+	tests := []struct {
+		name string
+		hook Hook
+		want bool
+	}{
+		{"no criteria", Hook{}, true},
+		{"process name", Hook{ProcessNames: []string{"Node"}}, true},
+		{"port", Hook{Ports: []int{8080, 3000}}, true},
+		{"either may match", Hook{ProcessNames: []string{"python"}, Ports: []int{3000}}, true},
+		{"no match", Hook{ProcessNames: []string{"python"}, Ports: []int{8080}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hook.Matches(port); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// requireShell skips tests that run hooks through sh.
+func requireShell(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+}
+
+func TestRunner_Run(t *testing.T) {
+	requireShell(t)
+
+	port := models.PortInfo{PortNumber: 3000, ProcessName: "node", PID: 4242}
+	stdinFile := filepath.Join(t.TempDir(), "stdin.json")
+	runner := NewRunner(
+		Hook{Name: "flush", Event: PreKill, Command: []string{"sh", "-c", `echo "flushing $PORT_CHASER_PROCESS:$PORT_CHASER_PORT"`}},
+		Hook{Name: "other", Event: PreKill, Command: []string{"false"}, Ports: []int{8080}},
+		Hook{Name: "notify", Event: PostKill, Command: []string{"sh", "-c", `cat > "$1"; echo "success=$PORT_CHASER_KILL_SUCCESS"`, "sh", stdinFile}},
+	)
+
+	runs, err := runner.Run(context.Background(), PreKill, port, nil)
+	if err != nil {
+		t.Fatalf("Run(PreKill) error = %v", err)
+	}
+	if len(runs) != 1 || runs[0].Hook != "flush" || runs[0].ExitCode != 0 {
+		t.Fatalf("runs = %+v, want only the matching flush hook", runs)
+	}
+	if got := strings.TrimSpace(runs[0].Output); got != "flushing node:3000" {
+		t.Errorf("Output = %q, want the PORT_CHASER_* variables expanded", got)
+	}
+
+	runs, err = runner.Run(context.Background(), PostKill, port, &Result{Success: true, Message: "killed", Report: map[string]string{"method": "SIGTERM"}})
+	if err != nil {
+		t.Fatalf("Run(PostKill) error = %v", err)
+	}
+	if len(runs) != 1 || strings.TrimSpace(runs[0].Output) != "success=true" {
+		t.Fatalf("runs = %+v", runs)
+	}
+
+	data, err := os.ReadFile(stdinFile)
+	if err != nil {
+		t.Fatalf("hook didn't write its stdin: %v", err)
+	}
+	var payload struct {
+		Event  Event
+		Hook   string
+		Port   models.PortInfo
+		Result struct {
+			Success bool
+			Report  map[string]string
+		}
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("stdin is not JSON: %v\n%s", err, data)
+	}
+	if payload.Event != PostKill || payload.Hook != "notify" || payload.Port.PID != 4242 ||
+		!payload.Result.Success || payload.Result.Report["method"] != "SIGTERM" {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestRunner_Veto(t *testing.T) {
+	requireShell(t)
+
+	port := models.PortInfo{PortNumber: 5432, ProcessName: "postgres"}
+	tests := []struct {
+		name    string
+		hook    Hook
+		wantErr string
+	}{
+		{"non-zero exit", Hook{Name: "busy", Command: []string{"sh", "-c", "echo queue not empty; exit 3"}}, `vetoed by hook "busy": exit status 3: queue not empty`},
+		{"timeout", Hook{Name: "slow", Command: []string{"sleep", "5"}, Timeout: 100 * time.Millisecond}, `vetoed by hook "slow": timed out after 100ms`},
+		{"missing command", Hook{Name: "gone", Command: []string{"/nonexistent/hook"}}, `vetoed by hook "gone"`},
+		{"empty command", Hook{Name: "empty"}, `vetoed by hook "empty": no command configured`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.hook.Event = PreKill
+			after := Hook{Name: "after", Event: PreKill, Command: []string{"true"}}
+			runs, err := NewRunner(tt.hook, after).Run(context.Background(), PreKill, port, nil)
+			if !errors.Is(err, ErrVetoed) {
+				t.Fatalf("Run() error = %v, want ErrVetoed", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Run() error = %q, want it to contain %q", err, tt.wantErr)
+			}
+			if len(runs) != 1 || !runs[0].Failed() {
+				t.Errorf("runs = %+v, want one failed run and no hooks after the veto", runs)
+			}
+		})
+	}
+
+	// A failing post-kill hook is recorded but doesn't fail the run
+	post := NewRunner(Hook{Name: "notify", Event: PostKill, Command: []string{"false"}}, Hook{Name: "next", Event: PostKill, Command: []string{"true"}})
+	runs, err := post.Run(context.Background(), PostKill, port, &Result{})
+	if err != nil || len(runs) != 2 || !runs[0].Failed() || runs[1].Failed() {
+		t.Errorf("Run(PostKill) = %+v, %v; want both hooks run and only the first failed", runs, err)
+	}
+}
+
+func TestRunner_Nil(t *testing.T) {
+	var runner *Runner
+	if runs, err := runner.Run(context.Background(), PreKill, models.PortInfo{}, nil); runs != nil || err != nil {
+		t.Errorf("nil Runner.Run() = %v, %v; want nothing", runs, err)
+	}
+}
+
+func TestTail(t *testing.T) {
+	if got := tail("short", 10); got != "short" {
+		t.Errorf("tail() = %q", got)
+	}
+	if got := tail("0123456789", 4); got != "...6789" {
+		t.Errorf("tail() = %q, want the last 4 bytes", got)
+	}
+	if got := LastLine("first\nsecond\n\n"); got != "second" {
+		t.Errorf("LastLine() = %q", got)
+	}
+}
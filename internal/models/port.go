@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
 
 // PortInfo contains detailed information about a listening port and its associated process.
 // This is the primary data structure for displaying ports in the TUI.
@@ -13,6 +17,16 @@ type PortInfo struct {
 	PID int `json:"pid"`
 	// User is the username of the process owner
 	User string `json:"user"`
+	// Protocol is the listening socket's protocol ("tcp" or "tcp6"; empty if unknown)
+	Protocol string `json:"protocol,omitempty"`
+	// Address is the local IP the socket listens on (e.g. "0.0.0.0", "127.0.0.1", "::")
+	Address string `json:"address,omitempty"`
+	// StartTime is when the process started, captured at scan time to detect PID reuse
+	StartTime time.Time `json:"start_time,omitempty"`
+	// Executable is the path of the process binary, captured at scan time to detect PID reuse
+	Executable string `json:"executable,omitempty"`
+	// WorkDir is the process's working directory (empty if it can't be read); labels scoped to a project match it
+	WorkDir string `json:"work_dir,omitempty"`
 	// Command is the full command line that launched the process
 	Command string `json:"command"`
 	// IsDocker is true if this port belongs to a Docker container
@@ -25,10 +39,58 @@ type PortInfo struct {
 	ImageName string `json:"image_name"`
 	// IsSystem is true if this is a system process that should be treated carefully
 	IsSystem bool `json:"is_system"`
+	// Manager is the process manager supervising this process (pm2, supervisord, foreman, overmind, nodemon)
+	Manager string `json:"manager,omitempty"`
+	// ManagedName is the app/program name the process manager knows this process by
+	ManagedName string `json:"managed_name,omitempty"`
+	// ManagerPID is the PID of the process manager (or its daemon) in the ancestry chain
+	ManagerPID int `json:"manager_pid,omitempty"`
+	// StopCommand is the manager-native command that stops this process without a respawn
+	StopCommand []string `json:"stop_command,omitempty"`
 	// KillCount is how many times this port has been killed (tracked in history)
 	KillCount int `json:"kill_count"`
 	// LastKilled is when this port was last killed (zero if never)
 	LastKilled time.Time `json:"last_killed"`
+	// KillHours counts the kills within the KillCount window by local hour of day (nil if there were none)
+	KillHours []int `json:"kill_hours,omitempty"`
+	// ParentPID is the PID of the process's parent (0 if unknown)
+	ParentPID int `json:"parent_pid,omitempty"`
+	// Orphaned is true when the process's parent exited and it was adopted by init
+	Orphaned bool `json:"orphaned,omitempty"`
+	// Idle is true when no client is connected to the port
+	Idle bool `json:"idle,omitempty"`
+	// Recommendation is how strongly killing this port is suggested, and why (nil if not scored)
+	Recommendation *Recommendation `json:"recommendation,omitempty"`
+}
+
+// RecommendThreshold is the score from which a scored port is recommended for killing.
+const RecommendThreshold = 40
+
+// Recommendation is a port's kill recommendation score, out of 100, with the reasons behind it.
+type Recommendation struct {
+	// Score is the sum of the points of the reasons
+	Score float64 `json:"score"`
+	// Reasons are the factors that contributed, most points first
+	Reasons []RecommendationReason `json:"reasons,omitempty"`
+}
+
+// RecommendationReason is one factor's contribution to a recommendation score.
+type RecommendationReason struct {
+	// Factor names the signal (frequency, recency, time_of_day, age, orphaned, idle)
+	Factor string `json:"factor"`
+	// Points is how much the factor added to the score
+	Points float64 `json:"points"`
+	// Detail explains the factor for this port (e.g. "killed 5 times in the last 30 days")
+	Detail string `json:"detail"`
+}
+
+// Explain joins the reasons' details, most points first (e.g. "killed 5 times in the last 30 days, idle").
+func (r Recommendation) Explain() string {
+	details := make([]string, len(r.Reasons))
+	for i, reason := range r.Reasons {
+		details[i] = reason.Detail
+	}
+	return strings.Join(details, ", ")
 }
 
 // HistoryEntry represents a single entry in the kill history log.
@@ -44,10 +106,574 @@ type HistoryEntry struct {
 	PID int `json:"pid"`
 	// Command is the command line of the killed process
 	Command string `json:"command"`
+	// Manager is the process manager that supervised the process (empty if none)
+	Manager string `json:"manager,omitempty"`
+	// Outcome is what happened after the kill (OutcomeKilled, OutcomeRespawned, OutcomeStopped)
+	Outcome string `json:"outcome,omitempty"`
+	// RespawnPID is the PID that took the port over after the kill (0 if none)
+	RespawnPID int `json:"respawn_pid,omitempty"`
+	// Supervisor names what likely respawned the process (parent PID, systemd unit, Docker restart policy)
+	Supervisor string `json:"supervisor,omitempty"`
+	// Escalated is true when the kill was retried with elevated privileges (sudo or a helper)
+	Escalated bool `json:"escalated,omitempty"`
+	// Launch is how the process was started, captured before the kill so it can be restarted (nil if unknown)
+	Launch *LaunchContext `json:"launch,omitempty"`
+	// Failed is true for kill attempts that didn't terminate the process; Error then says why.
+	// It is a negative so entries from writers that predate it read as the successful kills they were.
+	Failed bool `json:"failed,omitempty"`
+	// Error is the failure message of an unsuccessful kill
+	Error string `json:"error,omitempty"`
+	// Method is the signal that finally terminated the process (e.g. "SIGINT"), or "stop" for a manager stop
+	Method string `json:"method,omitempty"`
+	// Duration is how long the kill took, from the first signal until the process was gone
+	Duration time.Duration `json:"duration,omitempty"`
+	// KilledBy is the user who ran port-chaser
+	KilledBy string `json:"killed_by,omitempty"`
+	// Owner is the user the killed process ran as
+	Owner string `json:"owner,omitempty"`
+	// Protocol is the protocol of the port's socket ("tcp" or "tcp6")
+	Protocol string `json:"protocol,omitempty"`
+	// Address is the local IP the port was bound to
+	Address string `json:"address,omitempty"`
+	// ContainerID is the Docker container that owned the port (empty if none)
+	ContainerID string `json:"container_id,omitempty"`
+	// ContainerName is the name of that container
+	ContainerName string `json:"container_name,omitempty"`
+	// ImageName is the image of that container
+	ImageName string `json:"image_name,omitempty"`
 	// KilledAt is when the process was terminated
 	KilledAt time.Time `json:"killed_at"`
 }
 
+// Outcomes recorded in HistoryEntry.Outcome.
+const (
+	// OutcomeKilled means the process was terminated and the port stayed free
+	OutcomeKilled = "killed"
+	// OutcomeRespawned means a new process took the port within the respawn window
+	OutcomeRespawned = "respawned"
+	// OutcomeStopped means the process was stopped through its process manager
+	OutcomeStopped = "stopped"
+	// OutcomePortHeld means the process died but another process still held the port
+	OutcomePortHeld = "port_held"
+	// OutcomeFailed means the kill attempt failed or was cancelled (see HistoryEntry.Error)
+	OutcomeFailed = "failed"
+)
+
+// HistoryStatus selects history entries by whether the kill succeeded.
+type HistoryStatus string
+
+const (
+	// StatusAny matches every entry
+	StatusAny HistoryStatus = ""
+	// StatusSucceeded matches kills that terminated the process
+	StatusSucceeded HistoryStatus = "succeeded"
+	// StatusFailed matches kill attempts that failed or were cancelled
+	StatusFailed HistoryStatus = "failed"
+)
+
+// HistoryQuery filters and pages kill history. Zero fields don't filter; entries come newest first.
+type HistoryQuery struct {
+	// Port matches a single port number
+	Port int `json:"port,omitempty"`
+	// PortMin and PortMax match an inclusive port range (0 leaves that end open)
+	PortMin int `json:"port_min,omitempty"`
+	PortMax int `json:"port_max,omitempty"`
+	// ProcessName matches the process name exactly, ignoring case
+	ProcessName string `json:"process_name,omitempty"`
+	// Command matches entries whose command line contains this text, ignoring case
+	Command string `json:"command,omitempty"`
+	// Since matches entries killed at or after this time
+	Since time.Time `json:"since,omitempty"`
+	// Until matches entries killed before this time
+	Until time.Time `json:"until,omitempty"`
+	// Status matches successful or failed kills
+	Status HistoryStatus `json:"status,omitempty"`
+	// Limit is the page size (0 means DefaultHistoryPageSize)
+	Limit int `json:"limit,omitempty"`
+	// Cursor continues from a previous page's NextCursor (empty starts at the newest entry)
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// DefaultHistoryPageSize is the page size of queries that don't set a limit.
+const DefaultHistoryPageSize = 50
+
+// Matches reports whether entry passes the query's filters; Limit and Cursor are ignored.
+func (q HistoryQuery) Matches(entry HistoryEntry) bool {
+	switch {
+	case q.Port != 0 && entry.PortNumber != q.Port,
+		q.PortMin != 0 && entry.PortNumber < q.PortMin,
+		q.PortMax != 0 && entry.PortNumber > q.PortMax,
+		q.ProcessName != "" && !strings.EqualFold(entry.ProcessName, q.ProcessName),
+		q.Command != "" && !strings.Contains(strings.ToLower(entry.Command), strings.ToLower(q.Command)),
+		!q.Since.IsZero() && entry.KilledAt.Before(q.Since),
+		!q.Until.IsZero() && !entry.KilledAt.Before(q.Until),
+		q.Status == StatusSucceeded && entry.Failed,
+		q.Status == StatusFailed && !entry.Failed:
+		return false
+	}
+	return true
+}
+
+// HistoryPage is one page of a history query.
+type HistoryPage struct {
+	// Entries are the matching entries, newest first
+	Entries []HistoryEntry `json:"entries"`
+	// NextCursor fetches the following page (empty on the last page)
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// StatsQuery selects the window kill statistics are computed over.
+type StatsQuery struct {
+	// Since starts the window (zero means the first entry)
+	Since time.Time `json:"since,omitempty"`
+	// Until ends the window, exclusive (zero means now)
+	Until time.Time `json:"until,omitempty"`
+	// Top is how many ports and processes to rank (0 means DefaultStatsTop)
+	Top int `json:"top,omitempty"`
+}
+
+// DefaultStatsTop is how many ports and processes are ranked when StatsQuery.Top is 0.
+const DefaultStatsTop = 10
+
+// KillStats aggregates the kill history in a window. Rankings, trends and methods count
+// successful kills only; Attempts and Failed include the failures.
+type KillStats struct {
+	// Since and Until are the window the statistics cover
+	Since time.Time `json:"since,omitempty"`
+	Until time.Time `json:"until,omitempty"`
+	// Attempts is every recorded kill attempt in the window
+	Attempts int `json:"attempts"`
+	// Kills is the attempts that terminated the process
+	Kills int `json:"kills"`
+	// Failed is the attempts that failed or were cancelled
+	Failed int `json:"failed"`
+	// TopPorts are the most killed ports, most kills first
+	TopPorts []PortKillCount `json:"top_ports"`
+	// TopProcesses are the most killed process names, most kills first
+	TopProcesses []ProcessKillCount `json:"top_processes"`
+	// PerDay is the number of kills on each day that had any, oldest first
+	PerDay []DayKillCount `json:"per_day"`
+	// PerHour is the number of kills in each hour of the day (local time of the kill)
+	PerHour [24]int `json:"per_hour"`
+	// Methods counts kills by the signal (or "stop") that ended the process, most used first
+	Methods []MethodKillCount `json:"methods"`
+	// ForcedRatio is the share of kills with a recorded method that needed SIGKILL
+	ForcedRatio float64 `json:"forced_ratio"`
+	// MedianDuration is the median time from the first signal until the process was gone
+	MedianDuration time.Duration `json:"median_duration"`
+}
+
+// DefaultKillCountDays is the window PortInfo.KillCount covers when scans fill it in.
+const DefaultKillCountDays = 30
+
+// PortKillSummary is a port's kill history as shown next to it in scan results.
+type PortKillSummary struct {
+	// Port is the port number
+	Port int `json:"port"`
+	// Kills is how many successful kills of the port happened in the queried window
+	Kills int `json:"kills"`
+	// LastKilled is the last successful kill of the port, at any time (zero if never)
+	LastKilled time.Time `json:"last_killed"`
+	// Hours counts the kills in the queried window by local hour of day (nil if there were none)
+	Hours []int `json:"hours,omitempty"`
+}
+
+// Add counts a successful kill of the port at killedAt; kills before since only move LastKilled.
+func (s *PortKillSummary) Add(killedAt, since time.Time) {
+	if killedAt.After(s.LastKilled) {
+		s.LastKilled = killedAt
+	}
+	if killedAt.Before(since) {
+		return
+	}
+	s.Kills++
+	if s.Hours == nil {
+		s.Hours = make([]int, 24)
+	}
+	s.Hours[killedAt.Local().Hour()]++
+}
+
+// PortKillCount is how often a port was killed.
+type PortKillCount struct {
+	// Port is the port number
+	Port int `json:"port"`
+	// ProcessName is the process most recently killed on the port
+	ProcessName string `json:"process_name"`
+	// Kills is the number of kills
+	Kills int `json:"kills"`
+}
+
+// ProcessKillCount is how often processes with a name were killed.
+type ProcessKillCount struct {
+	// ProcessName is the process name
+	ProcessName string `json:"process_name"`
+	// Kills is the number of kills
+	Kills int `json:"kills"`
+}
+
+// DayKillCount is the number of kills on one day.
+type DayKillCount struct {
+	// Day is the date in YYYY-MM-DD form
+	Day string `json:"day"`
+	// Kills is the number of kills
+	Kills int `json:"kills"`
+}
+
+// MethodKillCount is how many kills ended with a method.
+type MethodKillCount struct {
+	// Method is the signal (e.g. "SIGTERM") or "stop" for a process manager stop
+	Method string `json:"method"`
+	// Kills is the number of kills
+	Kills int `json:"kills"`
+}
+
+// MethodKills returns how many kills ended with method.
+func (s KillStats) MethodKills(method string) int {
+	for _, m := range s.Methods {
+		if m.Method == method {
+			return m.Kills
+		}
+	}
+	return 0
+}
+
+// PortEventKind says whether a listener appeared on a port or disappeared from it.
+type PortEventKind string
+
+const (
+	// PortEventAppeared is recorded when a scan first sees a process listening on a port
+	PortEventAppeared PortEventKind = "appeared"
+	// PortEventDisappeared is recorded when a scan no longer sees a listener it saw before
+	PortEventDisappeared PortEventKind = "disappeared"
+)
+
+// PortEvent is a change in who listens on a port, recorded as scans happen.
+type PortEvent struct {
+	// ID is assigned by storage
+	ID int64 `json:"id"`
+	// Port is the port number
+	Port int `json:"port"`
+	// Kind is whether the listener appeared or disappeared
+	Kind PortEventKind `json:"kind"`
+	// PID, ProcessName and Command identify the listening process
+	PID         int    `json:"pid"`
+	ProcessName string `json:"process_name"`
+	Command     string `json:"command,omitempty"`
+	// ContainerID, ContainerName and ImageName are set for Docker listeners
+	ContainerID   string `json:"container_id,omitempty"`
+	ContainerName string `json:"container_name,omitempty"`
+	ImageName     string `json:"image_name,omitempty"`
+	// At is when the scan noticed the change
+	At time.Time `json:"at"`
+}
+
+// NewPortEvent returns the event of kind for the listener described by port.
+func NewPortEvent(kind PortEventKind, port PortInfo, at time.Time) PortEvent {
+	return PortEvent{
+		Port:          port.PortNumber,
+		Kind:          kind,
+		PID:           port.PID,
+		ProcessName:   port.ProcessName,
+		Command:       port.Command,
+		ContainerID:   port.ContainerID,
+		ContainerName: port.ContainerName,
+		ImageName:     port.ImageName,
+		At:            at,
+	}
+}
+
+// PortEventQuery selects port events.
+type PortEventQuery struct {
+	// Port limits the events to one port (0 means every port)
+	Port int `json:"port,omitempty"`
+	// Since and Until limit the events to a window; Until is exclusive (zero means no bound)
+	Since time.Time `json:"since,omitempty"`
+	Until time.Time `json:"until,omitempty"`
+}
+
+// Matches reports whether event is selected by the query.
+func (q PortEventQuery) Matches(event PortEvent) bool {
+	if q.Port != 0 && event.Port != q.Port {
+		return false
+	}
+	if !q.Since.IsZero() && event.At.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !event.At.Before(q.Until) {
+		return false
+	}
+	return true
+}
+
+// PortHolding is a span of time during which a process listened on a port.
+type PortHolding struct {
+	// Port is the port number
+	Port int `json:"port"`
+	// PID, ProcessName and Command identify the listening process
+	PID         int    `json:"pid"`
+	ProcessName string `json:"process_name"`
+	Command     string `json:"command,omitempty"`
+	// ContainerID, ContainerName and ImageName are set for Docker listeners
+	ContainerID   string `json:"container_id,omitempty"`
+	ContainerName string `json:"container_name,omitempty"`
+	ImageName     string `json:"image_name,omitempty"`
+	// From is when the listener appeared (zero if that is older than the recorded events)
+	From time.Time `json:"from,omitempty"`
+	// Until is when the listener disappeared (zero while it is still listening)
+	Until time.Time `json:"until,omitempty"`
+}
+
+// Open reports whether the listener was still there at the last scan.
+func (h PortHolding) Open() bool {
+	return h.Until.IsZero()
+}
+
+// Holdings pairs port events, oldest first, into the spans each listener held its port,
+// and returns those overlapping the window [since, until) (zero bounds are open), oldest first.
+// A listener is identified by its port and PID.
+func Holdings(events []PortEvent, since, until time.Time) []PortHolding {
+	type listener struct{ port, pid int }
+	open := make(map[listener]int)
+	var all []PortHolding
+	for _, event := range events {
+		key := listener{event.Port, event.PID}
+		switch event.Kind {
+		case PortEventAppeared:
+			if _, ok := open[key]; ok {
+				continue
+			}
+			open[key] = len(all)
+			all = append(all, PortHolding{
+				Port:          event.Port,
+				PID:           event.PID,
+				ProcessName:   event.ProcessName,
+				Command:       event.Command,
+				ContainerID:   event.ContainerID,
+				ContainerName: event.ContainerName,
+				ImageName:     event.ImageName,
+				From:          event.At,
+			})
+		case PortEventDisappeared:
+			if i, ok := open[key]; ok {
+				all[i].Until = event.At
+				delete(open, key)
+				continue
+			}
+			// Its appearance is older than the recorded events
+			all = append(all, PortHolding{
+				Port:          event.Port,
+				PID:           event.PID,
+				ProcessName:   event.ProcessName,
+				Command:       event.Command,
+				ContainerID:   event.ContainerID,
+				ContainerName: event.ContainerName,
+				ImageName:     event.ImageName,
+				Until:         event.At,
+			})
+		}
+	}
+
+	var holdings []PortHolding
+	for _, h := range all {
+		if !since.IsZero() && !h.Open() && !h.Until.After(since) {
+			continue
+		}
+		if !until.IsZero() && !h.From.IsZero() && !h.From.Before(until) {
+			continue
+		}
+		holdings = append(holdings, h)
+	}
+	return holdings
+}
+
+// PortLabel is a user's name and free-text note for a port. It applies to whatever listens on
+// the port unless narrowed to a process name, a project directory, or both.
+type PortLabel struct {
+	// Port is the labelled port number
+	Port int `json:"port"`
+	// ProcessName narrows the label to processes of this name (case-insensitive; empty for any)
+	ProcessName string `json:"process_name,omitempty"`
+	// Project narrows the label to processes working in this directory or below it (empty for any)
+	Project string `json:"project,omitempty"`
+	// Label is a short name for the port, e.g. "storefront"
+	Label string `json:"label"`
+	// Note is free text shown alongside the label
+	Note string `json:"note,omitempty"`
+	// UpdatedAt is when the label was last saved
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Normalized returns the label with its scope in canonical form: the process name lowercased,
+// the project directory cleaned, and surrounding spaces trimmed. Labels are stored this way,
+// so two labels with the same scope replace each other.
+func (l PortLabel) Normalized() PortLabel {
+	l.ProcessName = strings.ToLower(strings.TrimSpace(l.ProcessName))
+	if l.Project = strings.TrimSpace(l.Project); l.Project != "" {
+		l.Project = filepath.Clean(l.Project)
+	}
+	l.Label = strings.TrimSpace(l.Label)
+	l.Note = strings.TrimSpace(l.Note)
+	return l
+}
+
+// SameScope reports whether l and other label the same port, process name and project.
+func (l PortLabel) SameScope(other PortLabel) bool {
+	l, other = l.Normalized(), other.Normalized()
+	return l.Port == other.Port && l.ProcessName == other.ProcessName && l.Project == other.Project
+}
+
+// Matches reports whether the label applies to port.
+func (l PortLabel) Matches(port PortInfo) bool {
+	l = l.Normalized()
+	if l.Port != port.PortNumber {
+		return false
+	}
+	if l.ProcessName != "" && !strings.EqualFold(l.ProcessName, port.ProcessName) {
+		return false
+	}
+	if l.Project != "" {
+		dir := filepath.Clean(port.WorkDir)
+		if port.WorkDir == "" || (dir != l.Project && !strings.HasPrefix(dir, strings.TrimSuffix(l.Project, string(filepath.Separator))+string(filepath.Separator))) {
+			return false
+		}
+	}
+	return true
+}
+
+// Scope describes what the label is narrowed to, e.g. "node in /src/shop" ("" for the whole port).
+func (l PortLabel) Scope() string {
+	switch {
+	case l.ProcessName != "" && l.Project != "":
+		return l.ProcessName + " in " + l.Project
+	case l.ProcessName != "":
+		return l.ProcessName
+	case l.Project != "":
+		return "in " + l.Project
+	}
+	return ""
+}
+
+// specificity ranks labels for the same port: a project is more specific than a process name,
+// and both together are the most specific.
+func (l PortLabel) specificity() int {
+	n := 0
+	if l.ProcessName != "" {
+		n++
+	}
+	if l.Project != "" {
+		n += 2
+	}
+	return n
+}
+
+// LabelFor returns the most specific label in labels that applies to port.
+func LabelFor(labels []PortLabel, port PortInfo) (PortLabel, bool) {
+	var best PortLabel
+	found := false
+	for _, l := range labels {
+		if l.Matches(port) && (!found || l.specificity() > best.specificity()) {
+			best, found = l, true
+		}
+	}
+	return best, found
+}
+
+// LaunchContext is everything needed to start a process again the way it was started.
+type LaunchContext struct {
+	// Executable is the resolved path of the binary (empty to look up Args[0] on PATH)
+	Executable string `json:"executable,omitempty"`
+	// Args is the full argv, including argv[0]
+	Args []string `json:"args"`
+	// Cwd is the working directory
+	Cwd string `json:"cwd,omitempty"`
+	// Env is the environment as KEY=value pairs
+	Env []string `json:"env,omitempty"`
+}
+
+// RedactionMask replaces the secrets redacted from command lines and environments.
+const RedactionMask = "[REDACTED]"
+
+// IsRedacted reports whether secrets were masked out of the arguments or environment,
+// in which case the process can't be started again from this context.
+func (l *LaunchContext) IsRedacted() bool {
+	for _, s := range append(append([]string(nil), l.Args...), l.Env...) {
+		if strings.Contains(s, RedactionMask) {
+			return true
+		}
+	}
+	return false
+}
+
+// RestartResult reports a relaunch of a killed process.
+type RestartResult struct {
+	// PID is the process ID of the relaunched process
+	PID int `json:"pid"`
+	// Bound is true once a process listens on the port again
+	Bound bool `json:"bound"`
+	// ListenerPID is the process listening on the port (it may be a child of PID)
+	ListenerPID int `json:"listener_pid,omitempty"`
+	// LogPath is the file receiving the relaunched process's output
+	LogPath string `json:"log_path,omitempty"`
+}
+
+// RespawnInfo describes a process that took over a port shortly after its previous owner was killed.
+type RespawnInfo struct {
+	// PID is the process ID of the new listener
+	PID int `json:"pid"`
+	// ProcessName is the name of the new listener
+	ProcessName string `json:"process_name"`
+	// Supervisor names what likely restarted it (e.g. "pm2", "systemd: api.service", "parent PID 42 (bash)")
+	Supervisor string `json:"supervisor"`
+}
+
+// PortRelease reports whether a port was freed after its owner was killed.
+type PortRelease struct {
+	// Released is true once nothing listens on the port or a test bind succeeded
+	Released bool `json:"released"`
+	// HolderPID is the process still listening on the port (0 if released or unknown)
+	HolderPID int `json:"holder_pid,omitempty"`
+	// HolderName is the name of the process still listening on the port
+	HolderName string `json:"holder_name,omitempty"`
+}
+
+// ProcessMember is a process sharing a process group or session with a port's listener.
+// Group and session kills signal every member; the kill result fills in the outcome fields.
+type ProcessMember struct {
+	// PID is the member's process ID
+	PID int `json:"pid"`
+	// PGID is the member's process group ID
+	PGID int `json:"pgid"`
+	// Name is the member's process name
+	Name string `json:"name"`
+	// Command is the member's full command line
+	Command string `json:"command,omitempty"`
+	// Terminated is true if the member exited during the kill
+	Terminated bool `json:"terminated"`
+	// Signal is the signal the member exited after (empty if it survived)
+	Signal string `json:"signal,omitempty"`
+}
+
+// HookRun records one run of a configured pre-kill or post-kill hook.
+type HookRun struct {
+	// Hook is the configured name of the hook
+	Hook string `json:"hook"`
+	// Event is when the hook ran ("pre_kill" or "post_kill")
+	Event string `json:"event"`
+	// ExitCode is the command's exit status (-1 if it didn't start or timed out)
+	ExitCode int `json:"exit_code"`
+	// Output is the combined stdout and stderr, truncated to the last few kilobytes
+	Output string `json:"output,omitempty"`
+	// Error describes why the hook failed (empty on success)
+	Error string `json:"error,omitempty"`
+	// Duration is how long the hook ran
+	Duration time.Duration `json:"duration"`
+}
+
+// Failed reports whether the hook exited non-zero, timed out or couldn't start.
+func (r HookRun) Failed() bool {
+	return r.Error != ""
+}
+
 // DockerInfo contains Docker-specific metadata for a container port.
 // This is extracted when detecting that a port belongs to a Docker container.
 type DockerInfo struct {
@@ -59,6 +685,25 @@ type DockerInfo struct {
 	ImageName string `json:"image_name"`
 }
 
+// ManagerInfo contains process-manager metadata for a supervised process.
+// This is extracted when the process ancestry shows pm2, supervisord, foreman, overmind or nodemon.
+type ManagerInfo struct {
+	// Manager is the process manager name (pm2, supervisord, foreman, overmind, nodemon)
+	Manager string `json:"manager"`
+	// ManagedName is the app/program name known to the manager (empty if unknown)
+	ManagedName string `json:"managed_name"`
+	// ManagerPID is the PID of the manager process found in the ancestry chain
+	ManagerPID int `json:"manager_pid"`
+	// StopCommand is the manager-native command that stops the process (nil if none is known)
+	StopCommand []string `json:"stop_command"`
+}
+
+// IsManaged returns true if this port's process is supervised by a process manager.
+// Killing a managed process usually just makes the manager respawn it.
+func (p *PortInfo) IsManaged() bool {
+	return p.Manager != ""
+}
+
 // IsCommonPort returns true if this port is commonly used for development.
 // Common ports include: 80 (HTTP), 443 (HTTPS), 3000, 5000, 8000, 8080 (common dev servers).
 func (p *PortInfo) IsCommonPort() bool {
@@ -69,14 +714,26 @@ func (p *PortInfo) IsCommonPort() bool {
 	return commonPorts[p.PortNumber]
 }
 
-// IsRecommended returns true if this port has been killed 3 or more times.
-// Frequently killed ports might be candidates for the user's attention.
+// IsRecommended returns true if this port's recommendation score reaches RecommendThreshold.
+// Ports that weren't scored fall back to having been killed 3 or more times.
 func (p *PortInfo) IsRecommended() bool {
+	if p.Recommendation != nil {
+		return p.Recommendation.Score >= RecommendThreshold
+	}
 	return p.KillCount >= 3
 }
 
+// RecommendationScore returns the port's recommendation score (0 if it wasn't scored).
+func (p *PortInfo) RecommendationScore() float64 {
+	if p.Recommendation == nil {
+		return 0
+	}
+	return p.Recommendation.Score
+}
+
 // ShouldDisplayWarning returns true if this port should display a warning before killing.
 // System processes and low-PID processes (<100) are considered potentially dangerous.
+// It is only a fallback for when no protection policy is configured; policy.Evaluate decides otherwise.
 func (p *PortInfo) ShouldDisplayWarning() bool {
 	return p.IsSystem || p.PID < 100
 }
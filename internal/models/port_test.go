@@ -1,6 +1,8 @@
 package models
 
 import (
+	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -56,6 +58,51 @@ func TestPortInfo_IsRecommended(t *testing.T) {
 	}
 }
 
+func TestPortInfo_IsRecommended_Scored(t *testing.T) {
+	tests := []struct {
+		name      string
+		killCount int
+		score     float64
+		want      bool
+	}{
+		{"score at the threshold", 0, RecommendThreshold, true},
+		{"score above the threshold", 1, 75, true},
+		{"score below the threshold despite many kills", 9, RecommendThreshold - 1, false},
+		{"zero score", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &PortInfo{KillCount: tt.killCount, Recommendation: &Recommendation{Score: tt.score}}
+			if got := p.IsRecommended(); got != tt.want {
+				t.Errorf("PortInfo.IsRecommended() = %v, want %v", got, tt.want)
+			}
+			if got := p.RecommendationScore(); got != tt.score {
+				t.Errorf("PortInfo.RecommendationScore() = %v, want %v", got, tt.score)
+			}
+		})
+	}
+}
+
+func TestPortKillSummary_Add(t *testing.T) {
+	now := time.Now()
+	since := now.AddDate(0, 0, -30)
+	var s PortKillSummary
+	s.Add(now.AddDate(0, 0, -40), since)
+	if s.Kills != 0 || s.Hours != nil || !s.LastKilled.Equal(now.AddDate(0, 0, -40)) {
+		t.Errorf("after an old kill = %+v, want only LastKilled set", s)
+	}
+
+	s.Add(now, since)
+	s.Add(now.Add(-time.Hour), since)
+	if s.Kills != 2 || !s.LastKilled.Equal(now) {
+		t.Errorf("after two recent kills = %+v, want 2 kills, the last now", s)
+	}
+	if s.Hours[now.Hour()] == 0 || s.Hours[now.Add(-time.Hour).Hour()] == 0 {
+		t.Errorf("Hours = %v, want the recent kills counted by hour", s.Hours)
+	}
+}
+
 func TestPortInfo_ShouldDisplayWarning(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -163,6 +210,139 @@ func TestHistoryEntry_Fields(t *testing.T) {
 	}
 }
 
+func TestHistoryQuery_Matches(t *testing.T) {
+	now := time.Now()
+	entry := HistoryEntry{PortNumber: 3000, ProcessName: "node", Command: "npm run Dev", KilledAt: now}
+	failed := entry
+	failed.Failed = true
+
+	tests := []struct {
+		name  string
+		query HistoryQuery
+		entry HistoryEntry
+		want  bool
+	}{
+		{"empty query", HistoryQuery{}, entry, true},
+		{"port", HistoryQuery{Port: 3000}, entry, true},
+		{"other port", HistoryQuery{Port: 8080}, entry, false},
+		{"port range", HistoryQuery{PortMin: 3000, PortMax: 3999}, entry, true},
+		{"below range", HistoryQuery{PortMin: 3001}, entry, false},
+		{"above range", HistoryQuery{PortMax: 2999}, entry, false},
+		{"process name ignores case", HistoryQuery{ProcessName: "NODE"}, entry, true},
+		{"process name is exact", HistoryQuery{ProcessName: "nod"}, entry, false},
+		{"command substring", HistoryQuery{Command: "run dev"}, entry, true},
+		{"since", HistoryQuery{Since: now.Add(-time.Minute)}, entry, true},
+		{"before since", HistoryQuery{Since: now.Add(time.Minute)}, entry, false},
+		{"until is exclusive", HistoryQuery{Until: now}, entry, false},
+		{"succeeded", HistoryQuery{Status: StatusSucceeded}, entry, true},
+		{"succeeded excludes failures", HistoryQuery{Status: StatusSucceeded}, failed, false},
+		{"failed", HistoryQuery{Status: StatusFailed}, failed, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.Matches(tt.entry); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHoldings(t *testing.T) {
+	now := time.Now()
+	node := PortInfo{PortNumber: 3000, PID: 101, ProcessName: "node", Command: "node server.js"}
+	vite := PortInfo{PortNumber: 3000, PID: 202, ProcessName: "vite"}
+	java := PortInfo{PortNumber: 8080, PID: 303, ProcessName: "java"}
+	events := []PortEvent{
+		// java was already listening when recording started
+		NewPortEvent(PortEventDisappeared, java, now.Add(-5*time.Hour)),
+		NewPortEvent(PortEventAppeared, node, now.Add(-4*time.Hour)),
+		NewPortEvent(PortEventDisappeared, node, now.Add(-3*time.Hour)),
+		NewPortEvent(PortEventAppeared, vite, now.Add(-2*time.Hour)),
+	}
+
+	all := Holdings(events, time.Time{}, time.Time{})
+	if len(all) != 3 {
+		t.Fatalf("Holdings() = %+v, want three", all)
+	}
+	if !all[0].From.IsZero() || !all[0].Until.Equal(now.Add(-5*time.Hour)) || all[0].ProcessName != "java" {
+		t.Errorf("holding without an appearance = %+v, want a zero From", all[0])
+	}
+	if !all[1].From.Equal(now.Add(-4*time.Hour)) || !all[1].Until.Equal(now.Add(-3*time.Hour)) || all[1].Command != "node server.js" {
+		t.Errorf("node holding = %+v", all[1])
+	}
+	if !all[2].Open() || all[2].ProcessName != "vite" {
+		t.Errorf("vite holding = %+v, want it still open", all[2])
+	}
+
+	tests := []struct {
+		name         string
+		since, until time.Time
+		want         []string
+	}{
+		{"overlapping the start", now.Add(-210 * time.Minute), time.Time{}, []string{"node", "vite"}},
+		{"ended exactly at since", now.Add(-3 * time.Hour), time.Time{}, []string{"vite"}},
+		{"before the current listener", time.Time{}, now.Add(-2 * time.Hour), []string{"java", "node"}},
+		{"between listeners", now.Add(-170 * time.Minute), now.Add(-130 * time.Minute), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, h := range Holdings(events, tt.since, tt.until) {
+				got = append(got, h.ProcessName)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Holdings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabelFor(t *testing.T) {
+	labels := []PortLabel{
+		{Port: 3000, Label: "dev server"},
+		{Port: 3000, ProcessName: "Node", Label: "node app"},
+		{Port: 3000, Project: "/src/shop/", Label: "storefront"},
+		{Port: 3000, ProcessName: "node", Project: "/src/shop", Label: "storefront api"},
+		{Port: 5433, ProcessName: "ssh", Label: "staging DB tunnel"},
+	}
+
+	tests := []struct {
+		name string
+		port PortInfo
+		want string
+	}{
+		{"port only", PortInfo{PortNumber: 3000, ProcessName: "python"}, "dev server"},
+		{"process name ignores case", PortInfo{PortNumber: 3000, ProcessName: "NODE"}, "node app"},
+		{"project", PortInfo{PortNumber: 3000, ProcessName: "vite", WorkDir: "/src/shop/web"}, "storefront"},
+		{"project prefix is a directory", PortInfo{PortNumber: 3000, ProcessName: "vite", WorkDir: "/src/shopping"}, "dev server"},
+		{"process and project", PortInfo{PortNumber: 3000, ProcessName: "node", WorkDir: "/src/shop"}, "storefront api"},
+		{"scoped to another process", PortInfo{PortNumber: 5433, ProcessName: "postgres"}, ""},
+		{"no label", PortInfo{PortNumber: 8080}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LabelFor(labels, tt.port)
+			if ok != (tt.want != "") || got.Label != tt.want {
+				t.Errorf("LabelFor() = %q, %v; want %q", got.Label, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestPortLabel_Normalized(t *testing.T) {
+	l := PortLabel{Port: 3000, ProcessName: " Node ", Project: "/src/shop/", Label: " storefront "}.Normalized()
+	if l.ProcessName != "node" || l.Project != filepath.Clean("/src/shop") || l.Label != "storefront" {
+		t.Errorf("Normalized() = %+v", l)
+	}
+	if !l.SameScope(PortLabel{Port: 3000, ProcessName: "NODE", Project: "/src/shop"}) {
+		t.Error("labels differing only in case and trailing separators should share a scope")
+	}
+	if l.Scope() != "node in "+filepath.Clean("/src/shop") {
+		t.Errorf("Scope() = %q", l.Scope())
+	}
+}
+
 func TestDockerInfo_Fields(t *testing.T) {
 	d := DockerInfo{
 		ContainerID:   "abc123",
@@ -170,6 +170,23 @@ func GetHistoryPath() string {
 	return filepath.Join(GetDataPath(), "history.db")
 }
 
+// GetLegacyDataPath returns ~/.port-chaser, where versions before the platform data
+// directory kept the history database.
+func GetLegacyDataPath() string {
+	return filepath.Join(NewManager().HomeDir(), "."+GetAppName())
+}
+
+// GetUserName returns the name of the user running port-chaser ("" if it can't be determined).
+func GetUserName() string {
+	if usr, err := user.Current(); err == nil && usr.Username != "" {
+		return usr.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
+
 func NormalizePath(path string) string {
 	if strings.HasPrefix(path, "~/") {
 		home := os.Getenv("HOME")
@@ -1,6 +1,8 @@
 package platform
 
 import (
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -212,6 +214,32 @@ func TestGetHistoryPath(t *testing.T) {
 	}
 }
 
+func TestGetHistoryPath_XDG(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG_DATA_HOME only applies on Linux")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+	if got, want := GetHistoryPath(), filepath.Join(home, "data", "port-chaser", "history.db"); got != want {
+		t.Errorf("GetHistoryPath() = %s, want %s", got, want)
+	}
+	t.Setenv("XDG_DATA_HOME", "")
+	if got, want := GetHistoryPath(), filepath.Join(home, ".local", "share", "port-chaser", "history.db"); got != want {
+		t.Errorf("GetHistoryPath() without XDG_DATA_HOME = %s, want %s", got, want)
+	}
+	if got, want := GetLegacyDataPath(), filepath.Join(home, ".port-chaser"); got != want {
+		t.Errorf("GetLegacyDataPath() = %s, want %s", got, want)
+	}
+}
+
+func TestGetUserName(t *testing.T) {
+	if name := GetUserName(); name == "" {
+		t.Error("GetUserName() returned empty string")
+	}
+}
+
 func TestNormalizePath(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,244 @@
+// Package policy decides how carefully a process must be handled before it is killed.
+// Rules match processes by name, owner, port, executable path and PID, and each carries an action:
+// block the kill, require the user to type the process name, or just warn.
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// Action is what a matching rule requires before a kill.
+type Action string
+
+const (
+	// ActionAllow lets the kill proceed without ceremony; use it for exceptions listed before broader rules
+	ActionAllow Action = "allow"
+	// ActionWarn shows a warning in the confirmation dialog
+	ActionWarn Action = "warn"
+	// ActionConfirm requires typing the process name to confirm the kill
+	ActionConfirm Action = "confirm"
+	// ActionBlock refuses the kill
+	ActionBlock Action = "block"
+)
+
+// ParseAction parses an action name ("allow", "warn", "confirm" or "block").
+func ParseAction(name string) (Action, error) {
+	switch action := Action(strings.ToLower(strings.TrimSpace(name))); action {
+	case ActionAllow, ActionWarn, ActionConfirm, ActionBlock:
+		return action, nil
+	}
+	return "", fmt.Errorf("unknown policy action %q (want allow, warn, confirm or block)", name)
+}
+
+// UnmarshalJSON accepts any action ParseAction accepts.
+func (a *Action) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid policy action %s", string(data))
+	}
+	action, err := ParseAction(s)
+	if err != nil {
+		return err
+	}
+	*a = action
+	return nil
+}
+
+// Range is an inclusive range of ports or PIDs. In JSON it is a number (22) or a string ("1-1023").
+type Range struct {
+	Min, Max int
+}
+
+// ParseRange parses "22" or "1-1023".
+func ParseRange(s string) (Range, error) {
+	lo, hi, isRange := strings.Cut(strings.TrimSpace(s), "-")
+	min, err := strconv.Atoi(strings.TrimSpace(lo))
+	if err != nil {
+		return Range{}, fmt.Errorf("invalid range %q", s)
+	}
+	if !isRange {
+		return Range{Min: min, Max: min}, nil
+	}
+	max, err := strconv.Atoi(strings.TrimSpace(hi))
+	if err != nil || max < min {
+		return Range{}, fmt.Errorf("invalid range %q", s)
+	}
+	return Range{Min: min, Max: max}, nil
+}
+
+// Contains reports whether n lies within the range.
+func (r Range) Contains(n int) bool {
+	return n >= r.Min && n <= r.Max
+}
+
+// String formats the range as ParseRange accepts it.
+func (r Range) String() string {
+	if r.Min == r.Max {
+		return strconv.Itoa(r.Min)
+	}
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+// MarshalJSON encodes single values as numbers and ranges as strings.
+func (r Range) MarshalJSON() ([]byte, error) {
+	if r.Min == r.Max {
+		return json.Marshal(r.Min)
+	}
+	return json.Marshal(r.String())
+}
+
+// UnmarshalJSON accepts a number or a "min-max" string.
+func (r *Range) UnmarshalJSON(data []byte) error {
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil {
+		*r = Range{Min: n, Max: n}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid range %s", string(data))
+	}
+	parsed, err := ParseRange(s)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// Rule protects the processes it matches. Every non-empty criterion must match (a root-owned
+// process on a privileged port, say); within a criterion any listed value may match.
+// A rule without criteria matches every process.
+type Rule struct {
+	Name         string   `json:"name"`
+	Action       Action   `json:"action"`
+	ProcessNames []string `json:"process_names,omitempty"`
+	Users        []string `json:"users,omitempty"`
+	Ports        []Range  `json:"ports,omitempty"`
+	// Executables are path globs such as "/usr/sbin/*"
+	Executables []string `json:"executables,omitempty"`
+	PIDs        []Range  `json:"pids,omitempty"`
+}
+
+// Matches reports whether the rule applies to the port's process.
+func (r Rule) Matches(port *models.PortInfo) bool {
+	if len(r.ProcessNames) > 0 && !matchFold(r.ProcessNames, port.ProcessName) {
+		return false
+	}
+	if len(r.Users) > 0 && !matchFold(r.Users, port.User) {
+		return false
+	}
+	if len(r.Ports) > 0 && !inRanges(r.Ports, port.PortNumber) {
+		return false
+	}
+	if len(r.Executables) > 0 && !matchGlob(r.Executables, port.Executable) {
+		return false
+	}
+	if len(r.PIDs) > 0 && !inRanges(r.PIDs, port.PID) {
+		return false
+	}
+	return true
+}
+
+// matchFold reports whether value equals any of candidates, ignoring case.
+func matchFold(candidates []string, value string) bool {
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// inRanges reports whether n lies in any of ranges.
+func inRanges(ranges []Range, n int) bool {
+	for _, r := range ranges {
+		if r.Contains(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob reports whether path matches any of patterns; an unknown path matches nothing.
+func matchGlob(patterns []string, path string) bool {
+	if path == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if ok, err := filepath.Match(pattern, path); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRules replaces the old "system port or PID < 100" heuristic: low PIDs and privileged
+// ports need a typed confirmation instead of being unkillable, and sshd is blocked so a remote
+// session can't be cut off by accident. Processes owned by root get a warning.
+func DefaultRules() []Rule {
+	return []Rule{
+		{Name: "sshd", Action: ActionBlock, ProcessNames: []string{"sshd", "sshd-session"}},
+		{Name: "low-pid", Action: ActionConfirm, PIDs: []Range{{Min: 1, Max: 99}}},
+		{Name: "privileged-port", Action: ActionConfirm, Ports: []Range{{Min: 1, Max: 1023}}},
+		{Name: "root-owned", Action: ActionWarn, Users: []string{"root", "SYSTEM"}},
+	}
+}
+
+// Decision is the outcome of evaluating a policy for one process.
+type Decision struct {
+	// Action is what the matching rule requires (ActionAllow if no rule matched)
+	Action Action
+	// Rule is the name of the matching rule (empty if none)
+	Rule string
+}
+
+// Permits reports whether a kill may proceed; confirmed is true once the user typed the confirmation.
+func (d Decision) Permits(confirmed bool) bool {
+	switch d.Action {
+	case ActionBlock:
+		return false
+	case ActionConfirm:
+		return confirmed
+	}
+	return true
+}
+
+// Policy is an ordered list of rules; the first matching rule decides.
+type Policy struct {
+	Rules []Rule
+}
+
+// New creates a policy from rules checked in order.
+func New(rules ...Rule) *Policy {
+	return &Policy{Rules: rules}
+}
+
+// Default creates a policy with DefaultRules.
+func Default() *Policy {
+	return New(DefaultRules()...)
+}
+
+// Evaluate returns the decision of the first rule matching the port's process.
+// A nil port or no matching rule allows the kill.
+func (p *Policy) Evaluate(port *models.PortInfo) Decision {
+	if p == nil || port == nil {
+		return Decision{Action: ActionAllow}
+	}
+	for _, rule := range p.Rules {
+		if rule.Matches(port) {
+			action := rule.Action
+			if action == "" {
+				action = ActionAllow
+			}
+			return Decision{Action: action, Rule: rule.Name}
+		}
+	}
+	return Decision{Action: ActionAllow}
+}
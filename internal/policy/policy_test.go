@@ -0,0 +1,138 @@
+package policy
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+func TestPolicy_DefaultRules(t *testing.T) {
+	tests := []struct {
+		name string
+		port models.PortInfo
+		want Decision
+	}{
+		{"container app as PID 1", models.PortInfo{PortNumber: 3000, PID: 1, ProcessName: "node", User: "app"},
+			Decision{Action: ActionConfirm, Rule: "low-pid"}},
+		{"root sshd with a high PID", models.PortInfo{PortNumber: 22, PID: 900, ProcessName: "sshd", User: "root"},
+			Decision{Action: ActionBlock, Rule: "sshd"}},
+		{"privileged port", models.PortInfo{PortNumber: 80, PID: 4242, ProcessName: "nginx", User: "www-data"},
+			Decision{Action: ActionConfirm, Rule: "privileged-port"}},
+		{"root-owned dev server", models.PortInfo{PortNumber: 8080, PID: 4242, ProcessName: "java", User: "root"},
+			Decision{Action: ActionWarn, Rule: "root-owned"}},
+		{"ordinary dev server", models.PortInfo{PortNumber: 5173, PID: 4242, ProcessName: "node", User: "dev"},
+			Decision{Action: ActionAllow}},
+	}
+
+	policy := Default()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Evaluate(&tt.port); got != tt.want {
+				t.Errorf("Evaluate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRule_Matches(t *testing.T) {
+	rule := Rule{
+		Action:       ActionBlock,
+		ProcessNames: []string{"Postgres"},
+		Users:        []string{"postgres"},
+		Ports:        []Range{{Min: 5432, Max: 5439}},
+		Executables:  []string{"/usr/lib/postgresql/*/bin/postgres"},
+	}
+	port := models.PortInfo{
+		PortNumber:  5433,
+		PID:         812,
+		ProcessName: "postgres",
+		User:        "postgres",
+		Executable:  "/usr/lib/postgresql/16/bin/postgres",
+	}
+	if !rule.Matches(&port) {
+		t.Error("rule should match when every criterion matches")
+	}
+
+	other := port
+	other.User = "dev"
+	if rule.Matches(&other) {
+		t.Error("rule should not match when one criterion fails")
+	}
+
+	other = port
+	other.Executable = ""
+	if rule.Matches(&other) {
+		t.Error("an unknown executable should not match an executable glob")
+	}
+
+	if !(Rule{Action: ActionWarn}).Matches(&port) {
+		t.Error("a rule without criteria should match everything")
+	}
+}
+
+func TestPolicy_FirstMatchWins(t *testing.T) {
+	// An allow exception listed first overrides the default PID rule for a containerised app
+	policy := New(append([]Rule{{Name: "my-app", Action: ActionAllow, ProcessNames: []string{"node"}}}, DefaultRules()...)...)
+
+	got := policy.Evaluate(&models.PortInfo{PortNumber: 3000, PID: 1, ProcessName: "node"})
+	if got.Action != ActionAllow || got.Rule != "my-app" {
+		t.Errorf("Evaluate() = %+v, want the allow exception", got)
+	}
+	if d := (*Policy)(nil).Evaluate(&models.PortInfo{PID: 1}); d.Action != ActionAllow {
+		t.Errorf("nil policy Evaluate() = %+v, want allow", d)
+	}
+}
+
+func TestDecision_Permits(t *testing.T) {
+	tests := []struct {
+		action                 Action
+		unconfirmed, confirmed bool
+	}{
+		{ActionAllow, true, true},
+		{ActionWarn, true, true},
+		{ActionConfirm, false, true},
+		{ActionBlock, false, false},
+	}
+	for _, tt := range tests {
+		d := Decision{Action: tt.action}
+		if d.Permits(false) != tt.unconfirmed || d.Permits(true) != tt.confirmed {
+			t.Errorf("%s: Permits(false/true) = %v/%v, want %v/%v",
+				tt.action, d.Permits(false), d.Permits(true), tt.unconfirmed, tt.confirmed)
+		}
+	}
+}
+
+func TestRule_JSON(t *testing.T) {
+	data := `{"name": "infra", "action": "Confirm", "ports": [22, "8000-8999"], "pids": ["1-10"]}`
+
+	var rule Rule
+	if err := json.Unmarshal([]byte(data), &rule); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := Rule{
+		Name:   "infra",
+		Action: ActionConfirm,
+		Ports:  []Range{{Min: 22, Max: 22}, {Min: 8000, Max: 8999}},
+		PIDs:   []Range{{Min: 1, Max: 10}},
+	}
+	if !reflect.DeepEqual(rule, want) {
+		t.Errorf("rule = %+v, want %+v", rule, want)
+	}
+
+	encoded, err := json.Marshal(rule.Ports)
+	if err != nil || string(encoded) != `[22,"8000-8999"]` {
+		t.Errorf("Marshal(ports) = %s, %v", encoded, err)
+	}
+
+	for _, bad := range []string{
+		`{"action": "nuke"}`,
+		`{"action": "warn", "ports": ["9000-80"]}`,
+		`{"action": "warn", "pids": ["one"]}`,
+	} {
+		if err := json.Unmarshal([]byte(bad), &rule); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded, want error", bad)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// ErrStaleTarget is returned when the PID no longer belongs to the process that was scanned,
+// i.e. the original process exited and the PID was recycled.
+var ErrStaleTarget = errors.New("stale target")
+
+// startTimeTolerance absorbs rounding in how start times are derived (boot time + clock ticks on Linux).
+const startTimeTolerance = time.Second
+
+// identityLookup returns the start time and executable of a running process.
+// It is a variable so tests can simulate a recycled PID.
+var identityLookup = processIdentity
+
+// processIdentity reads a process's start time and executable path.
+// The executable is empty if it can't be read (e.g. another user's process).
+func processIdentity(pid int) (time.Time, string, error) {
+	p, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	created, err := p.CreateTime()
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	exe, _ := p.Exe()
+	return time.UnixMilli(created), exe, nil
+}
+
+// staleReason checks that pid still belongs to the process described by portInfo.
+// It compares the start time and executable captured at scan time with the live process and
+// explains the mismatch, or returns "" if they match. Missing identity data on either side
+// is not a mismatch, so ports scanned without identity are never refused.
+func staleReason(pid int, portInfo *models.PortInfo) string {
+	if portInfo == nil || portInfo.PID != pid {
+		return ""
+	}
+	if portInfo.StartTime.IsZero() && portInfo.Executable == "" {
+		return ""
+	}
+
+	started, exe, err := identityLookup(pid)
+	if err != nil {
+		// The process may have exited just now; the caller's signalling reports that
+		return ""
+	}
+
+	if !portInfo.StartTime.IsZero() {
+		diff := started.Sub(portInfo.StartTime)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > startTimeTolerance {
+			return fmt.Sprintf("PID %d was started at %s, but the scanned process started at %s",
+				pid, started.Format("15:04:05"), portInfo.StartTime.Format("15:04:05"))
+		}
+	}
+
+	if portInfo.Executable != "" && exe != "" && cleanExe(exe) != cleanExe(portInfo.Executable) {
+		return fmt.Sprintf("PID %d now runs %s, not %s", pid, exe, portInfo.Executable)
+	}
+
+	return ""
+}
+
+// cleanExe strips the " (deleted)" suffix Linux adds when a running binary was replaced on disk,
+// so an upgraded-in-place server isn't mistaken for a different process.
+func cleanExe(exe string) string {
+	return strings.TrimSuffix(exe, " (deleted)")
+}
@@ -0,0 +1,79 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+func TestStaleReason(t *testing.T) {
+	started := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	identityLookup = func(pid int) (time.Time, string, error) {
+		return started, "/usr/bin/node", nil
+	}
+	defer func() { identityLookup = processIdentity }()
+
+	tests := []struct {
+		name  string
+		port  *models.PortInfo
+		stale bool
+	}{
+		{"matching identity", &models.PortInfo{PID: 42, StartTime: started.Add(300 * time.Millisecond), Executable: "/usr/bin/node"}, false},
+		{"no identity captured", &models.PortInfo{PID: 42}, false},
+		{"nil port", nil, false},
+		{"different PID", &models.PortInfo{PID: 43, StartTime: started.Add(time.Hour)}, false},
+		{"binary replaced in place", &models.PortInfo{PID: 42, Executable: "/usr/bin/node (deleted)"}, false},
+		{"recycled PID", &models.PortInfo{PID: 42, StartTime: started.Add(-time.Minute), Executable: "/usr/bin/node"}, true},
+		{"different executable", &models.PortInfo{PID: 42, StartTime: started, Executable: "/usr/bin/python3"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := staleReason(42, tt.port)
+			if got := reason != ""; got != tt.stale {
+				t.Errorf("staleReason() = %q, want stale %v", reason, tt.stale)
+			}
+		})
+	}
+}
+
+func TestProcessKiller_RefusesStaleTarget(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start test process: %v", err)
+	}
+	go cmd.Wait()
+	defer cmd.Process.Kill()
+
+	started, exe, err := processIdentity(cmd.Process.Pid)
+	if err != nil {
+		t.Skipf("cannot read process identity: %v", err)
+	}
+
+	killer := NewProcessKiller()
+	killer.SystemProcessProtection = false
+	killer.ReleaseTimeout = 0
+
+	// The scanned process started an hour earlier: this PID has been recycled since
+	stale := &models.PortInfo{PID: cmd.Process.Pid, StartTime: started.Add(-time.Hour), Executable: exe}
+	result, err := killer.Kill(context.Background(), cmd.Process.Pid, stale)
+	if !errors.Is(err, ErrStaleTarget) {
+		t.Fatalf("Kill() error = %v, want ErrStaleTarget", err)
+	}
+	if result.Success || result.Method != KillMethodStale {
+		t.Errorf("result = %+v, want a failed STALE result", result)
+	}
+	if running, _ := killer.IsRunning(cmd.Process.Pid); !running {
+		t.Fatal("stale target must not be signalled")
+	}
+
+	fresh := &models.PortInfo{PID: cmd.Process.Pid, StartTime: started, Executable: exe}
+	result, err = killer.Kill(context.Background(), cmd.Process.Pid, fresh)
+	if err != nil || !result.Success {
+		t.Errorf("Kill() with matching identity = %+v, %v", result, err)
+	}
+}
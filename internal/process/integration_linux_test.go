@@ -0,0 +1,118 @@
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// forEachBackend runs fn once with the pidfd backend and once with the PID-based fallback.
+func forEachBackend(t *testing.T, fn func(t *testing.T)) {
+	backends := []struct {
+		name  string
+		pidfd bool
+	}{
+		{"pidfd", true},
+		{"fallback", false},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			pidfdEnabled = backend.pidfd
+			defer func() { pidfdEnabled = true }()
+
+			proc, err := findProcess(1)
+			if err != nil {
+				t.Skipf("cannot open a process handle: %v", err)
+			}
+			proc.Release()
+			if _, isPidfd := proc.(*pidfdProcess); isPidfd != backend.pidfd {
+				t.Skipf("pidfd backend unavailable on this kernel")
+			}
+
+			fn(t)
+		})
+	}
+}
+
+func TestIntegration_Backend_SIGTERM(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test (-short)")
+	}
+
+	forEachBackend(t, func(t *testing.T) {
+		pid, cmd := spawnTestProcess(t)
+		// Deliberately not reaped until the end: the child stays a zombie after it dies
+		defer cmd.Wait()
+
+		killer := NewProcessKiller()
+		result, err := killer.Kill(context.Background(), pid, &models.PortInfo{PID: pid})
+		if err != nil || !result.Success {
+			t.Fatalf("Kill() = %+v, %v", result, err)
+		}
+		if result.Method != KillMethodSIGTERM {
+			t.Errorf("Method = %s, want SIGTERM", result.Method)
+		}
+		if result.Duration > time.Second {
+			t.Errorf("Duration = %v, an exited zombie should be noticed immediately", result.Duration)
+		}
+		if running, _ := killer.IsRunning(pid); running {
+			t.Error("zombie reported as running")
+		}
+	})
+}
+
+func TestIntegration_Backend_SIGKILLEscalation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test (-short)")
+	}
+
+	forEachBackend(t, func(t *testing.T) {
+		pid, cmd := spawnTermIgnoringProcess(t)
+		defer cmd.Wait()
+
+		killer := NewProcessKillerWithGracePeriod(300 * time.Millisecond)
+		result, err := killer.Kill(context.Background(), pid, &models.PortInfo{PID: pid})
+		if err != nil || !result.Success {
+			t.Fatalf("Kill() = %+v, %v", result, err)
+		}
+		if result.Method != KillMethodSIGKILL {
+			t.Errorf("Method = %s, want SIGKILL", result.Method)
+		}
+	})
+}
+
+func TestPidfdProcess_SignalAfterExit(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start test process: %v", err)
+	}
+
+	proc, err := findProcess(cmd.Process.Pid)
+	if err != nil {
+		t.Fatalf("findProcess() error = %v", err)
+	}
+	defer proc.Release()
+	if _, ok := proc.(*pidfdProcess); !ok {
+		cmd.Process.Kill()
+		cmd.Wait()
+		t.Skip("pidfd backend unavailable on this kernel")
+	}
+
+	cmd.Process.Kill()
+	cmd.Wait()
+
+	// Once reaped the PID may be reused, but the pidfd still refers to the dead process
+	if err := proc.Signal(0); err == nil {
+		t.Error("signalling a reaped process through its pidfd should fail")
+	}
+	if exited, _ := proc.Wait(context.Background(), 0); !exited {
+		t.Error("Wait() should report the process as exited")
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+package process
+
+import "testing"
+
+// forEachBackend runs fn once: outside Linux there is only the PID-based backend.
+func forEachBackend(t *testing.T, fn func(t *testing.T)) {
+	fn(t)
+}
@@ -5,6 +5,7 @@ package process
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"sync"
@@ -25,6 +26,21 @@ func spawnTestProcess(t *testing.T) (int, *exec.Cmd) {
 	return cmd.Process.Pid, cmd
 }
 
+// spawnTermIgnoringProcess starts a shell that ignores SIGTERM, so a kill has to wait out
+// its grace period. The caller must kill and reap it.
+func spawnTermIgnoringProcess(t *testing.T) (int, *exec.Cmd) {
+	t.Helper()
+
+	cmd := exec.Command("sh", "-c", "trap '' TERM; while :; do sleep 1; done")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start test process: %v", err)
+	}
+	// Let the shell install its trap
+	time.Sleep(100 * time.Millisecond)
+
+	return cmd.Process.Pid, cmd
+}
+
 func TestIntegration_SIGTERM(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test (-short)")
@@ -375,39 +391,45 @@ func TestIntegration_ContextTimeout(t *testing.T) {
 		t.Skip("skipping integration test (-short)")
 	}
 
-	pid, cmd := spawnTestProcess(t)
-	defer cmd.Wait()
+	// The process survives SIGTERM, so the context expires during the grace period
+	forEachBackend(t, func(t *testing.T) {
+		pid, cmd := spawnTermIgnoringProcess(t)
+		defer func() {
+			cmd.Process.Kill()
+			cmd.Wait()
+		}()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
-	defer cancel()
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
 
-	killer := NewProcessKillerWithGracePeriod(10 * time.Second)
-	portInfo := &models.PortInfo{
-		PID:      pid,
-		IsSystem: false,
-	}
+		killer := NewProcessKillerWithGracePeriod(10 * time.Second)
+		portInfo := &models.PortInfo{
+			PID:      pid,
+			IsSystem: false,
+		}
 
-	start := time.Now()
-	result, err := killer.Kill(ctx, pid, portInfo)
-	elapsed := time.Since(start)
+		start := time.Now()
+		result, err := killer.Kill(ctx, pid, portInfo)
+		elapsed := time.Since(start)
 
-	if err == nil {
-		t.Error("context timeout should return error")
-	}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("context timeout should return context.DeadlineExceeded: got=%v", err)
+		}
 
-	if result.Method != KillMethodFailed {
-		t.Errorf("should be FAILED on timeout: got=%v", result.Method)
-	}
+		if result.Success || result.Method != KillMethodFailed {
+			t.Errorf("should be FAILED on timeout: got=%v", result.Method)
+		}
 
-	if result.Message != "operation canceled" {
-		t.Errorf("cancel message different from expected: got=%v", result.Message)
-	}
+		if result.Message != "Operation cancelled" {
+			t.Errorf("cancel message different from expected: got=%v", result.Message)
+		}
 
-	if elapsed > 200*time.Millisecond {
-		t.Errorf("timeout too long: %v", elapsed)
-	}
+		if elapsed > 200*time.Millisecond {
+			t.Errorf("timeout too long: %v", elapsed)
+		}
 
-	t.Logf("context timeout: Elapsed=%v, Message=%v", elapsed, result.Message)
+		t.Logf("context timeout: Elapsed=%v, Message=%v", elapsed, result.Message)
+	})
 }
 
 func TestIntegration_SignalZero(t *testing.T) {
@@ -7,50 +7,83 @@ import (
 	"time"
 
 	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/policy"
 )
 
 // KillResult contains the outcome of a process termination attempt.
 // It provides detailed information about how the process was terminated.
 type KillResult struct {
 	Success  bool          // true if process was terminated successfully
-	Method   KillMethod    // termination method used (SIGTERM, SIGKILL, or FAILED)
+	Method   KillMethod    // signal that terminated the process (or FAILED)
+	Strategy string        // name of the kill strategy that was applied
 	Message  string        // human-readable result message
 	Duration time.Duration // time taken to terminate the process
+	// Members lists every signalled process and its outcome for group and session kills (nil otherwise)
+	Members []models.ProcessMember
+	// Release reports whether the port was freed after a successful kill (nil if not checked)
+	Release *models.PortRelease
 }
 
 // KillMethod represents the method used to terminate a process.
+// For signal-based kills it is the signal name, e.g. "SIGTERM" or "SIGINT".
 type KillMethod string
 
 const (
 	KillMethodSIGTERM KillMethod = "SIGTERM" // graceful termination signal
+	KillMethodSIGINT  KillMethod = "SIGINT"  // interrupt (PostgreSQL fast shutdown)
+	KillMethodSIGQUIT KillMethod = "SIGQUIT" // quit (nginx graceful shutdown)
+	KillMethodSIGHUP  KillMethod = "SIGHUP"  // hangup
 	KillMethodSIGKILL KillMethod = "SIGKILL" // force termination signal
 	KillMethodFailed  KillMethod = "FAILED"  // termination failed
+	KillMethodStale   KillMethod = "STALE"   // refused: the PID now belongs to a different process
 )
 
+// pollInterval is how often a signalled process is checked for exit.
+const pollInterval = 50 * time.Millisecond
+
 // Killer defines the interface for process termination operations.
 // Implementations can provide platform-specific process killing behavior.
 type Killer interface {
-	// Kill attempts to terminate the process with the given PID using default grace period
+	// Kill attempts to terminate the process with the given PID using the matching kill strategy
 	Kill(ctx context.Context, pid int, portInfo *models.PortInfo) (*KillResult, error)
 	// KillWithTimeout attempts to terminate with a custom grace period before forcing
 	KillWithTimeout(ctx context.Context, pid int, timeout time.Duration, portInfo *models.PortInfo) (*KillResult, error)
+	// KillWithSignal sends a specific signal first, forcing with SIGKILL after the grace period
+	KillWithSignal(ctx context.Context, pid int, sig syscall.Signal, portInfo *models.PortInfo) (*KillResult, error)
+	// KillWithStrategy runs an explicit signal sequence
+	KillWithStrategy(ctx context.Context, pid int, strategy KillStrategy, portInfo *models.PortInfo) (*KillResult, error)
 	// IsRunning checks if a process with the given PID is currently active
 	IsRunning(pid int) (bool, error)
+	// Suspend pauses the process without terminating it (SIGSTOP)
+	Suspend(pid int, portInfo *models.PortInfo) error
+	// Resume continues a suspended process (SIGCONT)
+	Resume(pid int, portInfo *models.PortInfo) error
 }
 
-// ProcessKiller implements the Killer interface with a two-phase termination strategy.
-// First tries SIGTERM (graceful shutdown), then SIGKILL (force) if needed.
+// ProcessKiller implements the Killer interface with configurable signal sequences.
+// By default it tries SIGTERM (graceful shutdown), then SIGKILL (force) if needed;
+// Strategies can override the sequence for specific processes.
 type ProcessKiller struct {
-	GracePeriod             time.Duration // how long to wait for graceful shutdown
-	SystemProcessProtection bool          // whether to prevent killing system processes
+	GracePeriod             time.Duration  // how long to wait for graceful shutdown
+	SystemProcessProtection bool           // whether to enforce Policy (false signals anything)
+	Policy                  *policy.Policy // protection rules; nil means the default rules
+	Confirmed               bool           // whether the user typed the confirmation "confirm" rules ask for
+	Strategies              []KillStrategy // per-process strategies, first match wins
+	Scope                   KillScope      // which processes to signal ("" means just the PID)
+	ReleaseTimeout          time.Duration  // how long to wait for the port to be freed after a kill (0 disables)
 }
 
 // NewProcessKiller creates a new ProcessKiller with default settings.
-// Default grace period is 3 seconds, system process protection is enabled.
+// Default grace period is 3 seconds, the default protection policy is enforced,
+// the built-in strategies (postgres, nginx) are active, and the port is
+// checked for release for up to 2 seconds after a kill.
 func NewProcessKiller() *ProcessKiller {
 	return &ProcessKiller{
 		GracePeriod:             3 * time.Second,
 		SystemProcessProtection: true,
+		Policy:                  policy.Default(),
+		Strategies:              BuiltinStrategies(),
+		ReleaseTimeout:          2 * time.Second,
 	}
 }
 
@@ -60,166 +93,249 @@ func NewProcessKillerWithGracePeriod(gracePeriod time.Duration) *ProcessKiller {
 	return &ProcessKiller{
 		GracePeriod:             gracePeriod,
 		SystemProcessProtection: true,
+		Policy:                  policy.Default(),
+		Strategies:              BuiltinStrategies(),
+		ReleaseTimeout:          2 * time.Second,
+	}
+}
+
+// protected checks the protection policy and explains why the process may not be signalled,
+// or returns "" if it may. "confirm" rules pass only when Confirmed is set.
+func (k *ProcessKiller) protected(pid int, portInfo *models.PortInfo) string {
+	if !k.SystemProcessProtection || portInfo == nil {
+		return ""
+	}
+	rules := k.Policy
+	if rules == nil {
+		rules = policy.Default()
 	}
+	decision := rules.Evaluate(portInfo)
+	if decision.Permits(k.Confirmed) {
+		return ""
+	}
+	if decision.Action == policy.ActionConfirm {
+		return fmt.Sprintf("Process PID %d is protected by policy rule %q and needs confirmation", pid, decision.Rule)
+	}
+	return fmt.Sprintf("Process PID %d is protected by policy rule %q", pid, decision.Rule)
 }
 
-// Kill attempts to terminate the process using the default grace period.
-// It first tries SIGTERM, then SIGKILL if the process doesn't exit in time.
+// StrategyFor returns the first configured strategy matching the port,
+// or the default SIGTERM/SIGKILL strategy with the killer's grace period.
+func (k *ProcessKiller) StrategyFor(portInfo *models.PortInfo) KillStrategy {
+	for _, strategy := range k.Strategies {
+		if len(strategy.Steps) > 0 && strategy.Matches(portInfo) {
+			return strategy
+		}
+	}
+	strategy := DefaultStrategy(k.GracePeriod)
+	strategy.Name = "default"
+	return strategy
+}
+
+// Kill attempts to terminate the process using the strategy matching portInfo.
+// Without a matching strategy it tries SIGTERM, then SIGKILL after the grace period.
 func (k *ProcessKiller) Kill(ctx context.Context, pid int, portInfo *models.PortInfo) (*KillResult, error) {
-	return k.KillWithTimeout(ctx, pid, k.GracePeriod, portInfo)
+	return k.KillWithStrategy(ctx, pid, k.StrategyFor(portInfo), portInfo)
 }
 
 // KillWithTimeout attempts to terminate a process with a custom grace period.
-// The termination strategy:
-// 1. Check if process is protected (system process)
-// 2. Verify process is actually running
-// 3. Send SIGTERM for graceful shutdown
-// 4. Wait for process to exit within grace period
-// 5. If still running after timeout, send SIGKILL
-// 6. Return the result with method used and duration
+// It ignores configured strategies: SIGTERM, wait up to timeout, then SIGKILL.
 func (k *ProcessKiller) KillWithTimeout(ctx context.Context, pid int, timeout time.Duration, portInfo *models.PortInfo) (*KillResult, error) {
+	return k.KillWithStrategy(ctx, pid, DefaultStrategy(timeout), portInfo)
+}
+
+// KillWithSignal sends sig first, then SIGKILL if the process outlives the grace period.
+// This backs the manual signal choice in the kill dialog.
+func (k *ProcessKiller) KillWithSignal(ctx context.Context, pid int, sig syscall.Signal, portInfo *models.PortInfo) (*KillResult, error) {
+	return k.KillWithStrategy(ctx, pid, SignalStrategy(sig, k.GracePeriod), portInfo)
+}
+
+// KillWithStrategy terminates a process by walking through the strategy's signal steps.
+// The termination flow:
+// 1. Validate the PID and check the context is still live
+// 2. Check if process is protected (system process)
+// 3. Verify process is actually running and is still the process that was scanned (no PID reuse)
+// 4. For group and session scopes, hand off to killScope to signal every member
+// 5. For each step: send the signal and wait up to step.Wait for the process to exit
+// 6. After a successful kill, wait for the port to be released (see WaitForRelease)
+// 7. Return the result with the signal that worked and the duration
+func (k *ProcessKiller) KillWithStrategy(ctx context.Context, pid int, strategy KillStrategy, portInfo *models.PortInfo) (*KillResult, error) {
+	result, err := k.signalWithStrategy(ctx, pid, strategy, portInfo)
+	if err != nil || !result.Success {
+		return result, err
+	}
+
+	// A dead PID doesn't guarantee a free port: a child may have inherited the socket
+	if k.ReleaseTimeout > 0 && portInfo != nil && portInfo.PortNumber > 0 {
+		result.Release = WaitForRelease(ctx, portInfo.PortNumber, k.ReleaseTimeout)
+	}
+	return result, nil
+}
+
+// signalWithStrategy performs steps 1-5 of KillWithStrategy: validation and signalling.
+func (k *ProcessKiller) signalWithStrategy(ctx context.Context, pid int, strategy KillStrategy, portInfo *models.PortInfo) (*KillResult, error) {
 	startTime := time.Now()
 
-	// Protect system processes from accidental termination
-	if k.SystemProcessProtection && portInfo != nil && portInfo.ShouldDisplayWarning() {
+	fail := func(msg string) *KillResult {
 		return &KillResult{
-			Success: false,
-			Method:  KillMethodFailed,
-			Message: fmt.Sprintf("System process (PID %d) is protected", pid),
-		}, nil
+			Success:  false,
+			Method:   KillMethodFailed,
+			Strategy: strategy.Name,
+			Message:  msg,
+			Duration: time.Since(startTime),
+		}
 	}
 
+	// Signalling PID 0 or a negative PID would hit whole process groups
+	if pid <= 0 {
+		return fail(fmt.Sprintf("Invalid PID %d", pid)), fmt.Errorf("invalid PID %d", pid)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fail("Operation cancelled"), err
+	}
+
+	// Protect system processes from accidental termination
+	if reason := k.protected(pid, portInfo); reason != "" {
+		return fail(reason), nil
+	}
+
+	// Open the handle once, before any checks: with a pidfd (Linux) every later check
+	// and signal is then pinned to this exact process even if the PID is recycled
+	proc, err := findProcess(pid)
+	if err != nil {
+		return fail(fmt.Sprintf("Process PID %d is not running", pid)), nil
+	}
+	defer proc.Release()
+
 	// Verify process is running before attempting to kill
-	running, err := k.IsRunning(pid)
+	exited, err := proc.Wait(ctx, 0)
 	if err != nil {
-		return &KillResult{
-			Success: false,
-			Method:  KillMethodFailed,
-			Message: fmt.Sprintf("Process status check failed: %v", err),
-		}, err
+		return fail(fmt.Sprintf("Process status check failed: %v", err)), err
 	}
-	if !running {
-		return &KillResult{
-			Success: false,
-			Method:  KillMethodFailed,
-			Message: fmt.Sprintf("Process PID %d is not running", pid),
-		}, nil
+	if exited {
+		return fail(fmt.Sprintf("Process PID %d is not running", pid)), nil
 	}
 
-	// Phase 1: Send SIGTERM for graceful shutdown
-	if err := k.sendSignal(pid, syscall.SIGTERM); err != nil {
-		return &KillResult{
-			Success: false,
-			Method:  KillMethodFailed,
-			Message: fmt.Sprintf("SIGTERM send failed: %v", err),
-		}, err
+	// The scan may be seconds old; never signal a PID that was recycled since
+	if reason := staleReason(pid, portInfo); reason != "" {
+		result := fail(reason)
+		result.Method = KillMethodStale
+		return result, fmt.Errorf("%w: %s", ErrStaleTarget, reason)
+	}
+
+	if len(strategy.Steps) == 0 {
+		return fail(fmt.Sprintf("Kill strategy %q has no signal steps", strategy.Name)), nil
 	}
 
-	// Phase 2: Wait for graceful shutdown or force with SIGKILL
-	graceCtx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
+	if k.Scope != "" && k.Scope != ScopeProcess {
+		return k.killScope(ctx, pid, strategy, startTime)
+	}
 
-	ticker := time.NewTicker(100 * time.Millisecond)
-	defer ticker.Stop()
+	lastMethod := KillMethodFailed
+	for _, step := range strategy.Steps {
+		method := KillMethod(SignalName(step.Signal))
 
-	for {
-		select {
-		case <-graceCtx.Done():
-			// Grace period expired - force kill
-			return k.forceKill(ctx, pid, startTime)
-		case <-ticker.C:
-			// Check if process has exited
-			running, err := k.IsRunning(pid)
-			if err == nil && !running {
-				return &KillResult{
-					Success:  true,
-					Method:   KillMethodSIGTERM,
-					Message:  fmt.Sprintf("PID %d terminated gracefully", pid),
-					Duration: time.Since(startTime),
-				}, nil
+		if err := proc.Signal(step.Signal); err != nil {
+			// The process may have exited on its own right after the previous step
+			if exited, _ := proc.Wait(ctx, 0); exited && lastMethod != KillMethodFailed {
+				return k.terminated(pid, lastMethod, strategy, startTime), nil
 			}
-		case <-ctx.Done():
+			return fail(fmt.Sprintf("%s send failed: %v", method, err)), err
+		}
+		lastMethod = method
+
+		exited, err := proc.Wait(ctx, step.Wait)
+		if err != nil {
 			// Operation was cancelled from outside
-			return &KillResult{
-				Success: false,
-				Method:  KillMethodFailed,
-				Message: "Operation cancelled",
-			}, ctx.Err()
+			return fail("Operation cancelled"), err
+		}
+		if exited {
+			return k.terminated(pid, method, strategy, startTime), nil
 		}
 	}
+
+	return &KillResult{
+		Success:  false,
+		Method:   lastMethod,
+		Strategy: strategy.Name,
+		Message:  fmt.Sprintf("PID %d termination failed", pid),
+		Duration: time.Since(startTime),
+	}, nil
 }
 
-// forceKill sends SIGKILL to terminate a process that didn't exit gracefully.
-// This is the final step after SIGTERM fails. It verifies termination and returns result.
-func (k *ProcessKiller) forceKill(_ context.Context, pid int, startTime time.Time) (*KillResult, error) {
-	// Check one more time in case process exited between last check and timeout
-	running, err := k.IsRunning(pid)
-	if err == nil && !running {
-		return &KillResult{
-			Success:  true,
-			Method:   KillMethodSIGTERM,
-			Message:  fmt.Sprintf("PID %d terminated (timing difference)", pid),
-			Duration: time.Since(startTime),
-		}, nil
+// terminated builds the success result for a process that exited after the given signal.
+func (k *ProcessKiller) terminated(pid int, method KillMethod, strategy KillStrategy, startTime time.Time) *KillResult {
+	msg := fmt.Sprintf("PID %d terminated gracefully (%s)", pid, method)
+	if method == KillMethodSIGKILL {
+		msg = fmt.Sprintf("PID %d force terminated", pid)
 	}
+	return &KillResult{
+		Success:  true,
+		Method:   method,
+		Strategy: strategy.Name,
+		Message:  msg,
+		Duration: time.Since(startTime),
+	}
+}
 
-	// Send SIGKILL to force termination
-	if err := k.sendSignal(pid, syscall.SIGKILL); err != nil {
-		return &KillResult{
-			Success: false,
-			Method:  KillMethodFailed,
-			Message: fmt.Sprintf("SIGKILL send failed: %v", err),
-		}, err
+// pollExit calls exited every pollInterval until it reports true, timeout elapses or ctx is done.
+// It backs Process.Wait for handles that can't block on process exit directly.
+// It returns an error only if ctx is cancelled; a process still running after timeout returns false.
+func pollExit(ctx context.Context, timeout time.Duration, exited func() bool) (bool, error) {
+	if exited() {
+		return true, nil
+	}
+	if timeout <= 0 {
+		return false, nil
 	}
 
-	// Give the process a moment to terminate after SIGKILL
-	time.Sleep(50 * time.Millisecond)
-	running, _ = k.IsRunning(pid)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
 
-	result := &KillResult{
-		Success:  !running,
-		Method:   KillMethodSIGKILL,
-		Message:  fmt.Sprintf("SIGKILL sent to PID %d", pid),
-		Duration: time.Since(startTime),
-	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
 
-	// Set appropriate message based on final state
-	if !running {
-		result.Message = fmt.Sprintf("PID %d force terminated", pid)
-		result.Success = true
-	} else {
-		result.Message = fmt.Sprintf("PID %d termination failed", pid)
-		result.Success = false
+	for {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-timer.C:
+			// Check one more time in case the process exited between the last poll and the deadline
+			return exited(), nil
+		case <-ticker.C:
+			if exited() {
+				return true, nil
+			}
+		}
 	}
-
-	return result, nil
 }
 
 // IsRunning checks if a process with the given PID is currently active.
-// It uses signal 0 which doesn't actually send a signal but checks process existence.
+// It opens a process handle and checks, without waiting, whether the process has exited.
+// An exited but unreaped (zombie) process counts as not running where the platform can tell.
 // Returns false if process doesn't exist, true if it exists.
 func (k *ProcessKiller) IsRunning(pid int) (bool, error) {
-	err := k.sendSignal(pid, 0)
-	if err != nil {
+	if pid <= 0 {
 		return false, nil
 	}
-	return true, nil
-}
 
-// sendSignal sends a signal to the process with the given PID.
-// Signal 0 is used to check process existence without actually signaling.
-// Actual implementation is platform-specific (see killer_posix.go and killer_windows.go).
-func (k *ProcessKiller) sendSignal(pid int, sig syscall.Signal) error {
 	process, err := findProcess(pid)
 	if err != nil {
-		return err
+		return false, nil
 	}
 	defer process.Release()
-	return process.Signal(sig)
+
+	exited, err := process.Wait(context.Background(), 0)
+	if err != nil {
+		return false, nil
+	}
+	return !exited, nil
 }
 
 // findProcess locates a process by PID and returns a Process handle.
-// The actual implementation is platform-specific and defined in killer_posix.go or killer_windows.go.
+// The actual implementation is platform-specific: killer_linux.go (pidfd with a PID fallback),
+// killer_darwin.go and killer_windows.go.
 func findProcess(pid int) (Process, error) {
 	return findProcessImpl(pid)
 }
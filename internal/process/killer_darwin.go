@@ -0,0 +1,13 @@
+//go:build darwin
+// +build darwin
+
+package process
+
+// maxSignal is the highest signal number ParseSignal accepts (macOS has no real-time signals).
+const maxSignal = 31
+
+// findProcessImpl is the macOS implementation of process finding.
+// macOS has no pidfd, so processes are signalled and polled by PID.
+func findProcessImpl(pid int) (Process, error) {
+	return findOSProcess(pid)
+}
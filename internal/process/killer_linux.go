@@ -0,0 +1,125 @@
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// pidfdEnabled selects the pidfd backend. It is a variable so tests can exercise the fallback.
+var pidfdEnabled = true
+
+// maxSignal is the highest signal number ParseSignal accepts: SIGRTMAX on Linux.
+const maxSignal = 64
+
+// pidfdProcess targets one exact process through a pidfd (Linux 5.3+).
+// Unlike a PID, a pidfd can't be recycled: signals never reach a process that reused the PID,
+// and the fd becomes readable the moment the process exits, even if it stays an unreaped zombie.
+type pidfdProcess struct {
+	pid int
+	fd  int
+}
+
+// Signal sends sig to the exact process the pidfd refers to.
+func (p *pidfdProcess) Signal(sig syscall.Signal) error {
+	return unix.PidfdSendSignal(p.fd, sig, nil, 0)
+}
+
+// Wait polls the pidfd until it becomes readable (the process exited), timeout elapses or ctx is done.
+// The poll is split into pollInterval slices only so that ctx cancellation is noticed promptly.
+func (p *pidfdProcess) Wait(ctx context.Context, timeout time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	fds := []unix.PollFd{{Fd: int32(p.fd), Events: unix.POLLIN}}
+
+	for {
+		slice := time.Until(deadline)
+		if slice > pollInterval {
+			slice = pollInterval
+		}
+		// Wake up when the context expires rather than at the end of the slice
+		if ctxDeadline, ok := ctx.Deadline(); ok && time.Until(ctxDeadline) < slice {
+			slice = time.Until(ctxDeadline)
+		}
+		if slice < 0 {
+			slice = 0
+		}
+
+		n, err := unix.Poll(fds, int(slice.Milliseconds()))
+		if err != nil && !errors.Is(err, unix.EINTR) {
+			return false, fmt.Errorf("poll pidfd (PID %d): %w", p.pid, err)
+		}
+		if n > 0 && fds[0].Revents&unix.POLLIN != 0 {
+			return true, nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		if !time.Now().Before(deadline) {
+			return false, nil
+		}
+	}
+}
+
+// Release closes the pidfd.
+func (p *pidfdProcess) Release() error {
+	return unix.Close(p.fd)
+}
+
+// procProcess is the fallback for kernels without pidfd_open (before 5.3) or sandboxes that block it.
+// It signals by PID like osProcess, but reads /proc to treat zombies as exited.
+type procProcess struct {
+	*osProcess
+}
+
+// Wait polls until signal 0 fails or /proc reports the process as a zombie.
+func (p *procProcess) Wait(ctx context.Context, timeout time.Duration) (bool, error) {
+	return pollExit(ctx, timeout, func() bool {
+		return p.Process.Signal(syscall.Signal(0)) != nil || isZombie(p.Pid)
+	})
+}
+
+// isZombie reports whether /proc/<pid>/stat shows the process in state Z (exited, not yet reaped).
+func isZombie(pid int) bool {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false
+	}
+	// The command name in parentheses may itself contain spaces or parentheses
+	stat := string(data)
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 || end+2 >= len(stat) {
+		return false
+	}
+	return stat[end+2] == 'Z'
+}
+
+// findProcessImpl is the Linux implementation of process finding.
+// It opens a pidfd when the kernel supports it and falls back to PID-based signalling otherwise.
+func findProcessImpl(pid int) (Process, error) {
+	if pidfdEnabled {
+		fd, err := unix.PidfdOpen(pid, 0)
+		if err == nil {
+			return &pidfdProcess{pid: pid, fd: fd}, nil
+		}
+		if errors.Is(err, unix.ESRCH) {
+			return nil, fmt.Errorf("find process failed (PID %d): %w", pid, err)
+		}
+		// ENOSYS (old kernel) or EPERM (seccomp): use the PID-based path below
+	}
+
+	process, err := findOSProcess(pid)
+	if err != nil {
+		return nil, err
+	}
+	return &procProcess{osProcess: process}, nil
+}
@@ -4,9 +4,11 @@
 package process
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"syscall"
+	"time"
 )
 
 // Process defines the interface for interacting with system processes on POSIX systems.
@@ -14,10 +16,19 @@ import (
 type Process interface {
 	// Signal sends a signal to the process (SIGTERM, SIGKILL, or 0 for checking existence)
 	Signal(sig syscall.Signal) error
+	// Wait blocks until the process exits, timeout elapses or ctx is done.
+	// It reports whether the process exited; a zero timeout just checks once.
+	Wait(ctx context.Context, timeout time.Duration) (bool, error)
 	// Release releases any resources associated with the process handle
 	Release() error
 }
 
+// platformSignals lists the extra signals that can be named in kill strategies on POSIX systems.
+var platformSignals = map[string]syscall.Signal{
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+}
+
 // osProcess wraps the standard library's os.Process to implement our Process interface.
 // It adds the Release method which is a no-op for os.Process since it doesn't hold resources.
 type osProcess struct {
@@ -36,10 +47,18 @@ func (p *osProcess) Signal(sig syscall.Signal) error {
 	return p.Process.Signal(sig)
 }
 
-// findProcessImpl is the POSIX implementation of process finding.
+// Wait polls the process with signal 0 until it disappears.
+// Polling by PID can't tell a recycled PID from the original process; see the pidfd backend on Linux.
+func (p *osProcess) Wait(ctx context.Context, timeout time.Duration) (bool, error) {
+	return pollExit(ctx, timeout, func() bool {
+		return p.Process.Signal(syscall.Signal(0)) != nil
+	})
+}
+
+// findOSProcess is the portable POSIX process lookup.
 // It uses os.FindProcess which on POSIX systems doesn't actually verify the process exists
 // but creates a Process object that can be used with Signal(0) to check existence.
-func findProcessImpl(pid int) (Process, error) {
+func findOSProcess(pid int) (*osProcess, error) {
 	process, err := os.FindProcess(pid)
 	if err != nil {
 		return nil, fmt.Errorf("find process failed (PID %d): %w", pid, err)
@@ -2,10 +2,13 @@ package process
 
 import (
 	"context"
+	"os/exec"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/policy"
 )
 
 func TestProcessKiller_NewProcessKiller(t *testing.T) {
@@ -295,6 +298,46 @@ func TestProcessKiller_SystemProcessProtectionDisabled(t *testing.T) {
 		result.Success, result.Method, result.Message, err)
 }
 
+func TestProcessKiller_Policy(t *testing.T) {
+	tests := []struct {
+		name        string
+		action      policy.Action
+		confirmed   bool
+		wantSuccess bool
+		wantMessage string
+	}{
+		{"block", policy.ActionBlock, true, false, `protected by policy rule "test"`},
+		{"confirm without confirmation", policy.ActionConfirm, false, false, "needs confirmation"},
+		{"confirm with confirmation", policy.ActionConfirm, true, true, ""},
+		{"warn", policy.ActionWarn, false, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("sleep", "30")
+			if err := cmd.Start(); err != nil {
+				t.Skipf("cannot start test process: %v", err)
+			}
+			go cmd.Wait()
+			defer cmd.Process.Kill()
+
+			killer := NewProcessKiller()
+			killer.ReleaseTimeout = 0
+			killer.Policy = policy.New(policy.Rule{Name: "test", Action: tt.action, ProcessNames: []string{"sleep"}})
+			killer.Confirmed = tt.confirmed
+
+			portInfo := &models.PortInfo{PortNumber: 3000, PID: cmd.Process.Pid, ProcessName: "sleep"}
+			result, _ := killer.Kill(context.Background(), portInfo.PID, portInfo)
+			if result.Success != tt.wantSuccess {
+				t.Fatalf("Kill() Success = %v, want %v (%s)", result.Success, tt.wantSuccess, result.Message)
+			}
+			if tt.wantMessage != "" && !strings.Contains(result.Message, tt.wantMessage) {
+				t.Errorf("Kill() Message = %q, want it to contain %q", result.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
 func TestProcessKiller_NotRunning(t *testing.T) {
 	killer := NewProcessKiller()
 	ctx := context.Background()
@@ -4,9 +4,11 @@
 package process
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"syscall"
+	"time"
 )
 
 // Process defines the interface for interacting with system processes on Windows.
@@ -14,14 +16,25 @@ import (
 type Process interface {
 	// Signal sends a signal to the process (SIGTERM or SIGKILL for termination)
 	Signal(sig syscall.Signal) error
+	// Wait blocks until the process exits, timeout elapses or ctx is done.
+	// It reports whether the process exited; a zero timeout just checks once.
+	Wait(ctx context.Context, timeout time.Duration) (bool, error)
 	// Release releases any resources associated with the process handle
 	Release() error
 }
 
+// platformSignals is empty on Windows: every signal ends in TerminateProcess anyway.
+var platformSignals = map[string]syscall.Signal{}
+
+// maxSignal is the highest signal number ParseSignal accepts.
+const maxSignal = 31
+
 // windowsProcess wraps the standard library's os.Process for Windows-specific behavior.
 // On Windows, signals work differently than POSIX systems.
 type windowsProcess struct {
 	*os.Process
+	// handle is a SYNCHRONIZE handle used to wait for the process to exit
+	handle syscall.Handle
 }
 
 // Signal sends a signal to the Windows process.
@@ -53,18 +66,30 @@ func (p *windowsProcess) Terminate() error {
 	return p.Kill()
 }
 
-// Release is a no-op for windowsProcess since os.Process doesn't require explicit cleanup.
-// This method exists to satisfy the Process interface.
+// Wait waits on the process handle until the process exits, timeout elapses or ctx is done.
+func (p *windowsProcess) Wait(ctx context.Context, timeout time.Duration) (bool, error) {
+	return pollExit(ctx, timeout, func() bool {
+		event, err := syscall.WaitForSingleObject(p.handle, 0)
+		return err == nil && event == syscall.WAIT_OBJECT_0
+	})
+}
+
+// Release closes the wait handle.
 func (p *windowsProcess) Release() error {
-	return nil
+	return syscall.CloseHandle(p.handle)
 }
 
 // findProcessImpl is the Windows implementation of process finding.
-// It uses os.FindProcess which creates a Process object for the given PID.
+// It uses os.FindProcess for termination and opens a separate SYNCHRONIZE handle for waiting.
 func findProcessImpl(pid int) (Process, error) {
+	handle, err := syscall.OpenProcess(syscall.SYNCHRONIZE, false, uint32(pid))
+	if err != nil {
+		return nil, fmt.Errorf("find process failed (PID %d): %w", pid, err)
+	}
 	process, err := os.FindProcess(pid)
 	if err != nil {
+		syscall.CloseHandle(handle)
 		return nil, fmt.Errorf("find process failed (PID %d): %w", pid, err)
 	}
-	return &windowsProcess{Process: process}, nil
+	return &windowsProcess{Process: process, handle: handle}, nil
 }
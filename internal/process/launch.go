@@ -0,0 +1,111 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// CaptureLaunch records how a running process was started: argv, working directory and environment.
+// On Linux these come from /proc/<pid>/{cmdline,cwd,environ}. Only argv is required; a working
+// directory or environment the OS won't reveal (e.g. another user's process) is left empty.
+func CaptureLaunch(pid int) (*models.LaunchContext, error) {
+	p, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return nil, err
+	}
+	args, err := p.CmdlineSlice()
+	if err != nil {
+		return nil, fmt.Errorf("read argv of PID %d: %w", pid, err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("PID %d has no argv (kernel thread or zombie)", pid)
+	}
+
+	launch := &models.LaunchContext{Args: args}
+	if exe, err := p.Exe(); err == nil {
+		launch.Executable = cleanExe(exe)
+	}
+	if cwd, err := p.Cwd(); err == nil {
+		launch.Cwd = cwd
+	}
+	if env, err := p.Environ(); err == nil {
+		launch.Env = env
+	}
+	return launch, nil
+}
+
+// Restart relaunches a process from its launch context and waits up to timeout for something
+// to listen on port again. The process is detached (own session, output in a log file under the
+// temp directory) so it keeps running after port-chaser exits.
+// Bound is false if the port is still free when the timeout expires; exiting before binding is an error.
+func Restart(ctx context.Context, launch *models.LaunchContext, port int, timeout time.Duration) (*models.RestartResult, error) {
+	if launch == nil || len(launch.Args) == 0 {
+		return nil, errors.New("no launch context recorded")
+	}
+
+	logFile, err := os.CreateTemp("", "port-chaser-restart-*.log")
+	if err != nil {
+		return nil, fmt.Errorf("create restart log: %w", err)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(launch.Args[0], launch.Args[1:]...)
+	cmd.Args = launch.Args
+	// Prefer the exact binary that was running; argv[0] may be relative or shadowed on our PATH
+	if launch.Executable != "" {
+		if _, err := os.Stat(launch.Executable); err == nil {
+			cmd.Path = launch.Executable
+			cmd.Err = nil
+		}
+	}
+	cmd.Dir = launch.Cwd
+	if len(launch.Env) > 0 {
+		cmd.Env = launch.Env
+	}
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = detachedAttr()
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start %s: %w", filepath.Base(launch.Args[0]), err)
+	}
+
+	// Reap the child if it exits while we're still running, and notice early crashes
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	result := &models.RestartResult{PID: cmd.Process.Pid, LogPath: logFile.Name()}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		if listeners, err := FindListeners(ctx, port); err == nil && len(listeners) > 0 {
+			result.Bound = true
+			result.ListenerPID = listeners[0].PID
+			return result, nil
+		}
+
+		select {
+		case err := <-exited:
+			if err == nil {
+				err = errors.New("exit status 0")
+			}
+			return result, fmt.Errorf("restarted process exited before binding port %d (%v); see %s", port, err, result.LogPath)
+		case <-ctx.Done():
+			return result, nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,12 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package process
+
+import "syscall"
+
+// detachedAttr starts restarted processes in a new session, away from port-chaser's terminal,
+// so they survive it exiting and don't receive its Ctrl+C.
+func detachedAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
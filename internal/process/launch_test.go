@@ -0,0 +1,107 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package process
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+func TestCaptureLaunch(t *testing.T) {
+	dir := t.TempDir()
+	cmd := exec.Command("sleep", "30")
+	cmd.Dir = dir
+	cmd.Env = []string{"PORT_CHASER_TEST=1", "PATH=" + os.Getenv("PATH")}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start test process: %v", err)
+	}
+	defer cmd.Process.Kill()
+
+	launch, err := CaptureLaunch(cmd.Process.Pid)
+	if err != nil {
+		t.Fatalf("CaptureLaunch() error = %v", err)
+	}
+	if len(launch.Args) != 2 || launch.Args[1] != "30" {
+		t.Errorf("Args = %v, want [sleep 30]", launch.Args)
+	}
+	if runtime.GOOS != "linux" {
+		// cwd and environ of other processes are only reliably readable from /proc
+		return
+	}
+	want, _ := filepath.EvalSymlinks(dir)
+	if launch.Cwd != want {
+		t.Errorf("Cwd = %q, want %q", launch.Cwd, want)
+	}
+	found := false
+	for _, kv := range launch.Env {
+		found = found || kv == "PORT_CHASER_TEST=1"
+	}
+	if !found {
+		t.Errorf("Env = %v, want PORT_CHASER_TEST=1", launch.Env)
+	}
+}
+
+func TestRestart(t *testing.T) {
+	defer func() { listenerLookup = findListeners }()
+
+	t.Run("binds", func(t *testing.T) {
+		// The port shows up on the second poll
+		var calls int32
+		listenerLookup = func(ctx context.Context, port int) ([]Listener, error) {
+			if atomic.AddInt32(&calls, 1) < 2 {
+				return nil, nil
+			}
+			return []Listener{{PID: 4242, Port: port}}, nil
+		}
+
+		launch := &models.LaunchContext{Args: []string{"sleep", "30"}, Cwd: t.TempDir()}
+		result, err := Restart(context.Background(), launch, 3000, 2*time.Second)
+		if err != nil {
+			t.Fatalf("Restart() error = %v", err)
+		}
+		defer syscallKill(result.PID)
+		defer os.Remove(result.LogPath)
+
+		if !result.Bound || result.ListenerPID != 4242 || result.PID <= 0 {
+			t.Errorf("result = %+v, want bound by 4242", result)
+		}
+	})
+
+	t.Run("exits before binding", func(t *testing.T) {
+		listenerLookup = func(ctx context.Context, port int) ([]Listener, error) { return nil, nil }
+
+		launch := &models.LaunchContext{Args: []string{"sh", "-c", "echo boom >&2; exit 3"}}
+		result, err := Restart(context.Background(), launch, 3000, 2*time.Second)
+		if err == nil || !strings.Contains(err.Error(), "exited before binding") {
+			t.Fatalf("Restart() error = %v, want an early-exit error", err)
+		}
+		defer os.Remove(result.LogPath)
+
+		if log, _ := os.ReadFile(result.LogPath); !strings.Contains(string(log), "boom") {
+			t.Errorf("log %s = %q, want the process output", result.LogPath, log)
+		}
+	})
+
+	t.Run("no launch context", func(t *testing.T) {
+		if _, err := Restart(context.Background(), nil, 3000, time.Second); err == nil {
+			t.Error("Restart(nil) should fail")
+		}
+	})
+}
+
+// syscallKill stops a process started by a test.
+func syscallKill(pid int) {
+	if p, err := os.FindProcess(pid); err == nil {
+		p.Kill()
+	}
+}
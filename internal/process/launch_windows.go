@@ -0,0 +1,15 @@
+//go:build windows
+// +build windows
+
+package process
+
+import "syscall"
+
+// detachedProcess is the DETACHED_PROCESS creation flag: no console is inherited.
+const detachedProcess = 0x00000008
+
+// detachedAttr starts restarted processes without port-chaser's console and in their own
+// process group, so they survive it exiting and don't receive its Ctrl+C.
+func detachedAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess}
+}
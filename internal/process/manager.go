@@ -0,0 +1,33 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// ErrNoStopCommand is returned when a port has no manager-native stop command.
+var ErrNoStopCommand = errors.New("no manager stop command available")
+
+// StopManaged stops a supervised process through its process manager (pm2 stop, supervisorctl stop, ...).
+// Unlike Kill, this keeps the manager from respawning the process.
+func StopManaged(ctx context.Context, portInfo *models.PortInfo) error {
+	if portInfo == nil || len(portInfo.StopCommand) == 0 {
+		return ErrNoStopCommand
+	}
+
+	cmd := exec.CommandContext(ctx, portInfo.StopCommand[0], portInfo.StopCommand[1:]...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(output))
+		if msg == "" {
+			return fmt.Errorf("%s failed: %w", strings.Join(portInfo.StopCommand, " "), err)
+		}
+		return fmt.Errorf("%s failed: %w: %s", strings.Join(portInfo.StopCommand, " "), err, msg)
+	}
+	return nil
+}
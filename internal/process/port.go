@@ -0,0 +1,45 @@
+package process
+
+import (
+	"context"
+
+	psnet "github.com/shirou/gopsutil/v3/net"
+)
+
+// Listener identifies the process currently listening on a TCP port.
+type Listener struct {
+	PID  int // process ID of the listening socket's owner (0 if unknown)
+	Port int // TCP port number
+}
+
+// listenerLookup finds the listeners for a port. It is a variable so tests can stub the socket table.
+var listenerLookup = findListeners
+
+// FindListeners returns every process listening on the given TCP port.
+// An empty result means nothing is listening.
+func FindListeners(ctx context.Context, port int) ([]Listener, error) {
+	return listenerLookup(ctx, port)
+}
+
+// findListeners scans the system socket table for LISTEN sockets on port.
+func findListeners(ctx context.Context, port int) ([]Listener, error) {
+	conns, err := psnet.ConnectionsWithContext(ctx, "tcp")
+	if err != nil {
+		return nil, err
+	}
+
+	var listeners []Listener
+	seen := make(map[int]bool)
+	for _, conn := range conns {
+		if conn.Status != "LISTEN" || conn.Laddr.Port != uint32(port) {
+			continue
+		}
+		pid := int(conn.Pid)
+		if seen[pid] {
+			continue
+		}
+		seen[pid] = true
+		listeners = append(listeners, Listener{PID: pid, Port: port})
+	}
+	return listeners, nil
+}
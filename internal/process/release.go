@@ -0,0 +1,61 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// bindTest reports whether the port can be bound right now. It is a variable so tests can stub it.
+var bindTest = canBind
+
+// WaitForRelease waits up to timeout for a TCP port to be freed.
+// The port counts as released once no process is listening on it or a test bind succeeds;
+// the bind test covers socket tables we can't read (other users' processes without root).
+// If the port is still held when the timeout expires, the result names the holder when known.
+func WaitForRelease(ctx context.Context, port int, timeout time.Duration) *models.PortRelease {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	var holders []Listener
+	for {
+		listeners, err := FindListeners(waitCtx, port)
+		if err == nil {
+			if len(listeners) == 0 {
+				return &models.PortRelease{Released: true}
+			}
+			holders = listeners
+		}
+		if bindTest(port) {
+			return &models.PortRelease{Released: true}
+		}
+
+		select {
+		case <-waitCtx.Done():
+			release := &models.PortRelease{Released: false}
+			if len(holders) > 0 && holders[0].PID > 0 {
+				release.HolderPID = holders[0].PID
+				release.HolderName = describeMember(holders[0].PID, 0).Name
+			}
+			return release
+		case <-ticker.C:
+		}
+	}
+}
+
+// canBind tries to listen on the port on all interfaces and immediately closes the listener.
+// A failure means something holds the port (or, for ports below 1024, that we lack the privilege).
+func canBind(port int) bool {
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return false
+	}
+	ln.Close()
+	return true
+}
@@ -0,0 +1,120 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+func TestWaitForRelease(t *testing.T) {
+	tests := []struct {
+		name      string
+		lookup    func(ctx context.Context, port int) ([]Listener, error)
+		bind      bool
+		released  bool
+		holderPID int
+	}{
+		{
+			name:     "no listener",
+			lookup:   func(ctx context.Context, port int) ([]Listener, error) { return nil, nil },
+			released: true,
+		},
+		{
+			name:     "socket table unreadable but bind succeeds",
+			lookup:   func(ctx context.Context, port int) ([]Listener, error) { return nil, errors.New("permission denied") },
+			bind:     true,
+			released: true,
+		},
+		{
+			name: "child still holds the socket",
+			lookup: func(ctx context.Context, port int) ([]Listener, error) {
+				return []Listener{{PID: 4242, Port: port}}, nil
+			},
+			released:  false,
+			holderPID: 4242,
+		},
+		{
+			name:     "unknown holder",
+			lookup:   func(ctx context.Context, port int) ([]Listener, error) { return nil, errors.New("permission denied") },
+			released: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listenerLookup = tt.lookup
+			bindTest = func(port int) bool { return tt.bind }
+			defer func() {
+				listenerLookup = findListeners
+				bindTest = canBind
+			}()
+
+			release := WaitForRelease(context.Background(), 3000, 150*time.Millisecond)
+			if release.Released != tt.released {
+				t.Errorf("Released = %v, want %v", release.Released, tt.released)
+			}
+			if release.HolderPID != tt.holderPID {
+				t.Errorf("HolderPID = %d, want %d", release.HolderPID, tt.holderPID)
+			}
+		})
+	}
+}
+
+func TestWaitForRelease_RealSocket(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	release := WaitForRelease(context.Background(), port, 200*time.Millisecond)
+	if release.Released {
+		ln.Close()
+		t.Fatal("port should be reported as held while listening")
+	}
+	if release.HolderPID != 0 && release.HolderPID != os.Getpid() {
+		t.Errorf("HolderPID = %d, want this test process (%d)", release.HolderPID, os.Getpid())
+	}
+
+	ln.Close()
+	if release := WaitForRelease(context.Background(), port, time.Second); !release.Released {
+		t.Errorf("port should be released after close, holder %d", release.HolderPID)
+	}
+}
+
+func TestProcessKiller_ReportsPortHolder(t *testing.T) {
+	// The test process plays the child that inherited the socket from the killed process
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer ln.Close()
+
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start test process: %v", err)
+	}
+	go cmd.Wait()
+
+	killer := NewProcessKiller()
+	killer.SystemProcessProtection = false
+	killer.ReleaseTimeout = 200 * time.Millisecond
+
+	port := &models.PortInfo{PortNumber: ln.Addr().(*net.TCPAddr).Port, PID: cmd.Process.Pid}
+	result, err := killer.Kill(context.Background(), cmd.Process.Pid, port)
+	if err != nil || !result.Success {
+		t.Fatalf("Kill() = %+v, %v", result, err)
+	}
+	if result.Release == nil {
+		t.Fatal("Release should be checked when the port is known")
+	}
+	if result.Release.Released {
+		t.Error("port is still held by the test process and should not be reported as released")
+	}
+}
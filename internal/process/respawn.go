@@ -0,0 +1,137 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// respawnPollInterval is how often the port is checked while watching for a respawn.
+const respawnPollInterval = 100 * time.Millisecond
+
+// WatchRespawn watches a port for the given window after its owner was killed.
+// It returns the replacement process if a different PID starts listening on the port,
+// or nil if the port stays free (or is still held by the killed PID) for the whole window.
+// Listeners whose owner can't be resolved (PID 0, e.g. another user's socket without root)
+// are ignored, since there is no process to describe.
+func WatchRespawn(ctx context.Context, portInfo *models.PortInfo, window time.Duration) (*models.RespawnInfo, error) {
+	if portInfo == nil || window <= 0 {
+		return nil, nil
+	}
+
+	watchCtx, cancel := context.WithTimeout(ctx, window)
+	defer cancel()
+
+	ticker := time.NewTicker(respawnPollInterval)
+	defer ticker.Stop()
+
+	for {
+		listeners, err := FindListeners(watchCtx, portInfo.PortNumber)
+		if err == nil {
+			for _, l := range listeners {
+				if l.PID > 0 && l.PID != portInfo.PID {
+					return describeRespawn(l.PID, portInfo), nil
+				}
+			}
+		}
+
+		select {
+		case <-watchCtx.Done():
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// describeRespawn builds RespawnInfo for the new listener and names its likely supervisor.
+// Known sources are tried from most to least specific: process manager, Docker restart policy,
+// systemd unit, and finally the parent process.
+func describeRespawn(pid int, killed *models.PortInfo) *models.RespawnInfo {
+	info := &models.RespawnInfo{PID: pid}
+
+	var parentPID int
+	var parentName string
+	if p, err := process.NewProcess(int32(pid)); err == nil {
+		info.ProcessName, _ = p.Name()
+		if ppid, err := p.Ppid(); err == nil {
+			parentPID = int(ppid)
+			if parent, err := process.NewProcess(ppid); err == nil {
+				parentName, _ = parent.Name()
+			}
+		}
+	}
+
+	switch {
+	case killed.Manager != "":
+		info.Supervisor = killed.Manager
+	case killed.ContainerID != "":
+		if policy := dockerRestartPolicy(killed.ContainerID); policy != "" {
+			info.Supervisor = "docker restart policy: " + policy
+		} else {
+			info.Supervisor = "docker"
+		}
+	}
+	if info.Supervisor == "" {
+		if unit := systemdUnit(pid); unit != "" {
+			info.Supervisor = "systemd: " + unit
+		}
+	}
+	if info.Supervisor == "" && parentPID > 0 {
+		info.Supervisor = fmt.Sprintf("parent PID %d (%s)", parentPID, parentName)
+	}
+
+	return info
+}
+
+// systemdUnit returns the systemd service owning pid, read from its cgroup path (Linux only).
+func systemdUnit(pid int) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return ""
+	}
+	return parseSystemdUnit(string(data))
+}
+
+// parseSystemdUnit finds the innermost .service component in /proc/<pid>/cgroup content.
+func parseSystemdUnit(cgroup string) string {
+	var unit string
+	for _, line := range strings.Split(cgroup, "\n") {
+		for _, part := range strings.Split(line, "/") {
+			if strings.HasSuffix(part, ".service") {
+				unit = part
+			}
+		}
+		if unit != "" {
+			return unit
+		}
+	}
+	return ""
+}
+
+// dockerRestartPolicy asks the Docker CLI for a container's restart policy ("always", "unless-stopped", ...).
+// It returns "" when Docker is unavailable or the policy is "no".
+func dockerRestartPolicy(containerID string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "docker", "inspect", "-f", "{{.HostConfig.RestartPolicy.Name}}", containerID).Output()
+	if err != nil {
+		return ""
+	}
+
+	policy := strings.TrimSpace(string(output))
+	if policy == "no" {
+		return ""
+	}
+	return policy
+}
@@ -0,0 +1,146 @@
+package process
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+func TestParseSystemdUnit(t *testing.T) {
+	tests := []struct {
+		name   string
+		cgroup string
+		want   string
+	}{
+		{"cgroup v2 service", "0::/system.slice/nginx.service\n", "nginx.service"},
+		{"user service", "0::/user.slice/user-1000.slice/user@1000.service/app.slice/api.service\n", "api.service"},
+		{"terminal session", "0::/user.slice/user-1000.slice/session-2.scope\n", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSystemdUnit(tt.cgroup); got != tt.want {
+				t.Errorf("parseSystemdUnit() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchRespawn_NewListener(t *testing.T) {
+	calls := 0
+	listenerLookup = func(ctx context.Context, port int) ([]Listener, error) {
+		calls++
+		if calls < 3 {
+			// The killed process still shows up briefly while it releases the socket
+			return []Listener{{PID: 1001, Port: port}}, nil
+		}
+		return []Listener{{PID: 1050, Port: port}}, nil
+	}
+	defer func() { listenerLookup = findListeners }()
+
+	port := &models.PortInfo{PortNumber: 3000, PID: 1001, Manager: "pm2"}
+	info, err := WatchRespawn(context.Background(), port, time.Second)
+	if err != nil {
+		t.Fatalf("WatchRespawn() error = %v", err)
+	}
+	if info == nil {
+		t.Fatal("WatchRespawn() should report the new listener")
+	}
+	if info.PID != 1050 {
+		t.Errorf("PID = %d, want 1050", info.PID)
+	}
+	if info.Supervisor != "pm2" {
+		t.Errorf("Supervisor = %q, want pm2", info.Supervisor)
+	}
+}
+
+func TestWatchRespawn_UnknownOwner(t *testing.T) {
+	calls := 0
+	listenerLookup = func(ctx context.Context, port int) ([]Listener, error) {
+		calls++
+		if calls < 3 {
+			// gopsutil reports PID 0 for a socket it can't attribute, e.g. another user's
+			return []Listener{{PID: 0, Port: port}}, nil
+		}
+		return []Listener{{PID: 0, Port: port}, {PID: 1050, Port: port}}, nil
+	}
+	defer func() { listenerLookup = findListeners }()
+
+	info, err := WatchRespawn(context.Background(), &models.PortInfo{PortNumber: 3000, PID: 1001}, time.Second)
+	if err != nil {
+		t.Fatalf("WatchRespawn() error = %v", err)
+	}
+	if info == nil || info.PID != 1050 {
+		t.Errorf("WatchRespawn() = %+v, want the listener with a known owner (PID 1050)", info)
+	}
+	if calls < 3 {
+		t.Errorf("WatchRespawn() returned after %d lookups, want the unknown owner ignored", calls)
+	}
+
+	listenerLookup = func(ctx context.Context, port int) ([]Listener, error) {
+		return []Listener{{PID: 0, Port: port}}, nil
+	}
+	info, err = WatchRespawn(context.Background(), &models.PortInfo{PortNumber: 3000, PID: 1001}, 300*time.Millisecond)
+	if err != nil || info != nil {
+		t.Errorf("WatchRespawn() = %+v, %v; want no respawn for a listener without an owner", info, err)
+	}
+}
+
+func TestWatchRespawn_PortStaysFree(t *testing.T) {
+	listenerLookup = func(ctx context.Context, port int) ([]Listener, error) {
+		return nil, nil
+	}
+	defer func() { listenerLookup = findListeners }()
+
+	start := time.Now()
+	info, err := WatchRespawn(context.Background(), &models.PortInfo{PortNumber: 3000, PID: 1001}, 300*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WatchRespawn() error = %v", err)
+	}
+	if info != nil {
+		t.Errorf("WatchRespawn() = %+v, want nil", info)
+	}
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("WatchRespawn() returned before the window elapsed: %v", elapsed)
+	}
+}
+
+func TestWatchRespawn_Disabled(t *testing.T) {
+	info, err := WatchRespawn(context.Background(), &models.PortInfo{PortNumber: 3000, PID: 1001}, 0)
+	if info != nil || err != nil {
+		t.Errorf("WatchRespawn() with zero window = %v, %v, want nil, nil", info, err)
+	}
+}
+
+func TestFindListeners(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test (-short)")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	listeners, err := FindListeners(context.Background(), port)
+	if err != nil {
+		t.Skipf("socket table unavailable: %v", err)
+	}
+
+	found := false
+	for _, l := range listeners {
+		if l.PID == os.Getpid() {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("FindListeners(%d) = %+v, want current PID %d", port, listeners, os.Getpid())
+	}
+}
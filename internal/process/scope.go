@@ -0,0 +1,183 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// KillScope selects which processes a kill signals.
+type KillScope string
+
+const (
+	ScopeProcess KillScope = "process" // only the listener PID
+	ScopeGroup   KillScope = "group"   // the listener's whole process group
+	ScopeSession KillScope = "session" // every process group in the listener's session
+)
+
+// ParseScope parses "process", "group" or "session"; an empty string means ScopeProcess.
+func ParseScope(s string) (KillScope, error) {
+	switch KillScope(strings.ToLower(strings.TrimSpace(s))) {
+	case "", ScopeProcess:
+		return ScopeProcess, nil
+	case ScopeGroup:
+		return ScopeGroup, nil
+	case ScopeSession:
+		return ScopeSession, nil
+	}
+	return "", fmt.Errorf("unknown kill scope %q (want process, group or session)", s)
+}
+
+// Members returns the processes a kill of pid with the given scope would signal, sorted by PID.
+// For ScopeProcess that is just pid itself. Group and session lookups refuse targets that
+// include port-chaser's own process group or session.
+func Members(pid int, scope KillScope) ([]models.ProcessMember, error) {
+	if pid <= 0 {
+		return nil, fmt.Errorf("invalid PID %d", pid)
+	}
+	if scope == "" || scope == ScopeProcess {
+		return []models.ProcessMember{describeMember(pid, 0)}, nil
+	}
+
+	members, err := scopeMembers(pid, scope)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].PID < members[j].PID })
+	return members, nil
+}
+
+// describeMember fills in a member's name and command line; lookup failures leave them empty.
+func describeMember(pid, pgid int) models.ProcessMember {
+	member := models.ProcessMember{PID: pid, PGID: pgid}
+	if p, err := process.NewProcess(int32(pid)); err == nil {
+		member.Name, _ = p.Name()
+		member.Command, _ = p.Cmdline()
+	}
+	return member
+}
+
+// killScope terminates every member of pid's process group or session by walking through
+// the strategy's steps, signalling whole groups via negative PIDs.
+// The result lists each member with the signal it exited after; it succeeds only if all members exited.
+func (k *ProcessKiller) killScope(ctx context.Context, pid int, strategy KillStrategy, startTime time.Time) (*KillResult, error) {
+	result := &KillResult{
+		Method:   KillMethodFailed,
+		Strategy: strategy.Name,
+	}
+	finish := func(msg string) *KillResult {
+		result.Message = msg
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	members, err := Members(pid, k.Scope)
+	if err != nil {
+		return finish(fmt.Sprintf("Process %s lookup failed: %v", k.Scope, err)), err
+	}
+
+	// A group containing a protected process is protected just like the process itself
+	for _, member := range members {
+		if member.PID == pid {
+			continue
+		}
+		info := &models.PortInfo{PID: member.PID, ProcessName: member.Name, Command: member.Command}
+		if reason := k.protected(member.PID, info); reason != "" {
+			return finish(fmt.Sprintf("Process %s of PID %d contains a protected process: %s", k.Scope, pid, reason)), nil
+		}
+	}
+	result.Members = members
+
+	for _, step := range strategy.Steps {
+		method := KillMethod(SignalName(step.Signal))
+
+		if err := signalGroups(aliveMembers(result.Members), step.Signal); err != nil {
+			return finish(fmt.Sprintf("%s send failed: %v", method, err)), err
+		}
+
+		done, err := k.waitForMembers(ctx, result.Members, method, step.Wait)
+		if err != nil {
+			return finish("Operation cancelled"), err
+		}
+		if done {
+			break
+		}
+	}
+
+	// Report the signal that took down the listener itself
+	for _, member := range result.Members {
+		if member.PID == pid && member.Terminated {
+			result.Method = KillMethod(member.Signal)
+		}
+	}
+
+	survivors := aliveMembers(result.Members)
+	if len(survivors) > 0 {
+		names := make([]string, 0, len(survivors))
+		for _, member := range survivors {
+			names = append(names, fmt.Sprintf("%s (%d)", member.Name, member.PID))
+		}
+		return finish(fmt.Sprintf("%d of %d %s members still running: %s",
+			len(survivors), len(result.Members), k.Scope, strings.Join(names, ", "))), nil
+	}
+
+	result.Success = true
+	return finish(fmt.Sprintf("Process %s of PID %d terminated (%d members)", k.Scope, pid, len(result.Members))), nil
+}
+
+// waitForMembers polls the members until all have exited or wait elapses, recording
+// method as the signal each exited member went down after.
+// It returns an error only if ctx is cancelled.
+func (k *ProcessKiller) waitForMembers(ctx context.Context, members []models.ProcessMember, method KillMethod, wait time.Duration) (bool, error) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	check := func() bool {
+		done := true
+		for i := range members {
+			if members[i].Terminated {
+				continue
+			}
+			if running, err := k.IsRunning(members[i].PID); err == nil && !running {
+				members[i].Terminated = true
+				members[i].Signal = string(method)
+				continue
+			}
+			done = false
+		}
+		return done
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-timer.C:
+			return check(), nil
+		case <-ticker.C:
+			if check() {
+				return true, nil
+			}
+		}
+	}
+}
+
+// aliveMembers returns the members that have not exited yet.
+func aliveMembers(members []models.ProcessMember) []models.ProcessMember {
+	var alive []models.ProcessMember
+	for _, member := range members {
+		if !member.Terminated {
+			alive = append(alive, member)
+		}
+	}
+	return alive
+}
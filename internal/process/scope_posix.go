@@ -0,0 +1,80 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package process
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"golang.org/x/sys/unix"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// ErrScopeIncludesSelf is returned when a group or session kill would also hit port-chaser.
+var ErrScopeIncludesSelf = errors.New("target includes port-chaser itself")
+
+// scopeMembers lists every process sharing pid's process group (ScopeGroup) or session (ScopeSession).
+func scopeMembers(pid int, scope KillScope) ([]models.ProcessMember, error) {
+	var key func(int) (int, error)
+	switch scope {
+	case ScopeGroup:
+		key = unix.Getpgid
+	case ScopeSession:
+		key = unix.Getsid
+	default:
+		return nil, fmt.Errorf("unknown kill scope %q", scope)
+	}
+
+	target, err := key(pid)
+	if err != nil {
+		return nil, fmt.Errorf("%s of PID %d: %w", scope, pid, err)
+	}
+	if own, err := key(os.Getpid()); err == nil && own == target {
+		return nil, ErrScopeIncludesSelf
+	}
+
+	pids, err := process.Pids()
+	if err != nil {
+		return nil, fmt.Errorf("list processes: %w", err)
+	}
+
+	var members []models.ProcessMember
+	for _, p := range pids {
+		if id, err := key(int(p)); err != nil || id != target {
+			continue
+		}
+		pgid, err := unix.Getpgid(int(p))
+		if err != nil {
+			// Exited while we were looking
+			continue
+		}
+		members = append(members, describeMember(int(p), pgid))
+	}
+	return members, nil
+}
+
+// signalGroups sends sig to each distinct process group among members via a negative PID.
+// Groups that have already disappeared are not an error.
+func signalGroups(members []models.ProcessMember, sig syscall.Signal) error {
+	seen := make(map[int]bool)
+	for _, member := range members {
+		if seen[member.PGID] {
+			continue
+		}
+		seen[member.PGID] = true
+
+		// kill(0) and kill(-1) would hit our own group or every process we can signal
+		if member.PGID <= 1 {
+			return fmt.Errorf("refusing to signal process group %d", member.PGID)
+		}
+		if err := unix.Kill(-member.PGID, sig); err != nil && !errors.Is(err, unix.ESRCH) {
+			return fmt.Errorf("signal process group %d: %w", member.PGID, err)
+		}
+	}
+	return nil
+}
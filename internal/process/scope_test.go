@@ -0,0 +1,123 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package process
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// startGroup starts a shell with two background sleeps in a new process group.
+func startGroup(t *testing.T) *exec.Cmd {
+	t.Helper()
+
+	cmd := exec.Command("sh", "-c", "sleep 30 & sleep 30 & wait")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start test process group: %v", err)
+	}
+	// Reap the shell so it doesn't linger as a zombie that still answers signal 0
+	go cmd.Wait()
+	t.Cleanup(func() { syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL) })
+
+	// Give the shell time to fork its children
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if members, err := Members(cmd.Process.Pid, ScopeGroup); err == nil && len(members) == 3 {
+			return cmd
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("process group did not reach 3 members")
+	return nil
+}
+
+func TestParseScope(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    KillScope
+		wantErr bool
+	}{
+		{"", ScopeProcess, false},
+		{"process", ScopeProcess, false},
+		{"Group", ScopeGroup, false},
+		{" session ", ScopeSession, false},
+		{"tree", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseScope(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseScope(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseScope(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMembers_RefusesOwnGroup(t *testing.T) {
+	if _, err := Members(os.Getpid(), ScopeGroup); !errors.Is(err, ErrScopeIncludesSelf) {
+		t.Errorf("Members(self, group) error = %v, want ErrScopeIncludesSelf", err)
+	}
+	if _, err := Members(os.Getpid(), ScopeSession); !errors.Is(err, ErrScopeIncludesSelf) {
+		t.Errorf("Members(self, session) error = %v, want ErrScopeIncludesSelf", err)
+	}
+}
+
+func TestMembers_Group(t *testing.T) {
+	cmd := startGroup(t)
+
+	members, err := Members(cmd.Process.Pid, ScopeGroup)
+	if err != nil {
+		t.Fatalf("Members() error = %v", err)
+	}
+	for _, member := range members {
+		if member.PGID != cmd.Process.Pid {
+			t.Errorf("member %d PGID = %d, want %d", member.PID, member.PGID, cmd.Process.Pid)
+		}
+	}
+
+	single, err := Members(cmd.Process.Pid, ScopeProcess)
+	if err != nil || len(single) != 1 || single[0].PID != cmd.Process.Pid {
+		t.Errorf("Members(process) = %+v, %v, want just the PID", single, err)
+	}
+}
+
+func TestProcessKiller_KillGroup(t *testing.T) {
+	cmd := startGroup(t)
+
+	killer := NewProcessKiller()
+	killer.SystemProcessProtection = false
+	killer.Scope = ScopeGroup
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := killer.Kill(ctx, cmd.Process.Pid, nil)
+	if err != nil {
+		t.Fatalf("Kill() error = %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Kill() failed: %s", result.Message)
+	}
+	if len(result.Members) != 3 {
+		t.Fatalf("Members = %d, want 3", len(result.Members))
+	}
+	for _, member := range result.Members {
+		if !member.Terminated || member.Signal != string(KillMethodSIGTERM) {
+			t.Errorf("member %d (%s) = terminated %v after %q, want SIGTERM", member.PID, member.Name, member.Terminated, member.Signal)
+		}
+	}
+	if result.Method != KillMethodSIGTERM {
+		t.Errorf("Method = %s, want SIGTERM", result.Method)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build windows
+// +build windows
+
+package process
+
+import (
+	"errors"
+	"syscall"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// ErrScopeIncludesSelf is returned when a group or session kill would also hit port-chaser.
+var ErrScopeIncludesSelf = errors.New("target includes port-chaser itself")
+
+// errScopeUnsupported is returned for group and session kills, which have no Windows equivalent.
+var errScopeUnsupported = errors.New("process group and session kills are not supported on Windows")
+
+// scopeMembers is not supported on Windows.
+func scopeMembers(pid int, scope KillScope) ([]models.ProcessMember, error) {
+	return nil, errScopeUnsupported
+}
+
+// signalGroups is not supported on Windows.
+func signalGroups(members []models.ProcessMember, sig syscall.Signal) error {
+	return errScopeUnsupported
+}
@@ -0,0 +1,204 @@
+package process
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// forceWait is how long to wait for a process to disappear after SIGKILL.
+const forceWait = 500 * time.Millisecond
+
+// SignalStep is a single step of a kill strategy: send Signal, then wait up to Wait for the process to exit.
+type SignalStep struct {
+	Signal syscall.Signal // signal to send
+	Wait   time.Duration  // how long to wait for the process to exit before the next step
+}
+
+// KillStrategy is an ordered signal sequence for processes matching its criteria.
+// A strategy with no criteria matches nothing; use DefaultStrategy for the fallback.
+type KillStrategy struct {
+	Name            string       // human-readable strategy name (shown in results)
+	ProcessNames    []string     // process names to match (case-insensitive, exact)
+	CommandContains []string     // substrings to look for in the command line
+	Ports           []int        // port numbers to match
+	Steps           []SignalStep // signals to send in order
+}
+
+// Matches reports whether the strategy applies to the given port.
+// Any matching criterion (process name, command substring or port) is enough.
+func (s KillStrategy) Matches(portInfo *models.PortInfo) bool {
+	if portInfo == nil {
+		return false
+	}
+
+	for _, name := range s.ProcessNames {
+		if strings.EqualFold(name, portInfo.ProcessName) {
+			return true
+		}
+	}
+	for _, substr := range s.CommandContains {
+		if substr != "" && strings.Contains(portInfo.Command, substr) {
+			return true
+		}
+	}
+	for _, port := range s.Ports {
+		if port == portInfo.PortNumber {
+			return true
+		}
+	}
+	return false
+}
+
+// TotalWait returns the sum of all step waits, i.e. the longest the strategy can take.
+func (s KillStrategy) TotalWait() time.Duration {
+	var total time.Duration
+	for _, step := range s.Steps {
+		total += step.Wait
+	}
+	return total
+}
+
+// Describe returns a compact form of the signal sequence, e.g. "SIGINT 10s → SIGKILL".
+func (s KillStrategy) Describe() string {
+	parts := make([]string, 0, len(s.Steps))
+	for i, step := range s.Steps {
+		if i == len(s.Steps)-1 && step.Signal == syscall.SIGKILL {
+			parts = append(parts, SignalName(step.Signal))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", SignalName(step.Signal), step.Wait))
+	}
+	return strings.Join(parts, " → ")
+}
+
+// DefaultStrategy returns the classic two-phase strategy: SIGTERM, wait gracePeriod, then SIGKILL.
+func DefaultStrategy(gracePeriod time.Duration) KillStrategy {
+	return SignalStrategy(syscall.SIGTERM, gracePeriod)
+}
+
+// SignalStrategy sends sig first and falls back to SIGKILL if the process outlives gracePeriod.
+// This is used when the user picks a signal manually for a single kill.
+func SignalStrategy(sig syscall.Signal, gracePeriod time.Duration) KillStrategy {
+	if sig == syscall.SIGKILL {
+		return KillStrategy{
+			Name:  SignalName(sig),
+			Steps: []SignalStep{{Signal: syscall.SIGKILL, Wait: forceWait}},
+		}
+	}
+	return KillStrategy{
+		Name: SignalName(sig),
+		Steps: []SignalStep{
+			{Signal: sig, Wait: gracePeriod},
+			{Signal: syscall.SIGKILL, Wait: forceWait},
+		},
+	}
+}
+
+// BuiltinStrategies returns strategies for well-known servers whose preferred shutdown signal isn't SIGTERM.
+// User-configured strategies are consulted before these.
+func BuiltinStrategies() []KillStrategy {
+	return []KillStrategy{
+		{
+			// SIGINT is PostgreSQL's "fast shutdown": it aborts transactions and exits cleanly
+			Name:         "postgres",
+			ProcessNames: []string{"postgres", "postmaster"},
+			Steps: []SignalStep{
+				{Signal: syscall.SIGINT, Wait: 10 * time.Second},
+				{Signal: syscall.SIGKILL, Wait: forceWait},
+			},
+		},
+		{
+			// SIGQUIT is nginx's graceful shutdown: workers finish in-flight requests
+			Name:         "nginx",
+			ProcessNames: []string{"nginx"},
+			Steps: []SignalStep{
+				{Signal: syscall.SIGQUIT, Wait: 10 * time.Second},
+				{Signal: syscall.SIGKILL, Wait: forceWait},
+			},
+		},
+	}
+}
+
+// signalNames maps signal names to signals available on every platform.
+// Platform-specific signals are added from platformSignals.
+var signalNames = map[string]syscall.Signal{
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGINT":  syscall.SIGINT,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGKILL": syscall.SIGKILL,
+	"SIGTERM": syscall.SIGTERM,
+}
+
+// ParseSignal parses a signal given as "SIGINT", "INT", "int" or a number like "2".
+// Numbers above the platform's highest signal are rejected rather than failing in kill(2) later.
+func ParseSignal(name string) (syscall.Signal, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if num, err := strconv.Atoi(name); err == nil && num > 0 {
+		if num > maxSignal {
+			return 0, fmt.Errorf("signal %d out of range (1-%d)", num, maxSignal)
+		}
+		return syscall.Signal(num), nil
+	}
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	if sig, ok := signalNames[name]; ok {
+		return sig, nil
+	}
+	if sig, ok := platformSignals[name]; ok {
+		return sig, nil
+	}
+	return 0, fmt.Errorf("unknown signal %q", name)
+}
+
+// SignalName returns the conventional name of a signal ("SIGTERM"), or "SIG<n>" if unknown.
+func SignalName(sig syscall.Signal) string {
+	for name, s := range signalNames {
+		if s == sig {
+			return name
+		}
+	}
+	for name, s := range platformSignals {
+		if s == sig {
+			return name
+		}
+	}
+	return "SIG" + strconv.Itoa(int(sig))
+}
+
+// FormatSteps encodes a signal sequence as "SIGINT:10s,SIGKILL:500ms", the form accepted by ParseSteps.
+// It is used to hand a resolved strategy to the `port-chaser kill -steps` subcommand.
+func FormatSteps(steps []SignalStep) string {
+	parts := make([]string, len(steps))
+	for i, step := range steps {
+		parts[i] = SignalName(step.Signal) + ":" + step.Wait.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseSteps parses a signal sequence written by FormatSteps.
+// The wait may be omitted ("SIGKILL"), in which case it defaults to the force-kill wait.
+func ParseSteps(s string) ([]SignalStep, error) {
+	var steps []SignalStep
+	for _, part := range strings.Split(s, ",") {
+		name, wait, hasWait := strings.Cut(strings.TrimSpace(part), ":")
+		sig, err := ParseSignal(name)
+		if err != nil {
+			return nil, err
+		}
+		step := SignalStep{Signal: sig, Wait: forceWait}
+		if hasWait {
+			step.Wait, err = time.ParseDuration(wait)
+			if err != nil || step.Wait < 0 {
+				return nil, fmt.Errorf("invalid wait %q for %s", wait, SignalName(sig))
+			}
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
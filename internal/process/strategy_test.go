@@ -0,0 +1,183 @@
+package process
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+func TestKillStrategy_Matches(t *testing.T) {
+	strategy := KillStrategy{
+		Name:            "java",
+		ProcessNames:    []string{"Postgres"},
+		CommandContains: []string{"java -jar"},
+		Ports:           []int{9090},
+	}
+
+	tests := []struct {
+		name string
+		port *models.PortInfo
+		want bool
+	}{
+		{"process name ignores case", &models.PortInfo{ProcessName: "postgres", PortNumber: 5432}, true},
+		{"command substring", &models.PortInfo{ProcessName: "java", Command: "java -jar app.jar", PortNumber: 8080}, true},
+		{"port number", &models.PortInfo{ProcessName: "node", PortNumber: 9090}, true},
+		{"no match", &models.PortInfo{ProcessName: "node", Command: "node index.js", PortNumber: 3000}, false},
+		{"nil port", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strategy.Matches(tt.port); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessKiller_StrategyFor(t *testing.T) {
+	killer := NewProcessKillerWithGracePeriod(5 * time.Second)
+	custom := KillStrategy{
+		Name:         "custom-postgres",
+		ProcessNames: []string{"postgres"},
+		Steps:        []SignalStep{{Signal: syscall.SIGTERM, Wait: 20 * time.Second}},
+	}
+
+	tests := []struct {
+		name       string
+		strategies []KillStrategy
+		port       *models.PortInfo
+		want       string
+		firstSig   syscall.Signal
+	}{
+		{"builtin postgres", killer.Strategies, &models.PortInfo{ProcessName: "postgres"}, "postgres", syscall.SIGINT},
+		{"builtin nginx", killer.Strategies, &models.PortInfo{ProcessName: "nginx"}, "nginx", syscall.SIGQUIT},
+		{"user strategy first", append([]KillStrategy{custom}, killer.Strategies...), &models.PortInfo{ProcessName: "postgres"}, "custom-postgres", syscall.SIGTERM},
+		{"fallback", killer.Strategies, &models.PortInfo{ProcessName: "node"}, "default", syscall.SIGTERM},
+		{"nil port", killer.Strategies, nil, "default", syscall.SIGTERM},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := *killer
+			k.Strategies = tt.strategies
+
+			got := k.StrategyFor(tt.port)
+			if got.Name != tt.want {
+				t.Errorf("StrategyFor() = %q, want %q", got.Name, tt.want)
+			}
+			if got.Steps[0].Signal != tt.firstSig {
+				t.Errorf("first signal = %v, want %v", got.Steps[0].Signal, tt.firstSig)
+			}
+		})
+	}
+
+	if got := killer.StrategyFor(nil).Steps[0].Wait; got != 5*time.Second {
+		t.Errorf("default grace = %v, want 5s", got)
+	}
+}
+
+func TestSignalStrategy(t *testing.T) {
+	if got := SignalStrategy(syscall.SIGINT, 3*time.Second).Describe(); got != "SIGINT 3s → SIGKILL" {
+		t.Errorf("Describe() = %q", got)
+	}
+
+	kill := SignalStrategy(syscall.SIGKILL, 3*time.Second)
+	if len(kill.Steps) != 1 || kill.Steps[0].Signal != syscall.SIGKILL {
+		t.Errorf("SIGKILL strategy steps = %+v, want a single SIGKILL", kill.Steps)
+	}
+	if kill.TotalWait() != forceWait {
+		t.Errorf("TotalWait() = %v, want %v", kill.TotalWait(), forceWait)
+	}
+}
+
+func TestParseSteps(t *testing.T) {
+	steps := []SignalStep{{Signal: syscall.SIGINT, Wait: 10 * time.Second}, {Signal: syscall.SIGKILL, Wait: forceWait}}
+	encoded := FormatSteps(steps)
+	if encoded != "SIGINT:10s,SIGKILL:500ms" {
+		t.Errorf("FormatSteps() = %q", encoded)
+	}
+
+	got, err := ParseSteps(encoded)
+	if err != nil {
+		t.Fatalf("ParseSteps(%q) error = %v", encoded, err)
+	}
+	if !reflect.DeepEqual(got, steps) {
+		t.Errorf("ParseSteps(%q) = %+v, want %+v", encoded, got, steps)
+	}
+
+	if got, err := ParseSteps("term:1s, kill"); err != nil || len(got) != 2 || got[1].Wait != forceWait {
+		t.Errorf("ParseSteps without wait = %+v, %v", got, err)
+	}
+	for _, bad := range []string{"", "SIGBOGUS:1s", "SIGTERM:soon", "SIGTERM:-1s", "9999:1s"} {
+		if _, err := ParseSteps(bad); err == nil {
+			t.Errorf("ParseSteps(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestParseSignal(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    syscall.Signal
+		wantErr bool
+	}{
+		{"SIGINT", syscall.SIGINT, false},
+		{"quit", syscall.SIGQUIT, false},
+		{" term ", syscall.SIGTERM, false},
+		{"9", syscall.SIGKILL, false},
+		{"9999", 0, true},
+		{strconv.Itoa(maxSignal), syscall.Signal(maxSignal), false},
+		{strconv.Itoa(maxSignal + 1), 0, true},
+		{"SIGBOGUS", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSignal(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSignal(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSignal(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	if got := SignalName(syscall.SIGHUP); got != "SIGHUP" {
+		t.Errorf("SignalName(SIGHUP) = %q", got)
+	}
+}
+
+func TestProcessKiller_KillWithSignal(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start test process: %v", err)
+	}
+	// Reap the child so it doesn't linger as a zombie that still answers signal 0
+	go cmd.Wait()
+
+	killer := NewProcessKiller()
+	killer.SystemProcessProtection = false
+
+	result, err := killer.KillWithSignal(context.Background(), cmd.Process.Pid, syscall.SIGINT, nil)
+	if err != nil {
+		t.Fatalf("KillWithSignal() error = %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("KillWithSignal() failed: %s", result.Message)
+	}
+	if result.Method != KillMethodSIGINT {
+		t.Errorf("Method = %s, want %s", result.Method, KillMethodSIGINT)
+	}
+	if result.Strategy != "SIGINT" {
+		t.Errorf("Strategy = %q, want SIGINT", result.Strategy)
+	}
+}
@@ -0,0 +1,55 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// ErrSuspendUnsupported is returned by Suspend and Resume on platforms without job-control signals.
+var ErrSuspendUnsupported = errors.New("suspending processes is not supported on this platform")
+
+// Suspend pauses a process with SIGSTOP. It keeps its port and memory but stops running,
+// which frees the CPU or simulates an unresponsive service without a slow restart.
+// The same PID, protection-policy and stale-target checks as a kill apply, so "confirm"
+// rules pass only when Confirmed is set.
+func (k *ProcessKiller) Suspend(pid int, portInfo *models.PortInfo) error {
+	if reason := k.protected(pid, portInfo); reason != "" {
+		return errors.New(reason)
+	}
+	return sendControl(pid, portInfo, suspendSignal)
+}
+
+// Resume continues a process paused by Suspend with SIGCONT.
+// It is not subject to the protection policy, so anything suspended can always be resumed.
+func (k *ProcessKiller) Resume(pid int, portInfo *models.PortInfo) error {
+	return sendControl(pid, portInfo, resumeSignal)
+}
+
+// sendControl sends a job-control signal to the process, refusing recycled PIDs.
+func sendControl(pid int, portInfo *models.PortInfo, sig syscall.Signal) error {
+	if !jobControlSupported {
+		return ErrSuspendUnsupported
+	}
+	// Signalling PID 0 or a negative PID would hit whole process groups
+	if pid <= 0 {
+		return fmt.Errorf("invalid PID %d", pid)
+	}
+
+	proc, err := findProcess(pid)
+	if err != nil {
+		return fmt.Errorf("process PID %d is not running", pid)
+	}
+	defer proc.Release()
+
+	if reason := staleReason(pid, portInfo); reason != "" {
+		return fmt.Errorf("%w: %s", ErrStaleTarget, reason)
+	}
+
+	if err := proc.Signal(sig); err != nil {
+		return fmt.Errorf("%s to PID %d failed: %w", SignalName(sig), pid, err)
+	}
+	return nil
+}
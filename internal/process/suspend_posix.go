@@ -0,0 +1,15 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package process
+
+import "syscall"
+
+// jobControlSupported reports whether Suspend and Resume work on this platform.
+const jobControlSupported = true
+
+// suspendSignal and resumeSignal are the job-control signals used by Suspend and Resume.
+const (
+	suspendSignal = syscall.SIGSTOP
+	resumeSignal  = syscall.SIGCONT
+)
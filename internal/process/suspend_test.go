@@ -0,0 +1,94 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package process
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	gopsprocess "github.com/shirou/gopsutil/v3/process"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// waitForStopped polls the process status until its stopped state matches want or the deadline passes.
+func waitForStopped(t *testing.T, pid int, want bool) bool {
+	t.Helper()
+
+	proc, err := gopsprocess.NewProcess(int32(pid))
+	if err != nil {
+		t.Fatalf("NewProcess(%d) error = %v", pid, err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if status, err := proc.Status(); err == nil && len(status) > 0 && (status[0] == gopsprocess.Stop) == want {
+			return true
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return false
+}
+
+func TestProcessKiller_SuspendResume(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start test process: %v", err)
+	}
+	defer cmd.Process.Kill()
+	pid := cmd.Process.Pid
+
+	killer := NewProcessKiller()
+	if err := killer.Suspend(pid, nil); err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+	if !waitForStopped(t, pid, true) {
+		t.Error("process should be stopped after Suspend()")
+	}
+
+	if err := killer.Resume(pid, nil); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if !waitForStopped(t, pid, false) {
+		t.Error("process should be running again after Resume()")
+	}
+}
+
+func TestProcessKiller_SuspendRefusals(t *testing.T) {
+	killer := NewProcessKiller()
+
+	if err := killer.Suspend(0, nil); err == nil {
+		t.Error("Suspend(0) should fail")
+	}
+
+	system := &models.PortInfo{PortNumber: 22, PID: 1, ProcessName: "launchd", IsSystem: true}
+	if err := killer.Suspend(1, system); err == nil {
+		t.Error("Suspend() of a protected system process should fail")
+	}
+
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start test process: %v", err)
+	}
+	defer cmd.Process.Kill()
+
+	// A "confirm" rule refuses until the user confirmed, as for kills
+	guarded := &models.PortInfo{PortNumber: 80, PID: cmd.Process.Pid, ProcessName: "nginx"}
+	if err := killer.Suspend(cmd.Process.Pid, guarded); err == nil || !strings.Contains(err.Error(), "needs confirmation") {
+		t.Errorf("unconfirmed Suspend() of a privileged port error = %v, want a confirmation refusal", err)
+	}
+	confirmed := *killer
+	confirmed.Confirmed = true
+	if err := confirmed.Suspend(cmd.Process.Pid, guarded); err != nil {
+		t.Errorf("confirmed Suspend() error = %v", err)
+	}
+	confirmed.Resume(cmd.Process.Pid, guarded)
+
+	stale := &models.PortInfo{PortNumber: 3000, PID: cmd.Process.Pid, StartTime: time.Unix(1000, 0)}
+	if err := killer.Suspend(cmd.Process.Pid, stale); !errors.Is(err, ErrStaleTarget) {
+		t.Errorf("Suspend() of a recycled PID error = %v, want ErrStaleTarget", err)
+	}
+}
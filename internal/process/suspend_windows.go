@@ -0,0 +1,16 @@
+//go:build windows
+// +build windows
+
+package process
+
+import "syscall"
+
+// jobControlSupported reports whether Suspend and Resume work on this platform.
+// Windows has no SIGSTOP/SIGCONT equivalent that Process.Signal can deliver.
+const jobControlSupported = false
+
+// suspendSignal and resumeSignal are unused placeholders on Windows.
+const (
+	suspendSignal syscall.Signal = 0
+	resumeSignal  syscall.Signal = 0
+)
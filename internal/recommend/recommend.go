@@ -0,0 +1,191 @@
+// Package recommend scores how strongly killing each listening port is suggested.
+// The score combines how often and how recently the port was killed, whether it is usually
+// killed at this time of day, how long its process has been running, and whether the process
+// is orphaned or idle. Every factor that adds points comes with a reason, so the UI can say
+// why a port is suggested.
+package recommend
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// Factor names, as stored in models.RecommendationReason.Factor.
+const (
+	FactorFrequency = "frequency"
+	FactorRecency   = "recency"
+	FactorTimeOfDay = "time_of_day"
+	FactorAge       = "age"
+	FactorOrphaned  = "orphaned"
+	FactorIdle      = "idle"
+)
+
+// Weights are the most points each factor can add; the defaults add up to 100.
+type Weights struct {
+	// Frequency is reached at FrequentKills kills in the KillCount window
+	Frequency float64
+	// Recency is reached by a kill just now and halves every HalfLife after it
+	Recency float64
+	// TimeOfDay is reached when every recent kill happened within an hour of the current time of day
+	TimeOfDay float64
+	// Age is reached by a process running for a day or more
+	Age float64
+	// Orphaned is added when the process's parent exited
+	Orphaned float64
+	// Idle is added when no client is connected to the port
+	Idle float64
+}
+
+// DefaultWeights favours the kill history, with process state as a tie-breaker.
+func DefaultWeights() Weights {
+	return Weights{
+		Frequency: 30,
+		Recency:   25,
+		TimeOfDay: 15,
+		Age:       10,
+		Orphaned:  10,
+		Idle:      10,
+	}
+}
+
+// FrequentKills is the kill count that earns the full frequency weight.
+const FrequentKills = 5
+
+// DefaultHalfLife is how long it takes the recency points of a kill to halve.
+const DefaultHalfLife = 3 * 24 * time.Hour
+
+// minPoints is the smallest contribution reported as a reason; less is noise.
+const minPoints = 0.5
+
+// Engine scores ports. It is a detector, so it can run at the end of a scan pipeline,
+// after the detectors that fill in the kill history and process activity.
+type Engine struct {
+	// Weights are the most points each factor can add
+	Weights Weights
+	// HalfLife is how long it takes the recency points of a kill to halve
+	HalfLife time.Duration
+	// Days is the window PortInfo.KillCount and KillHours cover, for the explanations
+	Days int
+	// Now returns the current time
+	Now func() time.Time
+}
+
+// New creates an Engine with the default weights.
+func New() *Engine {
+	return &Engine{
+		Weights:  DefaultWeights(),
+		HalfLife: DefaultHalfLife,
+		Days:     models.DefaultKillCountDays,
+		Now:      time.Now,
+	}
+}
+
+// Score returns port's recommendation. System processes are never recommended and score 0.
+func (e *Engine) Score(port models.PortInfo) models.Recommendation {
+	var rec models.Recommendation
+	if port.IsSystem {
+		return rec
+	}
+	now := e.Now()
+	add := func(factor string, points float64, detail string) {
+		if points < minPoints {
+			return
+		}
+		rec.Score += points
+		rec.Reasons = append(rec.Reasons, models.RecommendationReason{Factor: factor, Points: points, Detail: detail})
+	}
+
+	if port.KillCount > 0 {
+		add(FactorFrequency, e.Weights.Frequency*math.Min(float64(port.KillCount)/FrequentKills, 1),
+			fmt.Sprintf("killed %s in the last %d days", times(port.KillCount), e.Days))
+	}
+	if !port.LastKilled.IsZero() && e.HalfLife > 0 {
+		since := now.Sub(port.LastKilled)
+		if since < 0 {
+			since = 0
+		}
+		add(FactorRecency, e.Weights.Recency*math.Pow(0.5, float64(since)/float64(e.HalfLife)),
+			"last killed "+ago(since))
+	}
+	if near, total := killsNear(port.KillHours, now.Hour()); total >= 2 && near > 0 {
+		add(FactorTimeOfDay, e.Weights.TimeOfDay*float64(near)/float64(total),
+			fmt.Sprintf("%d of %d kills were around %02d:00", near, total, now.Hour()))
+	}
+	if !port.StartTime.IsZero() {
+		running := now.Sub(port.StartTime)
+		add(FactorAge, e.Weights.Age*math.Max(0, math.Min(running.Hours()/24, 1)),
+			"running for "+duration(running))
+	}
+	if port.Orphaned {
+		add(FactorOrphaned, e.Weights.Orphaned, "orphaned (its parent exited)")
+	}
+	if port.Idle {
+		add(FactorIdle, e.Weights.Idle, "no clients connected")
+	}
+
+	sort.SliceStable(rec.Reasons, func(i, j int) bool { return rec.Reasons[i].Points > rec.Reasons[j].Points })
+	return rec
+}
+
+// Detect sets each port's Recommendation.
+func (e *Engine) Detect(ports []models.PortInfo) ([]models.PortInfo, error) {
+	result := make([]models.PortInfo, len(ports))
+	for i, port := range ports {
+		rec := e.Score(port)
+		port.Recommendation = &rec
+		result[i] = port
+	}
+	return result, nil
+}
+
+// IsAvailable always returns true since scoring only needs what the other detectors found.
+func (e *Engine) IsAvailable() bool {
+	return true
+}
+
+// killsNear returns how many of the kills counted in hours (by hour of day) happened within an
+// hour of hour, and how many kills there were in all.
+func killsNear(hours []int, hour int) (near, total int) {
+	if len(hours) != 24 {
+		return 0, 0
+	}
+	for h, kills := range hours {
+		total += kills
+		if d := (h - hour + 24) % 24; d <= 1 || d == 23 {
+			near += kills
+		}
+	}
+	return near, total
+}
+
+// times describes a kill count, e.g. "once" or "5 times".
+func times(n int) string {
+	if n == 1 {
+		return "once"
+	}
+	return fmt.Sprintf("%d times", n)
+}
+
+// ago describes how long ago something happened, e.g. "3h ago".
+func ago(d time.Duration) string {
+	if d < time.Minute {
+		return "just now"
+	}
+	return duration(d) + " ago"
+}
+
+// duration describes a duration to the largest whole unit, e.g. "45m", "3h" or "5d".
+func duration(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,177 @@
+package recommend
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// fixedEngine returns an engine with the default weights whose clock is stopped at now.
+func fixedEngine(now time.Time) *Engine {
+	e := New()
+	e.Now = func() time.Time { return now }
+	return e
+}
+
+// hoursAt returns kill counts by hour of day with kills at each of the given hours.
+func hoursAt(hours ...int) []int {
+	counts := make([]int, 24)
+	for _, h := range hours {
+		counts[h]++
+	}
+	return counts
+}
+
+func TestEngine_Score(t *testing.T) {
+	now := time.Date(2026, 10, 18, 14, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name        string
+		port        models.PortInfo
+		wantScore   float64
+		wantFactors []string
+		recommended bool
+	}{
+		{
+			name:        "never killed, no process state",
+			port:        models.PortInfo{PortNumber: 3000},
+			wantScore:   0,
+			recommended: false,
+		},
+		{
+			name: "killed often, just now, at this time of day",
+			port: models.PortInfo{PortNumber: 3000, KillCount: 5, LastKilled: now,
+				KillHours: hoursAt(13, 14, 14, 15, 15)},
+			wantScore:   30 + 25 + 15,
+			wantFactors: []string{FactorFrequency, FactorRecency, FactorTimeOfDay},
+			recommended: true,
+		},
+		{
+			name: "recency halves every three days",
+			port: models.PortInfo{PortNumber: 3000, KillCount: 1, LastKilled: now.Add(-DefaultHalfLife),
+				KillHours: hoursAt(14)},
+			wantScore:   6 + 12.5,
+			wantFactors: []string{FactorRecency, FactorFrequency},
+			recommended: false,
+		},
+		{
+			name: "half the kills at another time of day",
+			port: models.PortInfo{PortNumber: 3000, KillCount: 4, LastKilled: now.Add(-2 * DefaultHalfLife),
+				KillHours: hoursAt(2, 3, 13, 14)},
+			wantScore:   24 + 6.25 + 7.5,
+			wantFactors: []string{FactorFrequency, FactorTimeOfDay, FactorRecency},
+			recommended: false,
+		},
+		{
+			name: "forgotten orphaned idle server that was killed before",
+			port: models.PortInfo{PortNumber: 8080, KillCount: 2, LastKilled: now.Add(-2 * DefaultHalfLife),
+				StartTime: now.Add(-3 * 24 * time.Hour), Orphaned: true, Idle: true},
+			wantScore:   12 + 6.25 + 10 + 10 + 10,
+			wantFactors: []string{FactorFrequency, FactorAge, FactorOrphaned, FactorIdle, FactorRecency},
+			recommended: true,
+		},
+		{
+			name:        "process age ramps up over a day",
+			port:        models.PortInfo{PortNumber: 8080, StartTime: now.Add(-12 * time.Hour)},
+			wantScore:   5,
+			wantFactors: []string{FactorAge},
+			recommended: false,
+		},
+		{
+			name: "system processes are never recommended",
+			port: models.PortInfo{PortNumber: 22, IsSystem: true, KillCount: 9, LastKilled: now,
+				Orphaned: true, Idle: true},
+			wantScore:   0,
+			recommended: false,
+		},
+	}
+
+	e := fixedEngine(now)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := e.Score(tt.port)
+			if math.Abs(rec.Score-tt.wantScore) > 0.01 {
+				t.Errorf("Score = %.2f, want %.2f (%+v)", rec.Score, tt.wantScore, rec.Reasons)
+			}
+			if len(rec.Reasons) != len(tt.wantFactors) {
+				t.Fatalf("Reasons = %+v, want factors %v", rec.Reasons, tt.wantFactors)
+			}
+			for i, factor := range tt.wantFactors {
+				if rec.Reasons[i].Factor != factor {
+					t.Errorf("Reasons[%d] = %+v, want %s (most points first)", i, rec.Reasons[i], factor)
+				}
+			}
+
+			tt.port.Recommendation = &rec
+			if got := tt.port.IsRecommended(); got != tt.recommended {
+				t.Errorf("IsRecommended() = %v, want %v", got, tt.recommended)
+			}
+		})
+	}
+}
+
+func TestEngine_Explain(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.Local)
+	rec := fixedEngine(now).Score(models.PortInfo{
+		PortNumber: 3000,
+		KillCount:  3,
+		LastKilled: now.Add(-3 * time.Hour),
+		KillHours:  hoursAt(8, 9, 20),
+		Idle:       true,
+	})
+
+	want := "last killed 3h ago, killed 3 times in the last 30 days, 2 of 3 kills were around 09:00, no clients connected"
+	if got := rec.Explain(); got != want {
+		t.Errorf("Explain() = %q, want %q", got, want)
+	}
+}
+
+func TestEngine_Detect(t *testing.T) {
+	now := time.Now()
+	ports := []models.PortInfo{
+		{PortNumber: 3000, KillCount: 5, LastKilled: now},
+		{PortNumber: 8080},
+	}
+
+	got, err := fixedEngine(now).Detect(ports)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if got[0].Recommendation == nil || !got[0].IsRecommended() {
+		t.Errorf("port 3000 = %+v, want it scored and recommended", got[0].Recommendation)
+	}
+	if got[1].Recommendation == nil || got[1].IsRecommended() {
+		t.Errorf("port 8080 = %+v, want it scored and not recommended", got[1].Recommendation)
+	}
+	if got[0].Recommendation == got[1].Recommendation {
+		t.Error("ports should not share a recommendation")
+	}
+	if ports[0].Recommendation != nil {
+		t.Error("Detect() shouldn't modify its input")
+	}
+}
+
+func TestKillsNear(t *testing.T) {
+	tests := []struct {
+		name      string
+		hours     []int
+		hour      int
+		wantNear  int
+		wantTotal int
+	}{
+		{"no kills", nil, 12, 0, 0},
+		{"same and neighbouring hours", hoursAt(11, 12, 13, 15), 12, 3, 4},
+		{"wraps around midnight", hoursAt(23, 0, 1, 12), 0, 3, 4},
+		{"wraps the other way", hoursAt(0, 22), 23, 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			near, total := killsNear(tt.hours, tt.hour)
+			if near != tt.wantNear || total != tt.wantTotal {
+				t.Errorf("killsNear() = %d of %d, want %d of %d", near, total, tt.wantNear, tt.wantTotal)
+			}
+		})
+	}
+}
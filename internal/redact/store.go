@@ -0,0 +1,100 @@
+package redact
+
+import (
+	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/storage"
+)
+
+// Store wraps s so that command lines are redacted on the way in and on the way out: nothing
+// new is stored with its secrets, and history recorded before redaction was turned on (or
+// imported from elsewhere) is redacted whenever it is read or exported.
+// Launch contexts are stored as captured, since restarting a process needs its real arguments
+// and environment, and are redacted when read. With a nil Redactor, s is returned as it is.
+func Store(s storage.Storage, r *Redactor) storage.Storage {
+	if r == nil || s == nil {
+		return s
+	}
+	return &store{Storage: s, redactor: r}
+}
+
+// StoreForRestart is like Store but returns launch contexts as stored, for the TUI: it shows
+// only their working directory and hands them to restarts. Never export from it.
+func StoreForRestart(s storage.Storage, r *Redactor) storage.Storage {
+	if r == nil || s == nil {
+		return s
+	}
+	return &store{Storage: s, redactor: r, rawLaunch: true}
+}
+
+// store is a storage.Storage that redacts the history and timeline it writes and reads.
+type store struct {
+	storage.Storage
+	redactor *Redactor
+	// rawLaunch returns launch contexts unredacted
+	rawLaunch bool
+}
+
+// RecordKill stores entry with its command line redacted.
+func (s *store) RecordKill(entry models.HistoryEntry) error {
+	return s.Storage.RecordKill(s.write(entry))
+}
+
+// ImportEntries stores the entries with their command lines redacted. Duplicates are still
+// detected, since they are matched on the kill time, port, PID and process name.
+func (s *store) ImportEntries(entries []models.HistoryEntry) (int, error) {
+	redacted := make([]models.HistoryEntry, len(entries))
+	for i, entry := range entries {
+		redacted[i] = s.write(entry)
+	}
+	return s.Storage.ImportEntries(redacted)
+}
+
+// GetHistory returns the newest limit entries, redacted.
+func (s *store) GetHistory(limit int) ([]models.HistoryEntry, error) {
+	entries, err := s.Storage.GetHistory(limit)
+	for i := range entries {
+		entries[i] = s.read(entries[i])
+	}
+	return entries, err
+}
+
+// QueryHistory returns one page of the matching entries, redacted.
+func (s *store) QueryHistory(q models.HistoryQuery) (models.HistoryPage, error) {
+	page, err := s.Storage.QueryHistory(q)
+	for i := range page.Entries {
+		page.Entries[i] = s.read(page.Entries[i])
+	}
+	return page, err
+}
+
+// write redacts what is stored of entry: the command line, but not the launch context.
+func (s *store) write(entry models.HistoryEntry) models.HistoryEntry {
+	entry.Command = s.redactor.String(entry.Command)
+	return entry
+}
+
+// read redacts entry as it is returned, keeping the launch context as stored for rawLaunch.
+func (s *store) read(entry models.HistoryEntry) models.HistoryEntry {
+	if s.rawLaunch {
+		return s.write(entry)
+	}
+	return s.redactor.Entry(entry)
+}
+
+// RecordPortEvents stores the timeline events redacted.
+func (s *store) RecordPortEvents(events []models.PortEvent) error {
+	redacted := make([]models.PortEvent, len(events))
+	for i, event := range events {
+		redacted[i] = s.redactor.Event(event)
+	}
+	return s.Storage.RecordPortEvents(redacted)
+}
+
+// PortEvents returns the matching timeline events, redacted.
+func (s *store) PortEvents(q models.PortEventQuery) ([]models.PortEvent, error) {
+	events, err := s.Storage.PortEvents(q)
+	for i := range events {
+		events[i] = s.redactor.Event(events[i])
+	}
+	return events, err
+}
@@ -0,0 +1,69 @@
+package redact
+
+import (
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/storage"
+)
+
+func TestStore(t *testing.T) {
+	r, _ := New()
+	mem := storage.NewMemory()
+	now := time.Now()
+
+	// Recorded before redaction was turned on
+	if err := mem.RecordKill(models.HistoryEntry{PortNumber: 3000, ProcessName: "node", Command: "node --token=old", KilledAt: now.Add(-time.Hour)}); err != nil {
+		t.Fatalf("RecordKill() error = %v", err)
+	}
+
+	s := Store(mem, r)
+	entry := models.HistoryEntry{PortNumber: 8080, ProcessName: "rails", Command: "rails s --password=new", KilledAt: now,
+		Launch: &models.LaunchContext{Args: []string{"rails", "s", "--password=new"}}}
+	if err := s.RecordKill(entry); err != nil {
+		t.Fatalf("RecordKill() error = %v", err)
+	}
+	if n, err := s.ImportEntries([]models.HistoryEntry{{PortNumber: 9000, ProcessName: "php", Command: "php -S :9000 api_key=imported", KilledAt: now.Add(-2 * time.Hour)}}); n != 1 || err != nil {
+		t.Fatalf("ImportEntries() = %d, %v", n, err)
+	}
+	if err := s.RecordPortEvents([]models.PortEvent{{Port: 3000, Kind: models.PortEventAppeared, PID: 1, Command: "node --secret=ev", At: now}}); err != nil {
+		t.Fatalf("RecordPortEvents() error = %v", err)
+	}
+
+	// No new command line reaches the backend with its secret; the launch context is kept for restarts
+	raw, _ := mem.GetHistory(10)
+	if raw[0].Command != "rails s --password="+mask || raw[2].Command != "php -S :9000 api_key="+mask {
+		t.Errorf("stored entries = %+v, want their commands redacted", raw)
+	}
+	if raw[0].Launch.Args[2] != "--password=new" {
+		t.Errorf("stored launch = %+v, want it as captured", raw[0].Launch)
+	}
+	rawEvents, _ := mem.PortEvents(models.PortEventQuery{})
+	if rawEvents[0].Command != "node --secret="+mask {
+		t.Errorf("stored events = %+v, want them redacted", rawEvents)
+	}
+	if raw[1].Command != "node --token=old" {
+		t.Fatalf("the entry recorded around the wrapper should be stored as it was")
+	}
+
+	// Older entries are redacted when read
+	page, err := s.QueryHistory(models.HistoryQuery{})
+	if err != nil || len(page.Entries) != 3 || page.Entries[1].Command != "node --token="+mask {
+		t.Errorf("QueryHistory() = %+v, %v; want every entry redacted", page.Entries, err)
+	}
+	history, err := s.GetHistory(10)
+	if err != nil || history[1].Command != "node --token="+mask || history[0].Launch.Args[2] != "--password="+mask {
+		t.Errorf("GetHistory() = %+v, %v; want every entry and launch context redacted", history, err)
+	}
+
+	// The TUI gets the real launch context to restart from, and redacted commands
+	restart, err := StoreForRestart(mem, r).GetHistory(10)
+	if err != nil || restart[0].Launch.Args[2] != "--password=new" || restart[0].Launch.IsRedacted() || restart[1].Command != "node --token="+mask {
+		t.Errorf("StoreForRestart().GetHistory() = %+v, %v", restart, err)
+	}
+
+	if Store(mem, nil) != storage.Storage(mem) {
+		t.Error("Store() without a Redactor should return the store as it is")
+	}
+}
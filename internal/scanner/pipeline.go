@@ -0,0 +1,57 @@
+package scanner
+
+import (
+	"github.com/manson/port-chaser/internal/detector"
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// Pipeline wraps a Scanner and passes every result through a chain of detectors.
+// Detectors enrich ports (Docker, process managers, ...) and run in the order given.
+type Pipeline struct {
+	base      Scanner
+	detectors []detector.Detector
+}
+
+// NewPipeline creates a Pipeline that enriches the results of base with the given detectors.
+func NewPipeline(base Scanner, detectors ...detector.Detector) *Pipeline {
+	return &Pipeline{
+		base:      base,
+		detectors: detectors,
+	}
+}
+
+// Scan performs a complete port scan and runs the results through all detectors.
+func (p *Pipeline) Scan() ([]models.PortInfo, error) {
+	ports, err := p.base.Scan()
+	if err != nil {
+		return nil, err
+	}
+	return p.enrich(ports), nil
+}
+
+// ScanByPort returns detailed, enriched info for a specific port number.
+func (p *Pipeline) ScanByPort(portNumber int) (*models.PortInfo, error) {
+	port, err := p.base.ScanByPort(portNumber)
+	if err != nil || port == nil {
+		return port, err
+	}
+
+	enriched := p.enrich([]models.PortInfo{*port})
+	return &enriched[0], nil
+}
+
+// enrich runs each available detector over the ports.
+// Enrichment is best effort: a failing detector leaves the ports unchanged.
+func (p *Pipeline) enrich(ports []models.PortInfo) []models.PortInfo {
+	for _, d := range p.detectors {
+		if !d.IsAvailable() {
+			continue
+		}
+		enriched, err := d.Detect(ports)
+		if err != nil {
+			continue
+		}
+		ports = enriched
+	}
+	return ports
+}
@@ -0,0 +1,67 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/manson/port-chaser/internal/detector"
+	"github.com/manson/port-chaser/internal/models"
+)
+
+type stubScanner struct {
+	ports []models.PortInfo
+}
+
+func (s *stubScanner) Scan() ([]models.PortInfo, error) {
+	return s.ports, nil
+}
+
+func (s *stubScanner) ScanByPort(portNumber int) (*models.PortInfo, error) {
+	for _, port := range s.ports {
+		if port.PortNumber == portNumber {
+			return &port, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestPipeline_Scan(t *testing.T) {
+	base := &stubScanner{ports: []models.PortInfo{
+		{PortNumber: 3000, PID: 1001},
+		{PortNumber: 8080, PID: 1002},
+	}}
+
+	docker := detector.NewMockDetector()
+	docker.SetDockerInfo(3000, models.DockerInfo{ContainerID: "abc", ContainerName: "web", ImageName: "node:20"})
+
+	unavailable := detector.NewMockDetector()
+	unavailable.SetAvailable(false)
+
+	pipeline := NewPipeline(base, unavailable, docker)
+
+	ports, err := pipeline.Scan()
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(ports) != 2 {
+		t.Fatalf("port count = %d, want 2", len(ports))
+	}
+	if !ports[0].IsDocker || ports[0].ContainerName != "web" {
+		t.Errorf("port 3000 not enriched: %+v", ports[0])
+	}
+	if ports[1].IsDocker {
+		t.Error("port 8080 should not be Docker")
+	}
+
+	port, err := pipeline.ScanByPort(3000)
+	if err != nil {
+		t.Fatalf("ScanByPort() error = %v", err)
+	}
+	if port == nil || !port.IsDocker {
+		t.Errorf("ScanByPort() result not enriched: %+v", port)
+	}
+
+	port, err = pipeline.ScanByPort(9999)
+	if err != nil || port != nil {
+		t.Errorf("ScanByPort(9999) = %v, %v, want nil, nil", port, err)
+	}
+}
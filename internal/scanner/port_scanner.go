@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/manson/port-chaser/internal/models"
@@ -106,6 +107,11 @@ func (s *PortScanner) fillProcessInfo(portInfo *models.PortInfo) error {
 		for _, conn := range conns {
 			if conn.Laddr.Port == uint32(portInfo.PortNumber) &&
 				(conn.Status == "ESTABLISHED" || conn.Status == "LISTEN") {
+				portInfo.Address = conn.Laddr.IP
+				portInfo.Protocol = "tcp"
+				if strings.Contains(conn.Laddr.IP, ":") {
+					portInfo.Protocol = "tcp6"
+				}
 				return s.populatePortInfoFromProcess(p, portInfo)
 			}
 		}
@@ -146,6 +152,18 @@ func (s *PortScanner) populatePortInfoFromProcess(p *process.Process, portInfo *
 		}
 	}
 
+	// Identity fields let the killer detect a PID that was recycled after the scan
+	if created, err := p.CreateTime(); err == nil {
+		portInfo.StartTime = time.UnixMilli(created)
+	}
+	if exe, err := p.Exe(); err == nil {
+		portInfo.Executable = exe
+	}
+	// Project-scoped labels match on the working directory
+	if cwd, err := p.Cwd(); err == nil {
+		portInfo.WorkDir = cwd
+	}
+
 	username, err := p.Username()
 	if err == nil {
 		portInfo.User = username
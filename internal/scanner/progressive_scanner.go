@@ -239,6 +239,16 @@ func (s *ProgressiveScanner) enrichPorts(initialPorts []models.PortInfo) {
 					enriched.IsDocker = s.isDockerProcess(enriched.Command)
 				}
 
+				if created, err := p.CreateTime(); err == nil {
+					enriched.StartTime = time.UnixMilli(created)
+				}
+				if exe, err := p.Exe(); err == nil {
+					enriched.Executable = exe
+				}
+				if cwd, err := p.Cwd(); err == nil {
+					enriched.WorkDir = cwd
+				}
+
 				break
 			}
 		}
@@ -156,7 +156,7 @@ func (r *ScanResult) DockerPorts() []models.PortInfo {
 	})
 }
 
-// RecommendedPorts returns ports that are frequently killed (KillCount >= 3).
+// RecommendedPorts returns ports recommended for killing (see PortInfo.IsRecommended).
 // These might be processes the user often wants to terminate.
 func (r *ScanResult) RecommendedPorts() []models.PortInfo {
 	return r.FilteredPorts(func(p models.PortInfo) bool {
@@ -0,0 +1,462 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// backends opens a fresh, empty instance of every Storage implementation.
+var backends = []struct {
+	name string
+	open func(t *testing.T) Storage
+}{
+	{"sqlite", func(t *testing.T) Storage { return newTestSQLite(t) }},
+	{"jsonl", func(t *testing.T) Storage { return newTestJSONL(t, filepath.Join(t.TempDir(), "history.jsonl")) }},
+	{"memory", func(t *testing.T) Storage { return NewMemory() }},
+}
+
+// forEachBackend runs test against a fresh instance of every backend.
+func forEachBackend(t *testing.T, test func(t *testing.T, s Storage)) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			test(t, b.open(t))
+		})
+	}
+}
+
+// record stores entries, failing the test on the first error.
+func record(t *testing.T, s Storage, entries ...models.HistoryEntry) {
+	t.Helper()
+	for _, entry := range entries {
+		if err := s.RecordKill(entry); err != nil {
+			t.Fatalf("RecordKill() error = %v", err)
+		}
+	}
+}
+
+// ports returns the port numbers of entries, in order.
+func ports(entries []models.HistoryEntry) []int {
+	list := []int{}
+	for _, entry := range entries {
+		list = append(list, entry.PortNumber)
+	}
+	return list
+}
+
+func TestConformance_RecordAndGetHistory(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s Storage) {
+		now := time.Now()
+		record(t, s, exportEntries(now)...)
+		record(t, s, models.HistoryEntry{PortNumber: 5432, ProcessName: "postgres", PID: 303, KilledAt: now})
+
+		history, err := s.GetHistory(2)
+		if err != nil {
+			t.Fatalf("GetHistory() error = %v", err)
+		}
+		if got := ports(history); !reflect.DeepEqual(got, []int{5432, 8080}) {
+			t.Fatalf("GetHistory(2) ports = %v, want the newest two", got)
+		}
+		if history[0].ID == 0 || history[0].ID == history[1].ID {
+			t.Errorf("IDs = %d, %d; want distinct IDs assigned", history[0].ID, history[1].ID)
+		}
+
+		all, _ := s.GetHistory(10)
+		first := all[2]
+		want := exportEntries(now)[0]
+		if !first.KilledAt.Equal(want.KilledAt) || first.Duration != want.Duration || first.Command != want.Command ||
+			first.Outcome != want.Outcome || !reflect.DeepEqual(first.Launch, want.Launch) {
+			t.Errorf("stored entry = %+v, want the fields of %+v", first, want)
+		}
+		if !all[1].Failed || all[1].Error != "permission denied\nsudo required" || all[1].ContainerName != "api" {
+			t.Errorf("failed entry = %+v, want its error and container kept", all[1])
+		}
+	})
+}
+
+func TestConformance_QueryHistory(t *testing.T) {
+	now := time.Now()
+	entries := []models.HistoryEntry{
+		{PortNumber: 3000, ProcessName: "node", Command: "node 100%_done.js", KilledAt: now.Add(-3 * time.Hour)},
+		{PortNumber: 3001, ProcessName: "Node", Command: "node server.js", KilledAt: now.Add(-2 * time.Hour)},
+		{PortNumber: 8080, ProcessName: "java", Failed: true, Error: "denied", KilledAt: now.Add(-time.Hour)},
+		{PortNumber: 5432, ProcessName: "postgres", KilledAt: now.Add(-10 * 24 * time.Hour)},
+	}
+	tests := []struct {
+		name  string
+		query models.HistoryQuery
+		want  []int
+	}{
+		{"all", models.HistoryQuery{}, []int{8080, 3001, 3000, 5432}},
+		{"port", models.HistoryQuery{Port: 3001}, []int{3001}},
+		{"range", models.HistoryQuery{PortMin: 3000, PortMax: 5000}, []int{3001, 3000}},
+		{"process ignores case", models.HistoryQuery{ProcessName: "NODE"}, []int{3001, 3000}},
+		{"command wildcards are literal", models.HistoryQuery{Command: "100%_"}, []int{3000}},
+		{"since", models.HistoryQuery{Since: now.Add(-24 * time.Hour)}, []int{8080, 3001, 3000}},
+		{"until", models.HistoryQuery{Until: now.Add(-24 * time.Hour)}, []int{5432}},
+		{"failed", models.HistoryQuery{Status: models.StatusFailed}, []int{8080}},
+		{"succeeded", models.HistoryQuery{Status: models.StatusSucceeded, Since: now.Add(-24 * time.Hour)}, []int{3001, 3000}},
+	}
+
+	forEachBackend(t, func(t *testing.T, s Storage) {
+		record(t, s, entries...)
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				page, err := s.QueryHistory(tt.query)
+				if err != nil {
+					t.Fatalf("QueryHistory() error = %v", err)
+				}
+				if got := ports(page.Entries); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("QueryHistory() ports = %v, want %v", got, tt.want)
+				}
+				if page.NextCursor != "" {
+					t.Errorf("NextCursor = %q, want none on a single page", page.NextCursor)
+				}
+			})
+		}
+	})
+}
+
+func TestConformance_Pagination(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s Storage) {
+		now := time.Now()
+		// Two entries share a kill time, so the cursor needs the ID as a tie-breaker
+		record(t, s,
+			models.HistoryEntry{PortNumber: 1, KilledAt: now.Add(-4 * time.Minute)},
+			models.HistoryEntry{PortNumber: 2, KilledAt: now.Add(-3 * time.Minute)},
+			models.HistoryEntry{PortNumber: 3, KilledAt: now.Add(-3 * time.Minute)},
+			models.HistoryEntry{PortNumber: 4, KilledAt: now.Add(-2 * time.Minute)},
+			models.HistoryEntry{PortNumber: 5, KilledAt: now.Add(-time.Minute)},
+		)
+
+		var got []int
+		query := models.HistoryQuery{Limit: 2}
+		for pages := 0; ; pages++ {
+			if pages > 3 {
+				t.Fatal("pagination did not end")
+			}
+			page, err := s.QueryHistory(query)
+			if err != nil {
+				t.Fatalf("QueryHistory() error = %v", err)
+			}
+			got = append(got, ports(page.Entries)...)
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+		if !reflect.DeepEqual(got, []int{5, 4, 3, 2, 1}) {
+			t.Errorf("paged ports = %v, want every entry once, newest first", got)
+		}
+
+		_, err := s.QueryHistory(models.HistoryQuery{Cursor: "not a cursor"})
+		if !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("QueryHistory(bad cursor) error = %v, want ErrInvalidCursor", err)
+		}
+	})
+}
+
+func TestConformance_KillCountAndLastKill(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s Storage) {
+		now := time.Now()
+		recent := now.Add(-time.Hour)
+		record(t, s,
+			models.HistoryEntry{PortNumber: 3000, KilledAt: now.Add(-10 * 24 * time.Hour)},
+			models.HistoryEntry{PortNumber: 3000, KilledAt: now.Add(-2 * time.Hour)},
+			models.HistoryEntry{PortNumber: 3000, KilledAt: recent},
+			models.HistoryEntry{PortNumber: 3000, Failed: true, KilledAt: now.Add(-time.Minute)},
+		)
+
+		if count, err := s.GetKillCount(3000, 7); err != nil || count != 2 {
+			t.Errorf("GetKillCount(3000, 7) = %d, %v; want 2 (failed and old kills excluded)", count, err)
+		}
+		if count, err := s.GetKillCount(4000, 7); err != nil || count != 0 {
+			t.Errorf("GetKillCount(4000, 7) = %d, %v; want 0", count, err)
+		}
+		if last, err := s.GetLastKillTime(3000); err != nil || !last.Equal(recent) {
+			t.Errorf("GetLastKillTime(3000) = %v, %v; want %v", last, err, recent)
+		}
+		if last, err := s.GetLastKillTime(4000); err != nil || !last.IsZero() {
+			t.Errorf("GetLastKillTime(4000) = %v, %v; want zero", last, err)
+		}
+	})
+}
+
+func TestConformance_Prune(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s Storage) {
+		now := time.Now()
+		record(t, s,
+			models.HistoryEntry{PortNumber: 1, KilledAt: now.Add(-40 * 24 * time.Hour)},
+			models.HistoryEntry{PortNumber: 2, KilledAt: now.Add(-3 * time.Hour)},
+			models.HistoryEntry{PortNumber: 3, KilledAt: now.Add(-2 * time.Hour)},
+			models.HistoryEntry{PortNumber: 4, KilledAt: now.Add(-time.Hour)},
+		)
+
+		if removed, err := s.Prune(30*24*time.Hour, 0); err != nil || removed != 1 {
+			t.Fatalf("Prune(30d, 0) = %d, %v; want 1", removed, err)
+		}
+		if removed, err := s.Prune(0, 2); err != nil || removed != 1 {
+			t.Fatalf("Prune(0, 2) = %d, %v; want 1", removed, err)
+		}
+		if removed, err := s.Prune(0, 0); err != nil || removed != 0 {
+			t.Fatalf("Prune(0, 0) = %d, %v; want nothing removed", removed, err)
+		}
+		if err := s.Compact(); err != nil {
+			t.Fatalf("Compact() error = %v", err)
+		}
+
+		history, _ := s.GetHistory(10)
+		if got := ports(history); !reflect.DeepEqual(got, []int{4, 3}) {
+			t.Errorf("history after pruning = %v, want the newest two", got)
+		}
+	})
+}
+
+func TestConformance_Stats(t *testing.T) {
+	day := func(d, hour int) time.Time {
+		return time.Date(2026, 10, d, hour, 30, 0, 0, time.Local)
+	}
+	entries := []models.HistoryEntry{
+		{PortNumber: 3000, ProcessName: "node", Method: "SIGTERM", Duration: 100 * time.Millisecond, KilledAt: day(10, 9)},
+		{PortNumber: 3000, ProcessName: "node", Method: "SIGTERM", Duration: 300 * time.Millisecond, KilledAt: day(10, 9)},
+		{PortNumber: 3000, ProcessName: "vite", Method: "SIGKILL", Duration: 3200 * time.Millisecond, KilledAt: day(11, 14)},
+		{PortNumber: 5432, ProcessName: "postgres", Method: "SIGINT", Duration: 200 * time.Millisecond, KilledAt: day(11, 9)},
+		{PortNumber: 8080, ProcessName: "node", Failed: true, Error: "permission denied", KilledAt: day(12, 9)},
+		{PortNumber: 9000, ProcessName: "java", Method: "SIGKILL", Duration: time.Second, KilledAt: day(1, 9)},
+	}
+	queries := []models.StatsQuery{
+		{Since: day(5, 0), Until: day(13, 0), Top: 2},
+		{Top: 10},
+		{Since: day(20, 0)},
+	}
+
+	// Every backend must agree with SQLite, whose results TestSQLite_Stats checks in detail
+	reference := newTestSQLite(t)
+	record(t, reference, entries...)
+	forEachBackend(t, func(t *testing.T, s Storage) {
+		record(t, s, entries...)
+		for _, q := range queries {
+			want, err := reference.Stats(q)
+			if err != nil {
+				t.Fatalf("SQLite Stats() error = %v", err)
+			}
+			got, err := s.Stats(q)
+			if err != nil {
+				t.Fatalf("Stats() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Stats(%+v) =\n%+v\nwant\n%+v", q, got, want)
+			}
+		}
+	})
+}
+
+func TestConformance_ImportEntries(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s Storage) {
+		now := time.Now()
+		entries := exportEntries(now)
+
+		if added, err := s.ImportEntries(append(entries, entries[0])); err != nil || added != 2 {
+			t.Fatalf("ImportEntries() = %d, %v; want 2 (the repeated entry once)", added, err)
+		}
+		if added, err := s.ImportEntries(entries); err != nil || added != 0 {
+			t.Fatalf("second ImportEntries() = %d, %v; want 0", added, err)
+		}
+		if added, err := s.ImportEntries(nil); err != nil || added != 0 {
+			t.Fatalf("ImportEntries(nil) = %d, %v; want 0", added, err)
+		}
+
+		history, _ := s.GetHistory(10)
+		if got := ports(history); !reflect.DeepEqual(got, []int{8080, 3000}) {
+			t.Errorf("history = %v, want each entry once", got)
+		}
+	})
+}
+
+func TestConformance_ExportImport(t *testing.T) {
+	src := newTestSQLite(t)
+	record(t, src, exportEntries(time.Now())...)
+
+	forEachBackend(t, func(t *testing.T, s Storage) {
+		var buf bytes.Buffer
+		if _, err := Export(src, &buf, FormatJSONL, models.HistoryQuery{}); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+		result, err := Import(s, &buf, FormatJSONL)
+		if err != nil || result.Added != 2 {
+			t.Fatalf("Import() = %+v, %v; want 2 added", result, err)
+		}
+
+		// And back again: nothing is new to the source
+		buf.Reset()
+		if _, err := Export(s, &buf, FormatCSV, models.HistoryQuery{}); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+		result, err = Import(src, &buf, FormatCSV)
+		if err != nil || result.Read != 2 || result.Added != 0 {
+			t.Errorf("re-Import() = %+v, %v; want 2 read, all duplicates", result, err)
+		}
+	})
+}
+
+func TestConformance_PortEvents(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s Storage) {
+		now := time.Now()
+		node := models.PortInfo{PortNumber: 3000, PID: 101, ProcessName: "node", Command: "node server.js"}
+		api := models.PortInfo{PortNumber: 8080, PID: 202, ProcessName: "api", IsDocker: true, ContainerName: "api", ImageName: "api:latest"}
+		events := []models.PortEvent{
+			models.NewPortEvent(models.PortEventAppeared, node, now.Add(-48*time.Hour)),
+			models.NewPortEvent(models.PortEventAppeared, api, now.Add(-2*time.Hour)),
+			models.NewPortEvent(models.PortEventDisappeared, node, now.Add(-time.Hour)),
+		}
+		// Recorded out of order; queries return them oldest first
+		if err := s.RecordPortEvents(events[1:]); err != nil {
+			t.Fatalf("RecordPortEvents() error = %v", err)
+		}
+		if err := s.RecordPortEvents(events[:1]); err != nil {
+			t.Fatalf("RecordPortEvents() error = %v", err)
+		}
+		if err := s.RecordPortEvents(nil); err != nil {
+			t.Fatalf("RecordPortEvents(nil) error = %v", err)
+		}
+
+		all, err := s.PortEvents(models.PortEventQuery{})
+		if err != nil {
+			t.Fatalf("PortEvents() error = %v", err)
+		}
+		if len(all) != 3 || !all[0].At.Equal(events[0].At) || all[1].Port != 8080 || all[2].Kind != models.PortEventDisappeared {
+			t.Fatalf("PortEvents() = %+v, want all three oldest first", all)
+		}
+		if all[0].ID == 0 || all[0].ID == all[1].ID || all[0].Command != "node server.js" || all[1].ContainerName != "api" || all[1].ImageName != "api:latest" {
+			t.Errorf("stored events = %+v, want IDs assigned and fields kept", all)
+		}
+
+		tests := []struct {
+			name  string
+			query models.PortEventQuery
+			want  int
+		}{
+			{"port", models.PortEventQuery{Port: 3000}, 2},
+			{"since", models.PortEventQuery{Since: now.Add(-3 * time.Hour)}, 2},
+			{"until", models.PortEventQuery{Until: now.Add(-time.Hour)}, 2},
+			{"port and window", models.PortEventQuery{Port: 3000, Since: now.Add(-3 * time.Hour)}, 1},
+		}
+		for _, tt := range tests {
+			got, err := s.PortEvents(tt.query)
+			if err != nil || len(got) != tt.want {
+				t.Errorf("PortEvents(%s) = %d events, %v; want %d", tt.name, len(got), err, tt.want)
+			}
+		}
+
+		removed, err := s.PrunePortEvents(24 * time.Hour)
+		if err != nil || removed != 1 {
+			t.Fatalf("PrunePortEvents(24h) = %d, %v; want 1", removed, err)
+		}
+		if removed, _ := s.PrunePortEvents(0); removed != 0 {
+			t.Errorf("PrunePortEvents(0) = %d, want nothing removed", removed)
+		}
+		left, _ := s.PortEvents(models.PortEventQuery{})
+		if len(left) != 2 || left[0].Port != 8080 {
+			t.Errorf("events after pruning = %+v, want the two recent ones", left)
+		}
+	})
+}
+
+func TestConformance_Labels(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s Storage) {
+		saved := time.Now().Add(-time.Hour)
+		for _, label := range []models.PortLabel{
+			{Port: 5433, ProcessName: "ssh", Label: "staging DB tunnel", UpdatedAt: saved},
+			{Port: 3000, Label: "dev server", Note: "anything on 3000", UpdatedAt: saved},
+			{Port: 3000, ProcessName: "Node", Project: "/src/shop/", Label: "storefront"},
+		} {
+			if err := s.SaveLabel(label); err != nil {
+				t.Fatalf("SaveLabel() error = %v", err)
+			}
+		}
+		// Same scope, differently written: replaces the label
+		if err := s.SaveLabel(models.PortLabel{Port: 3000, ProcessName: "node", Project: "/src/shop", Label: "storefront", Note: "ask the web team"}); err != nil {
+			t.Fatalf("SaveLabel() error = %v", err)
+		}
+
+		labels, err := s.Labels()
+		if err != nil {
+			t.Fatalf("Labels() error = %v", err)
+		}
+		var got []string
+		for _, l := range labels {
+			got = append(got, l.Label)
+		}
+		if want := []string{"dev server", "storefront", "staging DB tunnel"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Labels() = %v, want %v ordered by port and scope", got, want)
+		}
+		if labels[0].Note != "anything on 3000" || !labels[0].UpdatedAt.Equal(saved) {
+			t.Errorf("label = %+v, want its note and time kept", labels[0])
+		}
+		if labels[1].ProcessName != "node" || labels[1].Note != "ask the web team" || labels[1].UpdatedAt.IsZero() {
+			t.Errorf("replaced label = %+v, want the normalized scope, the new note and a save time", labels[1])
+		}
+
+		deleted, err := s.DeleteLabel(models.PortLabel{Port: 5433, ProcessName: "SSH"})
+		if err != nil || !deleted {
+			t.Fatalf("DeleteLabel() = %v, %v; want the tunnel label deleted", deleted, err)
+		}
+		if deleted, _ := s.DeleteLabel(models.PortLabel{Port: 5433}); deleted {
+			t.Error("DeleteLabel() of a scope without a label should report nothing deleted")
+		}
+		if labels, _ := s.Labels(); len(labels) != 2 {
+			t.Errorf("labels after deleting = %+v, want two", labels)
+		}
+	})
+}
+
+func TestConformance_KillSummaries(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s Storage) {
+		now := time.Now()
+		record(t, s,
+			models.HistoryEntry{PortNumber: 3000, ProcessName: "node", KilledAt: now.Add(-40 * 24 * time.Hour)},
+			models.HistoryEntry{PortNumber: 3000, ProcessName: "node", KilledAt: now.Add(-2 * time.Hour)},
+			models.HistoryEntry{PortNumber: 3000, ProcessName: "node", KilledAt: now.Add(-time.Hour)},
+			models.HistoryEntry{PortNumber: 3000, ProcessName: "node", KilledAt: now, Failed: true, Error: "denied"},
+			models.HistoryEntry{PortNumber: 8080, ProcessName: "java", KilledAt: now.Add(-60 * 24 * time.Hour)},
+			models.HistoryEntry{PortNumber: 9000, ProcessName: "php", KilledAt: now},
+			models.HistoryEntry{PortNumber: 5432, ProcessName: "postgres", KilledAt: now, Failed: true},
+		)
+
+		summaries, err := s.KillSummaries([]int{3000, 8080, 5432, 4000}, 30)
+		if err != nil {
+			t.Fatalf("KillSummaries() error = %v", err)
+		}
+		if len(summaries) != 2 {
+			t.Fatalf("KillSummaries() = %+v, want only the ports asked for that were killed", summaries)
+		}
+		if got := summaries[3000]; got.Kills != 2 || !got.LastKilled.Equal(now.Add(-time.Hour)) {
+			t.Errorf("port 3000 = %+v, want 2 recent kills, the last an hour ago", got)
+		}
+		if got := summaries[8080]; got.Kills != 0 || !got.LastKilled.Equal(now.Add(-60*24*time.Hour)) || got.Hours != nil {
+			t.Errorf("port 8080 = %+v, want no recent kills but the old last kill", got)
+		}
+		want := make([]int, 24)
+		want[now.Add(-2*time.Hour).Hour()]++
+		want[now.Add(-time.Hour).Hour()]++
+		if got := summaries[3000].Hours; !reflect.DeepEqual(got, want) {
+			t.Errorf("port 3000 hours = %v, want the two recent kills by local hour %v", got, want)
+		}
+
+		// Agrees with the per-port queries
+		count, _ := s.GetKillCount(3000, 30)
+		last, _ := s.GetLastKillTime(3000)
+		if count != summaries[3000].Kills || !last.Equal(summaries[3000].LastKilled) {
+			t.Errorf("GetKillCount/GetLastKillTime = %d, %v; KillSummaries = %+v", count, last, summaries[3000])
+		}
+
+		if summaries, err := s.KillSummaries(nil, 30); err != nil || len(summaries) != 0 {
+			t.Errorf("KillSummaries(nil) = %+v, %v; want nothing", summaries, err)
+		}
+	})
+}
@@ -0,0 +1,294 @@
+package storage
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// Format is a history export file format.
+type Format string
+
+const (
+	// FormatJSONL writes one JSON history entry per line
+	FormatJSONL Format = "jsonl"
+	// FormatCSV writes a header row of the entry's JSON field names and one row per entry
+	FormatCSV Format = "csv"
+)
+
+// ParseFormat converts a format name ("jsonl", "json" or "csv") into a Format.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "jsonl", "json", "ndjson":
+		return FormatJSONL, nil
+	case "csv":
+		return FormatCSV, nil
+	}
+	return "", fmt.Errorf("unknown history format %q (want jsonl or csv)", name)
+}
+
+// FormatForPath picks the format from a file extension; anything but .csv is JSON Lines.
+func FormatForPath(path string) Format {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return FormatCSV
+	}
+	return FormatJSONL
+}
+
+// ImportResult reports what an import did.
+type ImportResult struct {
+	// Read is the number of entries in the input
+	Read int `json:"read"`
+	// Added is the number of entries that weren't in the history yet
+	Added int `json:"added"`
+}
+
+// Skipped is the number of duplicates that were not imported.
+func (r ImportResult) Skipped() int {
+	return r.Read - r.Added
+}
+
+// exportPageSize is how many entries Export reads from storage at a time.
+const exportPageSize = 500
+
+// Export writes the entries matching q (newest first) to w and returns how many it wrote.
+// The query's Limit and Cursor are ignored; every matching entry is exported.
+func Export(src Storage, w io.Writer, format Format, q models.HistoryQuery) (int, error) {
+	var write func(models.HistoryEntry) error
+	var flush func() error
+	switch format {
+	case FormatJSONL:
+		enc := json.NewEncoder(w)
+		write = func(entry models.HistoryEntry) error { return enc.Encode(entry) }
+		flush = func() error { return nil }
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader()); err != nil {
+			return 0, fmt.Errorf("failed to write CSV header: %w", err)
+		}
+		write = func(entry models.HistoryEntry) error {
+			record, err := csvRecord(entry)
+			if err != nil {
+				return err
+			}
+			return cw.Write(record)
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	default:
+		return 0, fmt.Errorf("unknown history format %q", format)
+	}
+
+	q.Limit, q.Cursor = exportPageSize, ""
+	written := 0
+	for {
+		page, err := src.QueryHistory(q)
+		if err != nil {
+			return written, err
+		}
+		for _, entry := range page.Entries {
+			if err := write(entry); err != nil {
+				return written, fmt.Errorf("failed to export entry %d: %w", entry.ID, err)
+			}
+			written++
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+	if err := flush(); err != nil {
+		return written, fmt.Errorf("failed to export history: %w", err)
+	}
+	return written, nil
+}
+
+// Import reads every entry from r and adds those the history doesn't have yet.
+// Nothing is imported if any entry is malformed. IDs in the input are ignored.
+func Import(dst Storage, r io.Reader, format Format) (ImportResult, error) {
+	var entries []models.HistoryEntry
+	var err error
+	switch format {
+	case FormatJSONL:
+		entries, err = readJSONL(r)
+	case FormatCSV:
+		entries, err = readCSV(r)
+	default:
+		err = fmt.Errorf("unknown history format %q", format)
+	}
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	added, err := dst.ImportEntries(entries)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	return ImportResult{Read: len(entries), Added: added}, nil
+}
+
+// readJSONL decodes a stream of JSON history entries.
+func readJSONL(r io.Reader) ([]models.HistoryEntry, error) {
+	var entries []models.HistoryEntry
+	dec := json.NewDecoder(r)
+	for {
+		var entry models.HistoryEntry
+		err := dec.Decode(&entry)
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid entry %d: %w", len(entries)+1, err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// readCSV decodes history entries from CSV with a header row. Columns are matched by name,
+// so files with columns reordered or removed (e.g. by a spreadsheet) still import.
+func readCSV(r io.Reader) ([]models.HistoryEntry, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	columns := make([]*csvColumn, len(header))
+	known := false
+	for i, name := range header {
+		for j := range entryColumns {
+			if entryColumns[j].name == strings.TrimSpace(name) {
+				columns[i] = &entryColumns[j]
+				known = true
+			}
+		}
+	}
+	if !known {
+		return nil, errors.New("invalid CSV header: no history entry columns")
+	}
+
+	var entries []models.HistoryEntry
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV row %d: %w", len(entries)+2, err)
+		}
+		entry, err := parseCSVRecord(columns, record)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV row %d: %w", len(entries)+2, err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// csvColumn is a CSV column: a json tag of models.HistoryEntry and whether its JSON value is a string.
+type csvColumn struct {
+	name   string
+	quoted bool
+}
+
+// entryColumns are the CSV columns, in models.HistoryEntry field order.
+var entryColumns = jsonColumns(reflect.TypeOf(models.HistoryEntry{}))
+
+// jsonColumns lists the JSON field names of a struct type. Strings and times are JSON strings;
+// numbers, booleans, durations and nested objects are written as their raw JSON.
+func jsonColumns(t reflect.Type) []csvColumn {
+	timeType := reflect.TypeOf(time.Time{})
+	var columns []csvColumn
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		quoted := field.Type.Kind() == reflect.String || field.Type == timeType
+		columns = append(columns, csvColumn{name: name, quoted: quoted})
+	}
+	return columns
+}
+
+// csvHeader returns the CSV header row.
+func csvHeader() []string {
+	header := make([]string, len(entryColumns))
+	for i, c := range entryColumns {
+		header[i] = c.name
+	}
+	return header
+}
+
+// csvRecord converts an entry into a CSV row through its JSON form, so the CSV
+// uses the same names and value formats as JSON Lines.
+func csvRecord(entry models.HistoryEntry) ([]string, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	record := make([]string, len(entryColumns))
+	for i, c := range entryColumns {
+		raw, ok := fields[c.name]
+		if !ok {
+			continue
+		}
+		if c.quoted {
+			var s string
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return nil, err
+			}
+			record[i] = s
+		} else {
+			record[i] = string(raw)
+		}
+	}
+	return record, nil
+}
+
+// parseCSVRecord rebuilds an entry from a CSV row; empty cells leave the field at its zero value.
+func parseCSVRecord(columns []*csvColumn, record []string) (models.HistoryEntry, error) {
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i, value := range record {
+		if i >= len(columns) || columns[i] == nil || value == "" {
+			continue
+		}
+		if sb.Len() > 1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(strconv.Quote(columns[i].name))
+		sb.WriteString(":")
+		if columns[i].quoted {
+			quoted, _ := json.Marshal(value)
+			sb.Write(quoted)
+		} else {
+			sb.WriteString(value)
+		}
+	}
+	sb.WriteString("}")
+
+	var entry models.HistoryEntry
+	if err := json.Unmarshal([]byte(sb.String()), &entry); err != nil {
+		return models.HistoryEntry{}, err
+	}
+	return entry, nil
+}
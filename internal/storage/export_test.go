@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// newTestSQLite opens a fresh database in a temporary directory.
+func newTestSQLite(t *testing.T) *SQLite {
+	t.Helper()
+	s, err := NewSQLite(Config{DBPath: filepath.Join(t.TempDir(), "test.db"), Timeout: 50})
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// exportEntries are stored by TestExportImport; they cover every kind of column.
+func exportEntries(now time.Time) []models.HistoryEntry {
+	return []models.HistoryEntry{
+		{
+			PortNumber: 3000, ProcessName: "node", PID: 101, Command: `node "server.js", --port=3000`,
+			Outcome: models.OutcomeKilled, Method: "SIGTERM", Duration: 1500 * time.Millisecond, KilledBy: "dev",
+			Launch:   &models.LaunchContext{Executable: "/usr/bin/node", Args: []string{"node", "server.js"}, Cwd: "/app", Env: []string{"A=1"}},
+			KilledAt: now.Add(-2 * time.Hour),
+		},
+		{
+			PortNumber: 8080, ProcessName: "java", PID: 202, Failed: true, Error: "permission denied\nsudo required",
+			Outcome: models.OutcomeFailed, ContainerID: "abc", ContainerName: "api", ImageName: "api:1",
+			KilledAt: now.Add(-time.Hour),
+		},
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	for _, format := range []Format{FormatJSONL, FormatCSV} {
+		t.Run(string(format), func(t *testing.T) {
+			src := newTestSQLite(t)
+			now := time.Now()
+			for _, entry := range exportEntries(now) {
+				if err := src.RecordKill(entry); err != nil {
+					t.Fatalf("RecordKill() error = %v", err)
+				}
+			}
+
+			var buf bytes.Buffer
+			n, err := Export(src, &buf, format, models.HistoryQuery{})
+			if err != nil || n != 2 {
+				t.Fatalf("Export() = %d, %v; want 2 entries", n, err)
+			}
+			data := buf.Bytes()
+
+			dst := newTestSQLite(t)
+			if err := dst.RecordKill(models.HistoryEntry{PortNumber: 5432, ProcessName: "postgres", PID: 303, KilledAt: now}); err != nil {
+				t.Fatalf("RecordKill() error = %v", err)
+			}
+			result, err := Import(dst, bytes.NewReader(data), format)
+			if err != nil || result.Read != 2 || result.Added != 2 {
+				t.Fatalf("Import() = %+v, %v; want 2 read and added", result, err)
+			}
+
+			// Importing the same file again only finds duplicates
+			result, err = Import(dst, bytes.NewReader(data), format)
+			if err != nil || result.Added != 0 || result.Skipped() != 2 {
+				t.Errorf("second Import() = %+v, %v; want both entries skipped", result, err)
+			}
+
+			want, _ := src.GetHistory(10)
+			got, _ := dst.GetHistory(10)
+			if len(got) != 3 {
+				t.Fatalf("destination has %d entries, want 3", len(got))
+			}
+			for i := range want {
+				imported := got[i+1]
+				want[i].ID, imported.ID = 0, 0
+				if !imported.KilledAt.Equal(want[i].KilledAt) {
+					t.Errorf("KilledAt = %v, want %v", imported.KilledAt, want[i].KilledAt)
+				}
+				want[i].KilledAt, imported.KilledAt = time.Time{}, time.Time{}
+				if !reflect.DeepEqual(imported, want[i]) {
+					t.Errorf("imported entry = %+v\nwant %+v", imported, want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExport_Filtered(t *testing.T) {
+	s := newTestSQLite(t)
+	for _, entry := range exportEntries(time.Now()) {
+		if err := s.RecordKill(entry); err != nil {
+			t.Fatalf("RecordKill() error = %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if n, err := Export(s, &buf, FormatCSV, models.HistoryQuery{Status: models.StatusFailed, Limit: 1}); err != nil || n != 1 {
+		t.Fatalf("Export() = %d, %v; want only the failed entry", n, err)
+	}
+	lines := strings.SplitN(buf.String(), "\n", 2)
+	if !strings.HasPrefix(lines[0], "id,port_number,process_name,pid,command,") {
+		t.Errorf("CSV header = %q, want the json tag names", lines[0])
+	}
+	if !strings.Contains(lines[1], "java") || !strings.Contains(lines[1], `"permission denied`) {
+		t.Errorf("CSV row = %q", lines[1])
+	}
+}
+
+func TestImport_CSVColumns(t *testing.T) {
+	// Columns can be reordered or dropped, and unknown ones are ignored
+	input := "note,killed_at,process_name,port_number,failed\n" +
+		"edited,2026-10-18T09:30:00+02:00,node,3000,\n" +
+		"x,2026-10-18T10:00:00Z,vite,5173,true\n"
+
+	s := newTestSQLite(t)
+	result, err := Import(s, strings.NewReader(input), FormatCSV)
+	if err != nil || result.Added != 2 {
+		t.Fatalf("Import() = %+v, %v", result, err)
+	}
+	history, _ := s.GetHistory(10)
+	if len(history) != 2 || history[0].ProcessName != "vite" || !history[0].Failed || history[1].PortNumber != 3000 || history[1].Failed {
+		t.Errorf("history = %+v", history)
+	}
+}
+
+func TestImport_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		input  string
+	}{
+		{"bad JSON", FormatJSONL, `{"port_number": 3000}` + "\n" + `{"port_number": "x"}`},
+		{"bad CSV value", FormatCSV, "port_number,process_name\n3000,node\nabc,vite\n"},
+		{"unknown CSV columns", FormatCSV, "a,b\n1,2\n"},
+		{"unknown format", Format("xml"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSQLite(t)
+			if _, err := Import(s, strings.NewReader(tt.input), tt.format); err == nil {
+				t.Fatal("Import() should fail")
+			}
+			if history, _ := s.GetHistory(10); len(history) != 0 {
+				t.Errorf("a failed import added %d entries, want none", len(history))
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"jsonl": FormatJSONL, "JSON": FormatJSONL, " csv ": FormatCSV} {
+		if got, err := ParseFormat(name); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(xml) should fail")
+	}
+	if FormatForPath("history.CSV") != FormatCSV || FormatForPath("history.jsonl") != FormatJSONL {
+		t.Error("FormatForPath() should pick CSV only for .csv files")
+	}
+}
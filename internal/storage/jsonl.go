@@ -0,0 +1,584 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/platform"
+)
+
+// JSONL is a Storage that appends each kill to a JSON Lines file (one models.HistoryEntry per line)
+// and answers queries from an in-memory index. It is pure Go, so it works in static builds
+// where SQLite's cgo driver is unavailable.
+//
+// Entries appended by other port-chaser processes are picked up before every operation.
+// Prune and Compact rewrite the file and replace it atomically; a process still holding the
+// old file notices and reloads.
+type JSONL struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	// size is how much of the file has been loaded into mem
+	size int64
+	// torn is true if the file ends in a partial line, left by a crash during an append
+	torn bool
+	mem  *Memory
+}
+
+// NewJSONL opens (creating if needed) the history file at path and loads it.
+// An empty path means history.jsonl in the platform data directory.
+func NewJSONL(path string) (*JSONL, error) {
+	if path == "" {
+		path = defaultPath(filepath.Join(platform.GetDataPath(), "history.jsonl"))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	j := &JSONL{path: path}
+	if err := j.reload(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// RecordKill appends entry to the file.
+func (j *JSONL) RecordKill(entry models.HistoryEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.sync(); err != nil {
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+	j.mem.mu.Lock()
+	entry = j.mem.add(entry)
+	j.mem.mu.Unlock()
+
+	if err := j.append([]models.HistoryEntry{entry}); err != nil {
+		j.mem.mu.Lock()
+		j.mem.remove(entry.ID)
+		j.mem.mu.Unlock()
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+	return nil
+}
+
+// ImportEntries appends the entries that aren't stored yet, in one write.
+func (j *JSONL) ImportEntries(entries []models.HistoryEntry) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.sync(); err != nil {
+		return 0, fmt.Errorf("failed to import history: %w", err)
+	}
+	j.mem.mu.Lock()
+	added := j.mem.importEntries(entries)
+	j.mem.mu.Unlock()
+
+	if err := j.append(added); err != nil {
+		j.mem.mu.Lock()
+		for _, entry := range added {
+			j.mem.remove(entry.ID)
+		}
+		j.mem.mu.Unlock()
+		return 0, fmt.Errorf("failed to import history: %w", err)
+	}
+	return len(added), nil
+}
+
+// GetHistory returns the newest limit entries.
+func (j *JSONL) GetHistory(limit int) ([]models.HistoryEntry, error) {
+	if err := j.refresh(); err != nil {
+		return nil, fmt.Errorf("failed to get history: %w", err)
+	}
+	return j.mem.GetHistory(limit)
+}
+
+// QueryHistory returns one page of the entries matching q, newest first.
+func (j *JSONL) QueryHistory(q models.HistoryQuery) (models.HistoryPage, error) {
+	if err := j.refresh(); err != nil {
+		return models.HistoryPage{}, fmt.Errorf("failed to query history: %w", err)
+	}
+	return j.mem.QueryHistory(q)
+}
+
+// Stats aggregates the history in the query's window.
+func (j *JSONL) Stats(q models.StatsQuery) (models.KillStats, error) {
+	if err := j.refresh(); err != nil {
+		return models.KillStats{}, fmt.Errorf("failed to compute stats: %w", err)
+	}
+	return j.mem.Stats(q)
+}
+
+// GetKillCount returns how many successful kills of port happened within the last days.
+func (j *JSONL) GetKillCount(port int, days int) (int, error) {
+	if err := j.refresh(); err != nil {
+		return 0, fmt.Errorf("failed to get kill count: %w", err)
+	}
+	return j.mem.GetKillCount(port, days)
+}
+
+// KillSummaries returns the recent kill count and last kill time of each port ever killed successfully.
+func (j *JSONL) KillSummaries(ports []int, days int) (map[int]models.PortKillSummary, error) {
+	if err := j.refresh(); err != nil {
+		return nil, fmt.Errorf("failed to summarize kills: %w", err)
+	}
+	return j.mem.KillSummaries(ports, days)
+}
+
+// GetLastKillTime returns when port was last killed successfully (zero if never).
+func (j *JSONL) GetLastKillTime(port int) (time.Time, error) {
+	if err := j.refresh(); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get last kill time: %w", err)
+	}
+	return j.mem.GetLastKillTime(port)
+}
+
+// Prune deletes entries beyond the retention limits and rewrites the file without them.
+func (j *JSONL) Prune(maxAge time.Duration, maxRows int) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.sync(); err != nil {
+		return 0, fmt.Errorf("failed to prune history: %w", err)
+	}
+	j.mem.mu.Lock()
+	removed := j.mem.prune(maxAge, maxRows)
+	j.mem.mu.Unlock()
+	if removed == 0 {
+		return 0, nil
+	}
+	if err := j.rewrite(); err != nil {
+		return 0, fmt.Errorf("failed to prune history: %w", err)
+	}
+	return removed, nil
+}
+
+// Compact rewrites the file without the partial or corrupt lines a crash may have left.
+func (j *JSONL) Compact() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.sync(); err != nil {
+		return fmt.Errorf("failed to compact history: %w", err)
+	}
+	if err := j.rewrite(); err != nil {
+		return fmt.Errorf("failed to compact history: %w", err)
+	}
+	return nil
+}
+
+// eventsPath is the file that holds the port timeline next to the history file,
+// e.g. history.events.jsonl for history.jsonl.
+func (j *JSONL) eventsPath() string {
+	return strings.TrimSuffix(j.path, filepath.Ext(j.path)) + ".events.jsonl"
+}
+
+// RecordPortEvents appends timeline events to the events file in a single write.
+func (j *JSONL) RecordPortEvents(events []models.PortEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	file, err := os.OpenFile(j.eventsPath(), os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to record port events: %w", err)
+	}
+	defer file.Close()
+
+	var buf bytes.Buffer
+	// Start on a fresh line after a write torn by a crash
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+	enc := json.NewEncoder(&buf)
+	for _, event := range events {
+		event.ID = 0
+		if err := enc.Encode(event); err != nil {
+			return fmt.Errorf("failed to record port events: %w", err)
+		}
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to record port events: %w", err)
+	}
+	return nil
+}
+
+// PortEvents reads the timeline events matching q, oldest first. IDs are line numbers.
+func (j *JSONL) PortEvents(q models.PortEventQuery) ([]models.PortEvent, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	all, err := j.readEvents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query port events: %w", err)
+	}
+	var events []models.PortEvent
+	for _, event := range all {
+		if q.Matches(event) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// PrunePortEvents rewrites the events file without the events older than maxAge (0 keeps them all).
+func (j *JSONL) PrunePortEvents(maxAge time.Duration) (int, error) {
+	if maxAge <= 0 {
+		return 0, nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	all, err := j.readEvents()
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune port events: %w", err)
+	}
+	cutoff := time.Now().Add(-maxAge)
+	var kept []models.PortEvent
+	for _, event := range all {
+		if !event.At.Before(cutoff) {
+			kept = append(kept, event)
+		}
+	}
+	if len(kept) == len(all) {
+		return 0, nil
+	}
+
+	err = replaceFile(j.eventsPath(), func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		for _, event := range kept {
+			event.ID = 0
+			if err := enc.Encode(event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune port events: %w", err)
+	}
+	return len(all) - len(kept), nil
+}
+
+// readEvents reads every complete, valid line of the events file, sorted oldest first.
+// A missing file has no events. The caller holds j.mu.
+func (j *JSONL) readEvents() ([]models.PortEvent, error) {
+	data, err := os.ReadFile(j.eventsPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var events []models.PortEvent
+	for line := int64(1); ; line++ {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		var event models.PortEvent
+		if json.Unmarshal(data[:i], &event) == nil {
+			event.ID = line
+			events = append(events, event)
+		}
+		data = data[i+1:]
+	}
+	sort.SliceStable(events, func(a, b int) bool { return events[a].At.Before(events[b].At) })
+	return events, nil
+}
+
+// labelsPath is the file that holds the port labels next to the history file,
+// e.g. history.labels.jsonl for history.jsonl.
+func (j *JSONL) labelsPath() string {
+	return strings.TrimSuffix(j.path, filepath.Ext(j.path)) + ".labels.jsonl"
+}
+
+// Labels reads every port label, ordered by port, then scope.
+func (j *JSONL) Labels() ([]models.PortLabel, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	labels, err := j.readLabels()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query labels: %w", err)
+	}
+	return labels, nil
+}
+
+// SaveLabel rewrites the labels file with label in place of the one with the same port and scope.
+func (j *JSONL) SaveLabel(label models.PortLabel) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	labels, err := j.readLabels()
+	if err == nil {
+		err = j.writeLabels(saveLabel(labels, label))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save label: %w", err)
+	}
+	return nil
+}
+
+// DeleteLabel rewrites the labels file without the label with the port and scope of label.
+func (j *JSONL) DeleteLabel(label models.PortLabel) (bool, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	labels, err := j.readLabels()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete label: %w", err)
+	}
+	labels, deleted := deleteLabel(labels, label)
+	if !deleted {
+		return false, nil
+	}
+	if err := j.writeLabels(labels); err != nil {
+		return false, fmt.Errorf("failed to delete label: %w", err)
+	}
+	return true, nil
+}
+
+// readLabels reads every valid line of the labels file, ordered by port, then scope.
+// A missing file has no labels. The caller holds j.mu.
+func (j *JSONL) readLabels() ([]models.PortLabel, error) {
+	data, err := os.ReadFile(j.labelsPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []models.PortLabel
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var label models.PortLabel
+		if json.Unmarshal(line, &label) == nil {
+			labels = saveLabel(labels, label)
+		}
+	}
+	return labels, nil
+}
+
+// writeLabels replaces the labels file with labels. The caller holds j.mu.
+func (j *JSONL) writeLabels(labels []models.PortLabel) error {
+	return replaceFile(j.labelsPath(), func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		for _, label := range labels {
+			if err := enc.Encode(label); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Close closes the history file.
+func (j *JSONL) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// refresh loads what other processes have written since the last operation.
+func (j *JSONL) refresh() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.sync()
+}
+
+// sync brings the index up to date with the file: new lines are loaded, and a file that was
+// replaced (pruned by another process) or truncated is reloaded. The caller holds j.mu.
+func (j *JSONL) sync() error {
+	if j.file == nil {
+		return os.ErrClosed
+	}
+	onDisk, err := os.Stat(j.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return j.reload()
+		}
+		return err
+	}
+	open, err := j.file.Stat()
+	if err != nil {
+		return err
+	}
+	if !os.SameFile(onDisk, open) || onDisk.Size() < j.size {
+		return j.reload()
+	}
+	if onDisk.Size() == j.size {
+		return nil
+	}
+
+	data := make([]byte, onDisk.Size()-j.size)
+	if _, err := j.file.ReadAt(data, j.size); err != nil && err != io.EOF {
+		return err
+	}
+	j.load(data)
+	return nil
+}
+
+// reload reopens the file and rebuilds the index from scratch. The caller holds j.mu
+// (or is NewJSONL).
+func (j *JSONL) reload() error {
+	if j.file != nil {
+		j.file.Close()
+		j.file = nil
+	}
+	file, err := os.OpenFile(j.path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	j.file = file
+	j.size = 0
+	j.torn = false
+	j.mem = NewMemory()
+	j.load(data)
+	return nil
+}
+
+// load adds the complete lines of data, which starts at j.size, to the index and advances j.size
+// past them. Corrupt lines are skipped; a partial last line is left for the next sync.
+// Entries whose ID is missing or already taken get a new one. The caller holds j.mu.
+func (j *JSONL) load(data []byte) {
+	j.mem.mu.Lock()
+	defer j.mem.mu.Unlock()
+
+	ids := make(map[int64]bool, len(j.mem.entries))
+	for _, entry := range j.mem.entries {
+		ids[entry.ID] = true
+	}
+
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			j.torn = len(data) > 0
+			return
+		}
+		line := data[:i]
+		data = data[i+1:]
+		j.size += int64(i + 1)
+
+		var entry models.HistoryEntry
+		if len(bytes.TrimSpace(line)) == 0 || json.Unmarshal(line, &entry) != nil {
+			continue
+		}
+		if entry.ID <= 0 || ids[entry.ID] {
+			entry.ID = j.mem.nextID
+		}
+		ids[entry.ID] = true
+		j.mem.insert(entry)
+	}
+}
+
+// append writes entries (already in the index) to the end of the file in a single write.
+// If another process appended in between, the index is reloaded from the file. The caller holds j.mu.
+func (j *JSONL) append(entries []models.HistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	// Start on a fresh line after a torn write, so that only the torn line is lost
+	if j.torn {
+		buf.WriteByte('\n')
+	}
+	enc := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+
+	if _, err := j.file.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	end, err := j.file.Seek(0, io.SeekCurrent)
+	if err != nil || end != j.size+int64(buf.Len()) {
+		// Someone else's lines are between ours and what we loaded
+		return j.reload()
+	}
+	j.size = end
+	j.torn = false
+	return nil
+}
+
+// rewrite replaces the file with the index's entries, oldest first. The caller holds j.mu.
+func (j *JSONL) rewrite() error {
+	// Windows can't replace a file that is still open
+	j.file.Close()
+	j.file = nil
+
+	err := replaceFile(j.path, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		j.mem.mu.RLock()
+		defer j.mem.mu.RUnlock()
+		for _, entry := range j.mem.entries {
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if reloadErr := j.reload(); err == nil {
+		err = reloadErr
+	}
+	return err
+}
+
+// replaceFile replaces the file at path with what write produces, through a temporary file
+// in the same directory so the file is never half written.
+func replaceFile(path string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".history-*.jsonl")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	err = write(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0600)
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// newTestJSONL opens the JSON Lines store at path and closes it when the test ends.
+func newTestJSONL(t *testing.T, path string) *JSONL {
+	t.Helper()
+	s, err := NewJSONL(path)
+	if err != nil {
+		t.Fatalf("NewJSONL() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestJSONL_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "history.jsonl")
+	s := newTestJSONL(t, path)
+	now := time.Now()
+	record(t, s,
+		models.HistoryEntry{PortNumber: 3000, ProcessName: "node", KilledAt: now.Add(-time.Hour)},
+		models.HistoryEntry{PortNumber: 8080, ProcessName: "java", KilledAt: now},
+	)
+	before, _ := s.GetHistory(10)
+	s.Close()
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Stat() error = %v", err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("file mode = %v, want 0600", info.Mode().Perm())
+		}
+	}
+
+	reopened := newTestJSONL(t, path)
+	after, err := reopened.GetHistory(10)
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if len(after) != 2 || after[0].ID != before[0].ID || after[1].ID != before[1].ID || !after[0].KilledAt.Equal(now) {
+		t.Errorf("history after reopening = %+v, want %+v", after, before)
+	}
+
+	// New entries continue the IDs
+	record(t, reopened, models.HistoryEntry{PortNumber: 5432, KilledAt: now.Add(time.Minute)})
+	latest, _ := reopened.GetHistory(1)
+	if latest[0].ID <= before[0].ID {
+		t.Errorf("new ID = %d, want more than %d", latest[0].ID, before[0].ID)
+	}
+}
+
+func TestJSONL_DamagedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	// A corrupt line, a line without an ID, and a write torn by a crash
+	content := `{"id":1,"port_number":3000,"killed_at":"` + now + `"}` + "\n" +
+		"not json\n" +
+		`{"port_number":4000,"killed_at":"` + now + `"}` + "\n" +
+		`{"id":9,"port_num`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	s := newTestJSONL(t, path)
+	history, err := s.GetHistory(10)
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if got := ports(history); len(got) != 2 {
+		t.Fatalf("history = %v, want the two readable entries", got)
+	}
+	if history[0].ID == 0 || history[0].ID == history[1].ID {
+		t.Errorf("IDs = %d, %d; want the entry without an ID given a fresh one", history[0].ID, history[1].ID)
+	}
+
+	// Appending after the torn line loses only that line
+	record(t, s, models.HistoryEntry{PortNumber: 5000, KilledAt: time.Now().Add(time.Minute)})
+	reopened := newTestJSONL(t, path)
+	if history, _ := reopened.GetHistory(10); len(history) != 3 || history[0].PortNumber != 5000 {
+		t.Errorf("history after append = %v, want the new entry kept", ports(history))
+	}
+
+	if err := reopened.Compact(); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 3 || strings.Contains(string(data), "not json") {
+		t.Errorf("compacted file =\n%s\nwant only the three entries", data)
+	}
+}
+
+func TestJSONL_SharedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	// Two stores on one file stand in for the TUI and a CLI command running side by side
+	tui := newTestJSONL(t, path)
+	cli := newTestJSONL(t, path)
+	now := time.Now()
+
+	record(t, tui, models.HistoryEntry{PortNumber: 3000, KilledAt: now.Add(-2 * time.Hour)})
+	record(t, cli, models.HistoryEntry{PortNumber: 8080, KilledAt: now.Add(-time.Hour)})
+	record(t, tui, models.HistoryEntry{PortNumber: 5432, KilledAt: now})
+
+	for name, s := range map[string]*JSONL{"tui": tui, "cli": cli} {
+		history, err := s.GetHistory(10)
+		if err != nil {
+			t.Fatalf("%s GetHistory() error = %v", name, err)
+		}
+		if got := ports(history); !reflect.DeepEqual(got, []int{5432, 8080, 3000}) {
+			t.Errorf("%s history = %v, want both stores' entries", name, got)
+		}
+		if history[0].ID == history[1].ID || history[1].ID == history[2].ID {
+			t.Errorf("%s IDs = %d, %d, %d; want them distinct", name, history[0].ID, history[1].ID, history[2].ID)
+		}
+	}
+
+	// Pruning replaces the file; the other store follows it
+	if removed, err := cli.Prune(0, 1); err != nil || removed != 2 {
+		t.Fatalf("Prune() = %d, %v; want 2", removed, err)
+	}
+	record(t, tui, models.HistoryEntry{PortNumber: 9000, KilledAt: now.Add(time.Minute)})
+	for name, s := range map[string]*JSONL{"tui": tui, "cli": cli} {
+		history, _ := s.GetHistory(10)
+		if got := ports(history); !reflect.DeepEqual(got, []int{9000, 5432}) {
+			t.Errorf("%s history after pruning = %v, want [9000 5432]", name, got)
+		}
+	}
+}
+
+func TestJSONL_Closed(t *testing.T) {
+	s := newTestJSONL(t, filepath.Join(t.TempDir(), "history.jsonl"))
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := s.RecordKill(models.HistoryEntry{PortNumber: 3000, KilledAt: time.Now()}); err == nil {
+		t.Error("RecordKill() after Close() should fail")
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		backend Backend
+		want    string
+	}{
+		{"", "*storage.SQLite"},
+		{BackendSQLite, "*storage.SQLite"},
+		{BackendJSONL, "*storage.JSONL"},
+		{BackendMemory, "*storage.Memory"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.backend), func(t *testing.T) {
+			s, err := Open(Config{Backend: tt.backend, DBPath: filepath.Join(dir, string(tt.backend)+".history"), Timeout: 50})
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer s.Close()
+			if got := reflect.TypeOf(s).String(); got != tt.want {
+				t.Errorf("Open() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Open(Config{Backend: "postgres"}); err == nil {
+		t.Error("Open() should reject an unknown backend")
+	}
+	if b, err := ParseBackend(" JSONL "); err != nil || b != BackendJSONL {
+		t.Errorf("ParseBackend(JSONL) = %q, %v", b, err)
+	}
+}
+
+func TestJSONL_PortEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	s := newTestJSONL(t, path)
+	now := time.Now()
+	node := models.PortInfo{PortNumber: 3000, PID: 101, ProcessName: "node"}
+	if err := s.RecordPortEvents([]models.PortEvent{models.NewPortEvent(models.PortEventAppeared, node, now.Add(-time.Minute))}); err != nil {
+		t.Fatalf("RecordPortEvents() error = %v", err)
+	}
+	s.Close()
+
+	// A write torn by a crash, after which recording continues on a fresh line
+	events := filepath.Join(filepath.Dir(path), "history.events.jsonl")
+	file, err := os.OpenFile(events, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatalf("events file missing: %v", err)
+	}
+	file.WriteString(`{"port":3000,"kind":"disapp`)
+	file.Close()
+
+	reopened := newTestJSONL(t, path)
+	if err := reopened.RecordPortEvents([]models.PortEvent{models.NewPortEvent(models.PortEventDisappeared, node, now)}); err != nil {
+		t.Fatalf("RecordPortEvents() error = %v", err)
+	}
+	got, err := reopened.PortEvents(models.PortEventQuery{Port: 3000})
+	if err != nil {
+		t.Fatalf("PortEvents() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Kind != models.PortEventAppeared || got[1].Kind != models.PortEventDisappeared || !got[1].At.Equal(now) {
+		t.Errorf("events after reopening = %+v, want the appearance and the new disappearance", got)
+	}
+}
@@ -0,0 +1,360 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// Memory is a Storage that keeps history in memory only; it is lost when the process exits.
+// It needs no files or cgo, so it is the last resort when no other backend can be opened,
+// and it is the index the JSONL backend serves reads from.
+type Memory struct {
+	mu sync.RWMutex
+	// entries are ordered oldest first by (KilledAt, ID), the reverse of query order
+	entries []models.HistoryEntry
+	nextID  int64
+	// events are the port timeline, ordered oldest first
+	events      []models.PortEvent
+	nextEventID int64
+	// labels are the port labels, ordered by port, then scope
+	labels []models.PortLabel
+}
+
+// NewMemory creates an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{nextID: 1, nextEventID: 1}
+}
+
+// RecordKill stores entry with the next ID.
+func (m *Memory) RecordKill(entry models.HistoryEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.add(entry)
+	return nil
+}
+
+// ImportEntries adds the entries that aren't stored yet. Duplicates have the same kill time,
+// port, PID and process name.
+func (m *Memory) ImportEntries(entries []models.HistoryEntry) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.importEntries(entries)), nil
+}
+
+// GetHistory returns the newest limit entries.
+func (m *Memory) GetHistory(limit int) ([]models.HistoryEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var entries []models.HistoryEntry
+	for i := len(m.entries) - 1; i >= 0 && len(entries) < limit; i-- {
+		entries = append(entries, m.entries[i])
+	}
+	return entries, nil
+}
+
+// QueryHistory returns one page of the entries matching q, newest first.
+func (m *Memory) QueryHistory(q models.HistoryQuery) (models.HistoryPage, error) {
+	var after *cursor
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return models.HistoryPage{}, err
+		}
+		after = &c
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	limit := pageSize(q)
+	var page models.HistoryPage
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		entry := m.entries[i]
+		if after != nil && !pastCursor(entry, *after) {
+			continue
+		}
+		if !q.Matches(entry) {
+			continue
+		}
+		if len(page.Entries) == limit {
+			page.NextCursor = encodeCursor(page.Entries[limit-1])
+			break
+		}
+		page.Entries = append(page.Entries, entry)
+	}
+	return page, nil
+}
+
+// Stats aggregates the history in the query's window.
+func (m *Memory) Stats(q models.StatsQuery) (models.KillStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return computeStats(m.entries, q), nil
+}
+
+// GetKillCount returns how many successful kills of port happened within the last days.
+func (m *Memory) GetKillCount(port int, days int) (int, error) {
+	since := time.Now().AddDate(0, 0, -days)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	count := 0
+	for _, entry := range m.entries {
+		if entry.PortNumber == port && !entry.Failed && !entry.KilledAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// GetLastKillTime returns when port was last killed successfully (zero if never).
+func (m *Memory) GetLastKillTime(port int) (time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		if entry := m.entries[i]; entry.PortNumber == port && !entry.Failed {
+			return entry.KilledAt, nil
+		}
+	}
+	return time.Time{}, nil
+}
+
+// KillSummaries returns the recent kills and last kill time of each port ever killed successfully.
+func (m *Memory) KillSummaries(ports []int, days int) (map[int]models.PortKillSummary, error) {
+	since := time.Now().AddDate(0, 0, -days)
+	wanted := make(map[int]bool, len(ports))
+	for _, port := range ports {
+		wanted[port] = true
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	summaries := make(map[int]models.PortKillSummary)
+	for _, entry := range m.entries {
+		if !wanted[entry.PortNumber] || entry.Failed {
+			continue
+		}
+		summary := summaries[entry.PortNumber]
+		summary.Port = entry.PortNumber
+		summary.Add(entry.KilledAt, since)
+		summaries[entry.PortNumber] = summary
+	}
+	return summaries, nil
+}
+
+// Prune deletes entries older than maxAge and all but the newest maxRows (0 disables either limit).
+func (m *Memory) Prune(maxAge time.Duration, maxRows int) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.prune(maxAge, maxRows), nil
+}
+
+// RecordPortEvents stores timeline events with the next IDs.
+func (m *Memory) RecordPortEvents(events []models.PortEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, event := range events {
+		event.ID = m.nextEventID
+		m.nextEventID++
+		i := sort.Search(len(m.events), func(i int) bool { return m.events[i].At.After(event.At) })
+		m.events = append(m.events, models.PortEvent{})
+		copy(m.events[i+1:], m.events[i:])
+		m.events[i] = event
+	}
+	return nil
+}
+
+// PortEvents returns the timeline events matching q, oldest first.
+func (m *Memory) PortEvents(q models.PortEventQuery) ([]models.PortEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var events []models.PortEvent
+	for _, event := range m.events {
+		if q.Matches(event) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// PrunePortEvents deletes timeline events older than maxAge (0 keeps them all).
+func (m *Memory) PrunePortEvents(maxAge time.Duration) (int, error) {
+	if maxAge <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().Add(-maxAge)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := sort.Search(len(m.events), func(i int) bool { return !m.events[i].At.Before(cutoff) })
+	m.events = append([]models.PortEvent(nil), m.events[i:]...)
+	return i, nil
+}
+
+// Labels returns every port label, ordered by port, then scope.
+func (m *Memory) Labels() ([]models.PortLabel, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]models.PortLabel(nil), m.labels...), nil
+}
+
+// SaveLabel stores label, replacing the label with the same port and scope.
+func (m *Memory) SaveLabel(label models.PortLabel) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.labels = saveLabel(m.labels, label)
+	return nil
+}
+
+// DeleteLabel deletes the label with the port and scope of label, reporting whether there was one.
+func (m *Memory) DeleteLabel(label models.PortLabel) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deleted bool
+	m.labels, deleted = deleteLabel(m.labels, label)
+	return deleted, nil
+}
+
+// Compact does nothing; memory is freed as entries are pruned.
+func (m *Memory) Compact() error {
+	return nil
+}
+
+// Close does nothing; the history is dropped with the store.
+func (m *Memory) Close() error {
+	return nil
+}
+
+// saveLabel returns labels with label normalized and in place of any label with the same
+// port and scope, keeping them ordered by port, then scope.
+func saveLabel(labels []models.PortLabel, label models.PortLabel) []models.PortLabel {
+	label = label.Normalized()
+	if label.UpdatedAt.IsZero() {
+		label.UpdatedAt = time.Now()
+	}
+	labels, _ = deleteLabel(labels, label)
+	i := sort.Search(len(labels), func(i int) bool { return !labelLess(labels[i], label) })
+	labels = append(labels, models.PortLabel{})
+	copy(labels[i+1:], labels[i:])
+	labels[i] = label
+	return labels
+}
+
+// deleteLabel returns labels without the one with the port and scope of label, and whether it was there.
+func deleteLabel(labels []models.PortLabel, label models.PortLabel) ([]models.PortLabel, bool) {
+	for i, l := range labels {
+		if l.SameScope(label) {
+			return append(labels[:i:i], labels[i+1:]...), true
+		}
+	}
+	return labels, false
+}
+
+// labelLess orders labels by port, then process name, then project, as SQLite does.
+func labelLess(a, b models.PortLabel) bool {
+	if a.Port != b.Port {
+		return a.Port < b.Port
+	}
+	if a.ProcessName != b.ProcessName {
+		return a.ProcessName < b.ProcessName
+	}
+	return a.Project < b.Project
+}
+
+// add inserts entry in order, assigning the next ID, and returns it. The caller holds the lock.
+func (m *Memory) add(entry models.HistoryEntry) models.HistoryEntry {
+	entry.ID = m.nextID
+	m.nextID++
+	m.insert(entry)
+	return entry
+}
+
+// insert places entry (with its ID already set) in (KilledAt, ID) order. The caller holds the lock.
+func (m *Memory) insert(entry models.HistoryEntry) {
+	i := sort.Search(len(m.entries), func(i int) bool { return !lessEntry(m.entries[i], entry) })
+	m.entries = append(m.entries, models.HistoryEntry{})
+	copy(m.entries[i+1:], m.entries[i:])
+	m.entries[i] = entry
+	if entry.ID >= m.nextID {
+		m.nextID = entry.ID + 1
+	}
+}
+
+// remove deletes the entry with id. The caller holds the lock.
+func (m *Memory) remove(id int64) {
+	for i := range m.entries {
+		if m.entries[i].ID == id {
+			m.entries = append(m.entries[:i], m.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// importEntries adds the entries that aren't stored yet and returns them with their IDs.
+// The caller holds the lock.
+func (m *Memory) importEntries(entries []models.HistoryEntry) []models.HistoryEntry {
+	seen := make(map[entryKey]bool, len(m.entries))
+	for _, entry := range m.entries {
+		seen[keyOf(entry)] = true
+	}
+
+	var added []models.HistoryEntry
+	for _, entry := range entries {
+		key := keyOf(entry)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		added = append(added, m.add(entry))
+	}
+	return added
+}
+
+// prune applies the retention limits and returns how many entries it removed. The caller holds the lock.
+func (m *Memory) prune(maxAge time.Duration, maxRows int) int {
+	before := len(m.entries)
+	if maxAge > 0 {
+		cutoff := time.Now().Add(-maxAge)
+		i := sort.Search(len(m.entries), func(i int) bool { return !m.entries[i].KilledAt.Before(cutoff) })
+		m.entries = m.entries[i:]
+	}
+	if maxRows > 0 && len(m.entries) > maxRows {
+		m.entries = m.entries[len(m.entries)-maxRows:]
+	}
+	// Don't keep the pruned entries alive through the backing array
+	m.entries = append([]models.HistoryEntry(nil), m.entries...)
+	return before - len(m.entries)
+}
+
+// entryKey identifies a kill across exports and imports, where IDs differ.
+type entryKey struct {
+	port, pid   int
+	processName string
+	killedAt    int64
+}
+
+// keyOf returns the duplicate-detection key of entry.
+func keyOf(entry models.HistoryEntry) entryKey {
+	return entryKey{entry.PortNumber, entry.PID, entry.ProcessName, entry.KilledAt.UnixNano()}
+}
+
+// lessEntry orders entries by (KilledAt, ID), oldest first.
+func lessEntry(a, b models.HistoryEntry) bool {
+	if !a.KilledAt.Equal(b.KilledAt) {
+		return a.KilledAt.Before(b.KilledAt)
+	}
+	return a.ID < b.ID
+}
+
+// pastCursor reports whether entry comes after the cursor in query order (newest first).
+func pastCursor(entry models.HistoryEntry, c cursor) bool {
+	return entry.KilledAt.Before(c.killedAt) || (entry.KilledAt.Equal(c.killedAt) && entry.ID < c.id)
+}
@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrSchemaTooNew is returned (wrapped) by NewSQLite when the database was written by a newer
+// port-chaser. Opening it anyway could corrupt columns this binary doesn't know about.
+var ErrSchemaTooNew = errors.New("database schema is newer than this version of port-chaser")
+
+// migration upgrades the schema by one version. Each runs in its own transaction together with
+// the PRAGMA user_version bump, so a failure leaves the database at the previous version.
+type migration struct {
+	// version is the user_version after the migration; versions start at 1 and have no gaps
+	version int
+	// name describes the change in errors
+	name string
+	// up applies the change
+	up func(tx *sql.Tx) error
+}
+
+// migrations lists every schema change in order. Append new ones; never edit or reorder
+// released migrations, since existing databases have already applied them.
+var migrations = []migration{
+	{1, "create history table", createHistory},
+	{2, "add kill audit columns", addAuditColumns},
+	{3, "index kills by port and time", indexPortKilledAt},
+	{4, "index kills by process name and time", indexProcessKilledAt},
+	{5, "create port events table", createPortEvents},
+	{6, "create port labels table", createPortLabels},
+}
+
+// schemaVersion is the history schema this binary writes.
+var schemaVersion = migrations[len(migrations)-1].version
+
+// migrate applies the migrations newer than the database's PRAGMA user_version, in order.
+// It refuses databases whose version is newer than the last migration.
+func migrate(db *sql.DB, migrations []migration) error {
+	var current int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&current); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	latest := 0
+	for i, m := range migrations {
+		if m.version != i+1 {
+			return fmt.Errorf("migration %q has version %d, want %d", m.name, m.version, i+1)
+		}
+		latest = m.version
+	}
+	if current > latest {
+		return fmt.Errorf("%w: database is at version %d, this binary supports up to %d", ErrSchemaTooNew, current, latest)
+	}
+
+	for _, m := range migrations[current:] {
+		if err := applyMigration(db, m); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+// applyMigration runs one migration and records its version in a single transaction.
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.up(tx); err != nil {
+		return err
+	}
+	// PRAGMA doesn't take bind parameters; the version is an int from the migration list
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", m.version)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// createHistory creates the history table as it was before the schema was versioned.
+// Databases from that time have user_version 0 and only some of these columns, so every
+// statement is idempotent and missing columns are added in place.
+func createHistory(tx *sql.Tx) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		port_number INTEGER NOT NULL,
+		process_name TEXT NOT NULL,
+		pid INTEGER NOT NULL,
+		command TEXT,
+		killed_at DATETIME NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_history_port ON history(port_number);
+	CREATE INDEX IF NOT EXISTS idx_history_killed_at ON history(killed_at);
+	`
+
+	if _, err := tx.Exec(query); err != nil {
+		return err
+	}
+
+	return ensureColumns(tx, "history", []column{
+		{"manager", "TEXT NOT NULL DEFAULT ''"},
+		{"outcome", "TEXT NOT NULL DEFAULT ''"},
+		{"respawn_pid", "INTEGER NOT NULL DEFAULT 0"},
+		{"supervisor", "TEXT NOT NULL DEFAULT ''"},
+		{"escalated", "INTEGER NOT NULL DEFAULT 0"},
+		{"executable", "TEXT NOT NULL DEFAULT ''"},
+		{"args", "TEXT NOT NULL DEFAULT ''"},
+		{"cwd", "TEXT NOT NULL DEFAULT ''"},
+		{"env", "TEXT NOT NULL DEFAULT ''"},
+	})
+}
+
+// addAuditColumns records how each kill went: the method and time it took, whether it failed
+// and why, who ran it, and which socket and container the port belonged to.
+// Entries written before this migration were all successful kills.
+func addAuditColumns(tx *sql.Tx) error {
+	return ensureColumns(tx, "history", []column{
+		{"success", "INTEGER NOT NULL DEFAULT 1"},
+		{"error", "TEXT NOT NULL DEFAULT ''"},
+		{"method", "TEXT NOT NULL DEFAULT ''"},
+		{"duration_ms", "INTEGER NOT NULL DEFAULT 0"},
+		{"killed_by", "TEXT NOT NULL DEFAULT ''"},
+		{"owner", "TEXT NOT NULL DEFAULT ''"},
+		{"protocol", "TEXT NOT NULL DEFAULT ''"},
+		{"address", "TEXT NOT NULL DEFAULT ''"},
+		{"container_id", "TEXT NOT NULL DEFAULT ''"},
+		{"container_name", "TEXT NOT NULL DEFAULT ''"},
+		{"image_name", "TEXT NOT NULL DEFAULT ''"},
+	})
+}
+
+// indexPortKilledAt lets GetKillCount and GetLastKillTime read one port's recent kills
+// instead of scanning every kill in the time range. It supersedes the port-only index.
+func indexPortKilledAt(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE INDEX IF NOT EXISTS idx_history_port_killed_at ON history(port_number, killed_at);
+	DROP INDEX IF EXISTS idx_history_port;
+	`)
+	return err
+}
+
+// indexProcessKilledAt serves QueryHistory's process name filter, which compares without case.
+func indexProcessKilledAt(tx *sql.Tx) error {
+	_, err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_history_process_killed_at ON history(process_name COLLATE NOCASE, killed_at)")
+	return err
+}
+
+// createPortEvents creates the port occupancy timeline: one row per listener appearing on
+// or disappearing from a port. Timeline queries read one port's events up to a time.
+func createPortEvents(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS port_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		port INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		pid INTEGER NOT NULL,
+		process_name TEXT NOT NULL DEFAULT '',
+		command TEXT NOT NULL DEFAULT '',
+		container_id TEXT NOT NULL DEFAULT '',
+		container_name TEXT NOT NULL DEFAULT '',
+		image_name TEXT NOT NULL DEFAULT '',
+		at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_port_events_port_at ON port_events(port, at);
+	CREATE INDEX IF NOT EXISTS idx_port_events_at ON port_events(at);
+	`)
+	return err
+}
+
+// createPortLabels creates the user's port labels and notes, one per port and scope.
+// The scope columns are stored normalized (see models.PortLabel.Normalized) so they can be unique.
+func createPortLabels(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS port_labels (
+		port INTEGER NOT NULL,
+		process_name TEXT NOT NULL DEFAULT '',
+		project TEXT NOT NULL DEFAULT '',
+		label TEXT NOT NULL DEFAULT '',
+		note TEXT NOT NULL DEFAULT '',
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (port, process_name, project)
+	)`)
+	return err
+}
+
+// column is a column name and its SQL declaration, e.g. {"respawn_pid", "INTEGER NOT NULL DEFAULT 0"}.
+type column struct{ name, decl string }
+
+// ensureColumns adds the columns table doesn't have yet.
+func ensureColumns(tx *sql.Tx, table string, columns []column) error {
+	existing, err := tableColumns(tx, table)
+	if err != nil {
+		return err
+	}
+	for _, col := range columns {
+		if existing[col.name] {
+			continue
+		}
+		if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, col.name, col.decl)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tableColumns returns the set of column names in table.
+func tableColumns(tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.Query("PRAGMA table_info(" + table + ")")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// openRaw opens a SQLite database without running the migrations.
+func openRaw(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// userVersion reads PRAGMA user_version.
+func userVersion(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		t.Fatalf("PRAGMA user_version error = %v", err)
+	}
+	return version
+}
+
+// hasTable reports whether the database has a table called name.
+func hasTable(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count); err != nil {
+		t.Fatalf("sqlite_master query error = %v", err)
+	}
+	return count > 0
+}
+
+func TestMigrate_FreshDatabase(t *testing.T) {
+	db := openRaw(t)
+
+	if err := migrate(db, migrations); err != nil {
+		t.Fatalf("migrate() error = %v", err)
+	}
+	if got := userVersion(t, db); got != schemaVersion {
+		t.Errorf("user_version = %d, want %d", got, schemaVersion)
+	}
+
+	var indexes []string
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = 'history' AND name LIKE 'idx_%' ORDER BY name")
+	if err != nil {
+		t.Fatalf("index query error = %v", err)
+	}
+	for rows.Next() {
+		var name string
+		rows.Scan(&name)
+		indexes = append(indexes, name)
+	}
+	rows.Close()
+	if strings.Join(indexes, ",") != "idx_history_killed_at,idx_history_port_killed_at,idx_history_process_killed_at" {
+		t.Errorf("indexes = %v, want the port index replaced by the port and time index, plus the process index", indexes)
+	}
+
+	// Running again is a no-op
+	if err := migrate(db, migrations); err != nil {
+		t.Fatalf("second migrate() error = %v", err)
+	}
+	if got := userVersion(t, db); got != schemaVersion {
+		t.Errorf("user_version after rerun = %d, want %d", got, schemaVersion)
+	}
+}
+
+func TestMigrate_Order(t *testing.T) {
+	db := openRaw(t)
+
+	var applied []string
+	step := func(name string) func(tx *sql.Tx) error {
+		return func(tx *sql.Tx) error {
+			applied = append(applied, name)
+			_, err := tx.Exec("CREATE TABLE " + name + " (id INTEGER)")
+			return err
+		}
+	}
+	list := []migration{{1, "one", step("one")}, {2, "two", step("two")}}
+
+	if err := migrate(db, list[:1]); err != nil {
+		t.Fatalf("migrate(v1) error = %v", err)
+	}
+	if err := migrate(db, list); err != nil {
+		t.Fatalf("migrate(v2) error = %v", err)
+	}
+	if strings.Join(applied, ",") != "one,two" {
+		t.Errorf("applied = %v, want each migration once, in order", applied)
+	}
+	if got := userVersion(t, db); got != 2 {
+		t.Errorf("user_version = %d, want 2", got)
+	}
+}
+
+func TestMigrate_FailureRollsBack(t *testing.T) {
+	db := openRaw(t)
+
+	list := []migration{
+		{1, "create", func(tx *sql.Tx) error {
+			_, err := tx.Exec("CREATE TABLE kept (id INTEGER)")
+			return err
+		}},
+		{2, "broken", func(tx *sql.Tx) error {
+			if _, err := tx.Exec("CREATE TABLE partial (id INTEGER)"); err != nil {
+				return err
+			}
+			_, err := tx.Exec("ALTER TABLE missing ADD COLUMN x TEXT")
+			return err
+		}},
+	}
+
+	err := migrate(db, list)
+	if err == nil || !strings.Contains(err.Error(), "migration 2 (broken) failed") {
+		t.Fatalf("migrate() error = %v, want the failing migration named", err)
+	}
+	if got := userVersion(t, db); got != 1 {
+		t.Errorf("user_version = %d, want 1 (the last migration that succeeded)", got)
+	}
+	if !hasTable(t, db, "kept") {
+		t.Error("the successful migration should be committed")
+	}
+	if hasTable(t, db, "partial") {
+		t.Error("the failed migration should be rolled back completely")
+	}
+}
+
+func TestMigrate_NewerDatabase(t *testing.T) {
+	db := openRaw(t)
+	if _, err := db.Exec("PRAGMA user_version = 99"); err != nil {
+		t.Fatalf("PRAGMA error = %v", err)
+	}
+
+	err := migrate(db, migrations)
+	if !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("migrate() error = %v, want ErrSchemaTooNew", err)
+	}
+	if got := userVersion(t, db); got != 99 {
+		t.Errorf("user_version = %d, the database must be left untouched", got)
+	}
+
+	// NewSQLite refuses it too, instead of writing rows an older schema doesn't describe
+	dbPath := filepath.Join(t.TempDir(), "newer.db")
+	raw, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	_, err = raw.Exec("PRAGMA user_version = 99")
+	raw.Close()
+	if err != nil {
+		t.Fatalf("PRAGMA error = %v", err)
+	}
+	if _, err := NewSQLite(Config{DBPath: dbPath, Timeout: 50}); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("NewSQLite() error = %v, want ErrSchemaTooNew", err)
+	}
+}
+
+func TestMigrate_InvalidList(t *testing.T) {
+	noop := func(tx *sql.Tx) error { return nil }
+	tests := []struct {
+		name string
+		list []migration
+	}{
+		{"gap", []migration{{1, "one", noop}, {3, "three", noop}}},
+		{"starts at zero", []migration{{0, "zero", noop}}},
+		{"out of order", []migration{{2, "two", noop}, {1, "one", noop}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := migrate(openRaw(t), tt.list); err == nil {
+				t.Error("migrate() should reject a migration list with bad versions")
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/manson/port-chaser/internal/platform"
+)
+
+// sqliteSidecars are the files SQLite keeps next to a database; they must move with it.
+var sqliteSidecars = []string{"-wal", "-shm", "-journal"}
+
+// defaultPath returns where a history file is kept by default: target, in the platform data
+// directory. A file of the same name still in the legacy ~/.port-chaser directory is moved
+// there first; if it can't be moved, it keeps being used where it is.
+func defaultPath(target string) string {
+	legacy := filepath.Join(platform.GetLegacyDataPath(), filepath.Base(target))
+	if err := migrateLegacy(legacy, target); err != nil {
+		return legacy
+	}
+	return target
+}
+
+// migrateLegacy moves the file at legacy, with its SQLite sidecar files, to target.
+// Nothing happens if there is no legacy file or target already exists. If any file can't be
+// moved, the ones already moved are moved back, so the history stays in one place.
+func migrateLegacy(legacy, target string) error {
+	if legacy == target || !exists(legacy) || exists(target) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	var moved []string
+	for _, suffix := range append([]string{""}, sqliteSidecars...) {
+		if suffix != "" && !exists(legacy+suffix) {
+			continue
+		}
+		if err := moveFile(legacy+suffix, target+suffix); err != nil {
+			for _, done := range moved {
+				moveFile(target+done, legacy+done)
+			}
+			return err
+		}
+		moved = append(moved, suffix)
+	}
+
+	// Only succeeds once the legacy directory is empty
+	os.Remove(filepath.Dir(legacy))
+	return nil
+}
+
+// moveFile renames src to dst, copying it when a rename isn't possible
+// (e.g. XDG_DATA_HOME is on another file system).
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+	in.Close()
+	return os.Remove(src)
+}
+
+// exists reports whether path exists (an error other than "not found" counts as existing,
+// so nothing is moved over a file that can't be inspected).
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, os.ErrNotExist)
+}
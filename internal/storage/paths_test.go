@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/platform"
+)
+
+// setDataHome points the home and data directories at dir.
+func setDataHome(t *testing.T, dir string) {
+	t.Helper()
+	for _, name := range []string{"HOME", "USERPROFILE", "XDG_DATA_HOME", "LOCALAPPDATA"} {
+		t.Setenv(name, dir)
+	}
+}
+
+func TestDefaultPath_MigratesLegacyDatabase(t *testing.T) {
+	home := t.TempDir()
+	setDataHome(t, home)
+
+	// A database written by an older version, at the legacy path
+	legacy := filepath.Join(platform.GetLegacyDataPath(), "history.db")
+	old, err := NewSQLite(Config{DBPath: legacy, Timeout: 50, WALEnabled: true})
+	if err != nil {
+		t.Fatalf("NewSQLite(legacy) error = %v", err)
+	}
+	record(t, old, models.HistoryEntry{PortNumber: 3000, ProcessName: "node", KilledAt: time.Now()})
+	old.Close()
+
+	s, err := NewSQLite(Config{Timeout: 50})
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	defer s.Close()
+	history, err := s.GetHistory(10)
+	if err != nil || len(history) != 1 || history[0].PortNumber != 3000 {
+		t.Fatalf("history after migration = %+v, %v; want the legacy entry", history, err)
+	}
+
+	if _, err := os.Stat(platform.GetHistoryPath()); err != nil {
+		t.Errorf("database not at the platform path: %v", err)
+	}
+	if _, err := os.Stat(platform.GetLegacyDataPath()); !os.IsNotExist(err) {
+		t.Errorf("legacy directory should be gone once empty, Stat() error = %v", err)
+	}
+}
+
+func TestMigrateLegacy(t *testing.T) {
+	write := func(t *testing.T, path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	read := func(path string) string {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "<missing>"
+		}
+		return string(data)
+	}
+
+	t.Run("moves sidecars", func(t *testing.T) {
+		dir := t.TempDir()
+		legacy, target := filepath.Join(dir, "old", "history.db"), filepath.Join(dir, "new", "data", "history.db")
+		write(t, legacy, "db")
+		write(t, legacy+"-wal", "wal")
+
+		if err := migrateLegacy(legacy, target); err != nil {
+			t.Fatalf("migrateLegacy() error = %v", err)
+		}
+		if read(target) != "db" || read(target+"-wal") != "wal" || read(target+"-shm") != "<missing>" {
+			t.Errorf("target files = %q, %q, %q; want the database and its WAL", read(target), read(target+"-wal"), read(target+"-shm"))
+		}
+		if read(legacy) != "<missing>" {
+			t.Error("legacy database should be moved, not copied")
+		}
+	})
+
+	t.Run("keeps an existing target", func(t *testing.T) {
+		dir := t.TempDir()
+		legacy, target := filepath.Join(dir, "old", "history.db"), filepath.Join(dir, "new", "history.db")
+		write(t, legacy, "old")
+		write(t, target, "new")
+
+		if err := migrateLegacy(legacy, target); err != nil {
+			t.Fatalf("migrateLegacy() error = %v", err)
+		}
+		if read(target) != "new" || read(legacy) != "old" {
+			t.Errorf("files = %q, %q; want both left alone", read(target), read(legacy))
+		}
+	})
+
+	t.Run("nothing to migrate", func(t *testing.T) {
+		dir := t.TempDir()
+		target := filepath.Join(dir, "new", "history.db")
+		if err := migrateLegacy(filepath.Join(dir, "old", "history.db"), target); err != nil {
+			t.Fatalf("migrateLegacy() error = %v", err)
+		}
+		if _, err := os.Stat(filepath.Dir(target)); !os.IsNotExist(err) {
+			t.Error("no directory should be created when there is nothing to move")
+		}
+	})
+
+	t.Run("rolls back", func(t *testing.T) {
+		dir := t.TempDir()
+		legacy, target := filepath.Join(dir, "old", "history.db"), filepath.Join(dir, "new", "history.db")
+		write(t, legacy, "db")
+		write(t, legacy+"-wal", "wal")
+		// A directory in the way of the WAL makes its move fail after the database moved
+		if err := os.MkdirAll(filepath.Join(target+"-wal", "blocker"), 0755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+
+		if err := migrateLegacy(legacy, target); err == nil {
+			t.Fatal("migrateLegacy() should fail")
+		}
+		if read(legacy) != "db" || read(legacy+"-wal") != "wal" || read(target) != "<missing>" {
+			t.Errorf("files = %q, %q, %q; want everything back at the legacy path", read(legacy), read(legacy+"-wal"), read(target))
+		}
+	})
+}
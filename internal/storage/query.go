@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// ErrInvalidCursor is returned (wrapped) by QueryHistory for a cursor it didn't issue.
+var ErrInvalidCursor = errors.New("invalid history cursor")
+
+// cursor is the position after the last entry of a page. History is ordered by
+// (killed_at, id) descending, so the next page starts strictly below it.
+type cursor struct {
+	killedAt time.Time
+	id       int64
+}
+
+// encodeCursor returns the opaque NextCursor for a page ending at entry.
+// The time keeps its zone offset so it compares equal to the stored value.
+func encodeCursor(entry models.HistoryEntry) string {
+	raw := strconv.FormatInt(entry.ID, 10) + "|" + entry.KilledAt.Format(time.RFC3339Nano)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor parses a cursor made by encodeCursor.
+func decodeCursor(s string) (cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, fmt.Errorf("%w: %q", ErrInvalidCursor, s)
+	}
+	idText, timeText, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return cursor{}, fmt.Errorf("%w: %q", ErrInvalidCursor, s)
+	}
+	id, err := strconv.ParseInt(idText, 10, 64)
+	if err != nil {
+		return cursor{}, fmt.Errorf("%w: %q", ErrInvalidCursor, s)
+	}
+	killedAt, err := time.Parse(time.RFC3339Nano, timeText)
+	if err != nil {
+		return cursor{}, fmt.Errorf("%w: %q", ErrInvalidCursor, s)
+	}
+	return cursor{killedAt: killedAt, id: id}, nil
+}
+
+// pageSize returns the query's limit, or the default page size when it has none.
+func pageSize(q models.HistoryQuery) int {
+	if q.Limit > 0 {
+		return q.Limit
+	}
+	return models.DefaultHistoryPageSize
+}
+
+// escapeLike escapes the LIKE wildcards in s, for use with ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
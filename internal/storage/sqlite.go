@@ -2,14 +2,17 @@ package storage
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 
 	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/platform"
 )
 
 type SQLite struct {
@@ -18,11 +21,7 @@ type SQLite struct {
 
 func NewSQLite(cfg Config) (*SQLite, error) {
 	if cfg.DBPath == "" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to find home directory: %w", err)
-		}
-		cfg.DBPath = filepath.Join(homeDir, ".port-chaser", "history.db")
+		cfg.DBPath = defaultPath(platform.GetHistoryPath())
 	}
 
 	if err := os.MkdirAll(filepath.Dir(cfg.DBPath), 0755); err != nil {
@@ -40,8 +39,9 @@ func NewSQLite(cfg Config) (*SQLite, error) {
 
 	s := &SQLite{db: db}
 
-	if err := s.createTables(); err != nil {
-		return nil, fmt.Errorf("failed to create tables: %w", err)
+	if err := migrate(db, migrations); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
 	if cfg.WALEnabled {
@@ -57,48 +57,88 @@ func NewSQLite(cfg Config) (*SQLite, error) {
 	return s, nil
 }
 
-func (s *SQLite) createTables() error {
-	query := `
-	CREATE TABLE IF NOT EXISTS history (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		port_number INTEGER NOT NULL,
-		process_name TEXT NOT NULL,
-		pid INTEGER NOT NULL,
-		command TEXT,
-		killed_at DATETIME NOT NULL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_history_port ON history(port_number);
-	CREATE INDEX IF NOT EXISTS idx_history_killed_at ON history(killed_at);
-	`
-
-	_, err := s.db.Exec(query)
-	return err
-}
-
 func (s *SQLite) enableWAL() error {
 	_, err := s.db.Exec("PRAGMA journal_mode=WAL;")
 	return err
 }
 
 func (s *SQLite) RecordKill(entry models.HistoryEntry) error {
+	if err := insertEntry(s.db, entry); err != nil {
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+	return nil
+}
+
+// ImportEntries adds the entries that aren't in the history yet, in one transaction, and
+// returns how many it added. An entry is a duplicate if it has the same kill time, port,
+// PID and process name as one already stored (or earlier in entries).
+func (s *SQLite) ImportEntries(entries []models.HistoryEntry) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to import history: %w", err)
+	}
+	defer tx.Rollback()
+
+	added := 0
+	for _, entry := range entries {
+		var exists int
+		err := tx.QueryRow(`
+		SELECT COUNT(*) FROM history
+		WHERE port_number = ? AND killed_at = ? AND pid = ? AND process_name = ?`,
+			entry.PortNumber, entry.KilledAt, entry.PID, entry.ProcessName).Scan(&exists)
+		if err != nil {
+			return 0, fmt.Errorf("failed to check for duplicate history: %w", err)
+		}
+		if exists > 0 {
+			continue
+		}
+		if err := insertEntry(tx, entry); err != nil {
+			return 0, fmt.Errorf("failed to import history: %w", err)
+		}
+		added++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to import history: %w", err)
+	}
+	return added, nil
+}
+
+// execer is implemented by *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertEntry inserts entry as a new history row; its ID is ignored.
+func insertEntry(db execer, entry models.HistoryEntry) error {
 	query := `
-	INSERT INTO history (port_number, process_name, pid, command, killed_at)
-	VALUES (?, ?, ?, ?, ?)
+	INSERT INTO history (port_number, process_name, pid, command, manager, outcome, respawn_pid, supervisor, escalated,
+		executable, args, cwd, env, success, error, method, duration_ms, killed_by, owner, protocol, address,
+		container_id, container_name, image_name, killed_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := s.db.Exec(query, entry.PortNumber, entry.ProcessName, entry.PID, entry.Command, entry.KilledAt)
+	executable, args, cwd, env, err := encodeLaunch(entry.Launch)
 	if err != nil {
-		return fmt.Errorf("failed to record history: %w", err)
+		return fmt.Errorf("failed to encode launch context: %w", err)
 	}
 
-	return nil
+	_, err = db.Exec(query, entry.PortNumber, entry.ProcessName, entry.PID, entry.Command,
+		entry.Manager, entry.Outcome, entry.RespawnPID, entry.Supervisor, entry.Escalated,
+		executable, args, cwd, env, !entry.Failed, entry.Error, entry.Method, entry.Duration.Milliseconds(),
+		entry.KilledBy, entry.Owner, entry.Protocol, entry.Address,
+		entry.ContainerID, entry.ContainerName, entry.ImageName, entry.KilledAt)
+	return err
 }
 
+// historyColumns are the history columns scanned by scanHistory, in order.
+const historyColumns = `id, port_number, process_name, pid, command, manager, outcome, respawn_pid, supervisor, escalated,
+		executable, args, cwd, env, success, error, method, duration_ms, killed_by, owner, protocol, address,
+		container_id, container_name, image_name, killed_at`
+
 func (s *SQLite) GetHistory(limit int) ([]models.HistoryEntry, error) {
 	query := `
-	SELECT id, port_number, process_name, pid, command, killed_at
+	SELECT ` + historyColumns + `
 	FROM history
 	ORDER BY killed_at DESC
 	LIMIT ?
@@ -110,13 +150,105 @@ func (s *SQLite) GetHistory(limit int) ([]models.HistoryEntry, error) {
 	}
 	defer rows.Close()
 
+	return scanHistory(rows)
+}
+
+// QueryHistory returns one page of the entries matching q, newest first.
+// Pages are keyed on (killed_at, id), so entries recorded while paging don't shift later pages.
+func (s *SQLite) QueryHistory(q models.HistoryQuery) (models.HistoryPage, error) {
+	var (
+		where []string
+		args  []interface{}
+	)
+	add := func(cond string, values ...interface{}) {
+		where = append(where, cond)
+		args = append(args, values...)
+	}
+
+	if q.Port != 0 {
+		add("port_number = ?", q.Port)
+	}
+	if q.PortMin != 0 {
+		add("port_number >= ?", q.PortMin)
+	}
+	if q.PortMax != 0 {
+		add("port_number <= ?", q.PortMax)
+	}
+	if q.ProcessName != "" {
+		add("process_name = ? COLLATE NOCASE", q.ProcessName)
+	}
+	if q.Command != "" {
+		add(`command LIKE ? ESCAPE '\'`, "%"+escapeLike(q.Command)+"%")
+	}
+	if !q.Since.IsZero() {
+		add("killed_at >= ?", q.Since)
+	}
+	if !q.Until.IsZero() {
+		add("killed_at < ?", q.Until)
+	}
+	switch q.Status {
+	case models.StatusSucceeded:
+		add("success = 1")
+	case models.StatusFailed:
+		add("success = 0")
+	}
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return models.HistoryPage{}, err
+		}
+		add("(killed_at < ? OR (killed_at = ? AND id < ?))", c.killedAt, c.killedAt, c.id)
+	}
+
+	query := "SELECT " + historyColumns + " FROM history"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	// One extra row tells whether there is a next page
+	limit := pageSize(q)
+	query += " ORDER BY killed_at DESC, id DESC LIMIT ?"
+	args = append(args, limit+1)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return models.HistoryPage{}, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	entries, err := scanHistory(rows)
+	if err != nil {
+		return models.HistoryPage{}, err
+	}
+
+	page := models.HistoryPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.NextCursor = encodeCursor(entries[limit-1])
+	}
+	return page, nil
+}
+
+// scanHistory reads history rows selected with historyColumns.
+func scanHistory(rows *sql.Rows) ([]models.HistoryEntry, error) {
 	var entries []models.HistoryEntry
 	for rows.Next() {
-		var entry models.HistoryEntry
-		err := rows.Scan(&entry.ID, &entry.PortNumber, &entry.ProcessName, &entry.PID, &entry.Command, &entry.KilledAt)
+		var (
+			entry                      models.HistoryEntry
+			executable, args, cwd, env string
+			durationMS                 int64
+			success                    bool
+		)
+		err := rows.Scan(&entry.ID, &entry.PortNumber, &entry.ProcessName, &entry.PID, &entry.Command,
+			&entry.Manager, &entry.Outcome, &entry.RespawnPID, &entry.Supervisor, &entry.Escalated,
+			&executable, &args, &cwd, &env, &success, &entry.Error, &entry.Method, &durationMS,
+			&entry.KilledBy, &entry.Owner, &entry.Protocol, &entry.Address,
+			&entry.ContainerID, &entry.ContainerName, &entry.ImageName, &entry.KilledAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan history row: %w", err)
 		}
+		entry.Launch = decodeLaunch(executable, args, cwd, env)
+		entry.Duration = time.Duration(durationMS) * time.Millisecond
+		entry.Failed = !success
 		entries = append(entries, entry)
 	}
 
@@ -127,13 +259,148 @@ func (s *SQLite) GetHistory(limit int) ([]models.HistoryEntry, error) {
 	return entries, nil
 }
 
+// Stats aggregates the history in the query's window. Days and hours come from the recorded
+// timestamp text, so they are in the local time of the machine that recorded each kill.
+func (s *SQLite) Stats(q models.StatsQuery) (models.KillStats, error) {
+	stats := models.KillStats{Since: q.Since, Until: q.Until}
+	where := "1 = 1"
+	var window []interface{}
+	if !q.Since.IsZero() {
+		where += " AND killed_at >= ?"
+		window = append(window, q.Since)
+	}
+	if !q.Until.IsZero() {
+		where += " AND killed_at < ?"
+		window = append(window, q.Until)
+	}
+	kills := where + " AND success = 1"
+	// withWindow returns the window's arguments followed by extra, without sharing window's array
+	withWindow := func(extra ...interface{}) []interface{} {
+		return append(append([]interface{}{}, window...), extra...)
+	}
+	top := q.Top
+	if top <= 0 {
+		top = models.DefaultStatsTop
+	}
+
+	err := s.db.QueryRow("SELECT COUNT(*), COALESCE(SUM(success), 0) FROM history WHERE "+where, window...).
+		Scan(&stats.Attempts, &stats.Kills)
+	if err != nil {
+		return stats, fmt.Errorf("failed to count kills: %w", err)
+	}
+	stats.Failed = stats.Attempts - stats.Kills
+
+	err = s.eachRow(`
+	SELECT port_number, COUNT(*) AS kills,
+		(SELECT process_name FROM history latest WHERE latest.port_number = history.port_number
+			ORDER BY killed_at DESC LIMIT 1)
+	FROM history WHERE `+kills+`
+	GROUP BY port_number ORDER BY kills DESC, port_number LIMIT ?`, withWindow(top), func(rows *sql.Rows) error {
+		var c models.PortKillCount
+		err := rows.Scan(&c.Port, &c.Kills, &c.ProcessName)
+		stats.TopPorts = append(stats.TopPorts, c)
+		return err
+	})
+	if err != nil {
+		return stats, fmt.Errorf("failed to rank ports: %w", err)
+	}
+
+	err = s.eachRow(`
+	SELECT process_name, COUNT(*) AS kills FROM history WHERE `+kills+`
+	GROUP BY process_name COLLATE NOCASE ORDER BY kills DESC, process_name LIMIT ?`, withWindow(top), func(rows *sql.Rows) error {
+		var c models.ProcessKillCount
+		err := rows.Scan(&c.ProcessName, &c.Kills)
+		stats.TopProcesses = append(stats.TopProcesses, c)
+		return err
+	})
+	if err != nil {
+		return stats, fmt.Errorf("failed to rank processes: %w", err)
+	}
+
+	err = s.eachRow(`
+	SELECT substr(killed_at, 1, 10) AS day, COUNT(*) FROM history WHERE `+kills+`
+	GROUP BY day ORDER BY day`, window, func(rows *sql.Rows) error {
+		var c models.DayKillCount
+		err := rows.Scan(&c.Day, &c.Kills)
+		stats.PerDay = append(stats.PerDay, c)
+		return err
+	})
+	if err != nil {
+		return stats, fmt.Errorf("failed to count kills per day: %w", err)
+	}
+
+	err = s.eachRow(`
+	SELECT CAST(substr(killed_at, 12, 2) AS INTEGER) AS hour, COUNT(*) FROM history WHERE `+kills+`
+	GROUP BY hour`, window, func(rows *sql.Rows) error {
+		var hour, count int
+		if err := rows.Scan(&hour, &count); err != nil {
+			return err
+		}
+		if hour >= 0 && hour < 24 {
+			stats.PerHour[hour] = count
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, fmt.Errorf("failed to count kills per hour: %w", err)
+	}
+
+	err = s.eachRow(`
+	SELECT method, COUNT(*) AS kills FROM history WHERE `+kills+` AND method != ''
+	GROUP BY method ORDER BY kills DESC, method`, window, func(rows *sql.Rows) error {
+		var c models.MethodKillCount
+		err := rows.Scan(&c.Method, &c.Kills)
+		stats.Methods = append(stats.Methods, c)
+		return err
+	})
+	if err != nil {
+		return stats, fmt.Errorf("failed to count kill methods: %w", err)
+	}
+	stats.ForcedRatio = forcedRatio(stats.Methods)
+
+	// The median is the middle duration, or the mean of the middle two
+	var timed int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM history WHERE "+kills+" AND duration_ms > 0", window...).Scan(&timed); err != nil {
+		return stats, fmt.Errorf("failed to count kill durations: %w", err)
+	}
+	if timed > 0 {
+		var median float64
+		err := s.db.QueryRow(`
+		SELECT AVG(duration_ms) FROM (
+			SELECT duration_ms FROM history WHERE `+kills+` AND duration_ms > 0
+			ORDER BY duration_ms LIMIT ? OFFSET ?
+		)`, withWindow(2-timed%2, (timed-1)/2)...).Scan(&median)
+		if err != nil {
+			return stats, fmt.Errorf("failed to find the median kill duration: %w", err)
+		}
+		stats.MedianDuration = time.Duration(median * float64(time.Millisecond))
+	}
+
+	return stats, nil
+}
+
+// eachRow runs query and calls fn for every row.
+func (s *SQLite) eachRow(query string, args []interface{}, fn func(rows *sql.Rows) error) error {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 func (s *SQLite) GetKillCount(port int, days int) (int, error) {
 	since := time.Now().AddDate(0, 0, -days)
 
 	query := `
 	SELECT COUNT(*)
 	FROM history
-	WHERE port_number = ? AND killed_at >= ?
+	WHERE port_number = ? AND killed_at >= ? AND success = 1
 	`
 
 	var count int
@@ -145,11 +412,60 @@ func (s *SQLite) GetKillCount(port int, days int) (int, error) {
 	return count, nil
 }
 
+// maxPortsPerQuery bounds the ports KillSummaries puts in one IN list, well under SQLite's
+// limit on bound parameters.
+const maxPortsPerQuery = 500
+
+// KillSummaries returns the recent kills and last kill time of each port ever killed
+// successfully, reading each port's kills once through the (port_number, killed_at) index.
+// Only the kills in the window and each port's latest kill are read back.
+func (s *SQLite) KillSummaries(ports []int, days int) (map[int]models.PortKillSummary, error) {
+	since := time.Now().AddDate(0, 0, -days)
+	summaries := make(map[int]models.PortKillSummary)
+	for len(ports) > 0 {
+		batch := ports
+		if len(batch) > maxPortsPerQuery {
+			batch = batch[:maxPortsPerQuery]
+		}
+		ports = ports[len(batch):]
+
+		var args []interface{}
+		for _, port := range batch {
+			args = append(args, port)
+		}
+		args = append(args, since)
+		// The window function keeps killed_at a column, so it is read back as a time
+		query := `
+		SELECT port_number, killed_at FROM (
+			SELECT port_number, killed_at,
+				ROW_NUMBER() OVER (PARTITION BY port_number ORDER BY killed_at DESC) AS latest
+			FROM history
+			WHERE success = 1 AND port_number IN (?` + strings.Repeat(", ?", len(batch)-1) + `)
+		) WHERE latest = 1 OR killed_at >= ?`
+		err := s.eachRow(query, args, func(rows *sql.Rows) error {
+			var port int
+			var killedAt time.Time
+			if err := rows.Scan(&port, &killedAt); err != nil {
+				return err
+			}
+			summary := summaries[port]
+			summary.Port = port
+			summary.Add(killedAt, since)
+			summaries[port] = summary
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to summarize kills: %w", err)
+		}
+	}
+	return summaries, nil
+}
+
 func (s *SQLite) GetLastKillTime(port int) (time.Time, error) {
 	query := `
 	SELECT killed_at
 	FROM history
-	WHERE port_number = ?
+	WHERE port_number = ? AND success = 1
 	ORDER BY killed_at DESC
 	LIMIT 1
 	`
@@ -166,9 +482,218 @@ func (s *SQLite) GetLastKillTime(port int) (time.Time, error) {
 	return killedAt, nil
 }
 
+// Prune deletes history beyond the retention limits in one transaction: entries killed more
+// than maxAge ago, then all but the newest maxRows entries. Zero disables a limit.
+func (s *SQLite) Prune(maxAge time.Duration, maxRows int) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune history: %w", err)
+	}
+	defer tx.Rollback()
+
+	var removed int64
+	if maxAge > 0 {
+		result, err := tx.Exec("DELETE FROM history WHERE killed_at < ?", time.Now().Add(-maxAge))
+		if err != nil {
+			return 0, fmt.Errorf("failed to prune old history: %w", err)
+		}
+		n, _ := result.RowsAffected()
+		removed += n
+	}
+	if maxRows > 0 {
+		result, err := tx.Exec(`
+		DELETE FROM history WHERE id NOT IN (
+			SELECT id FROM history ORDER BY killed_at DESC, id DESC LIMIT ?
+		)`, maxRows)
+		if err != nil {
+			return 0, fmt.Errorf("failed to prune excess history: %w", err)
+		}
+		n, _ := result.RowsAffected()
+		removed += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to prune history: %w", err)
+	}
+	return int(removed), nil
+}
+
+// Compact returns the pages freed by deletes to the file system and updates the statistics
+// the query planner uses to pick indexes.
+func (s *SQLite) Compact() error {
+	if _, err := s.db.Exec("PRAGMA optimize"); err != nil {
+		return fmt.Errorf("failed to optimize database: %w", err)
+	}
+	if _, err := s.db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	return nil
+}
+
+// RecordPortEvents stores timeline events in one transaction; their IDs are ignored.
+func (s *SQLite) RecordPortEvents(events []models.PortEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to record port events: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, event := range events {
+		_, err := tx.Exec(`
+		INSERT INTO port_events (port, kind, pid, process_name, command, container_id, container_name, image_name, at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			event.Port, event.Kind, event.PID, event.ProcessName, event.Command,
+			event.ContainerID, event.ContainerName, event.ImageName, event.At)
+		if err != nil {
+			return fmt.Errorf("failed to record port events: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to record port events: %w", err)
+	}
+	return nil
+}
+
+// PortEvents returns the timeline events matching q, oldest first.
+func (s *SQLite) PortEvents(q models.PortEventQuery) ([]models.PortEvent, error) {
+	var where []string
+	var args []interface{}
+	if q.Port != 0 {
+		where = append(where, "port = ?")
+		args = append(args, q.Port)
+	}
+	if !q.Since.IsZero() {
+		where = append(where, "at >= ?")
+		args = append(args, q.Since)
+	}
+	if !q.Until.IsZero() {
+		where = append(where, "at < ?")
+		args = append(args, q.Until)
+	}
+	query := `
+	SELECT id, port, kind, pid, process_name, command, container_id, container_name, image_name, at
+	FROM port_events`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY at, id"
+
+	var events []models.PortEvent
+	err := s.eachRow(query, args, func(rows *sql.Rows) error {
+		var event models.PortEvent
+		if err := rows.Scan(&event.ID, &event.Port, &event.Kind, &event.PID, &event.ProcessName, &event.Command,
+			&event.ContainerID, &event.ContainerName, &event.ImageName, &event.At); err != nil {
+			return err
+		}
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query port events: %w", err)
+	}
+	return events, nil
+}
+
+// PrunePortEvents deletes timeline events older than maxAge (0 keeps them all).
+func (s *SQLite) PrunePortEvents(maxAge time.Duration) (int, error) {
+	if maxAge <= 0 {
+		return 0, nil
+	}
+	result, err := s.db.Exec("DELETE FROM port_events WHERE at < ?", time.Now().Add(-maxAge))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune port events: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return int(n), nil
+}
+
+// Labels returns every port label, ordered by port, then scope.
+func (s *SQLite) Labels() ([]models.PortLabel, error) {
+	query := `
+	SELECT port, process_name, project, label, note, updated_at
+	FROM port_labels
+	ORDER BY port, process_name, project`
+
+	var labels []models.PortLabel
+	err := s.eachRow(query, nil, func(rows *sql.Rows) error {
+		var l models.PortLabel
+		if err := rows.Scan(&l.Port, &l.ProcessName, &l.Project, &l.Label, &l.Note, &l.UpdatedAt); err != nil {
+			return err
+		}
+		labels = append(labels, l)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query labels: %w", err)
+	}
+	return labels, nil
+}
+
+// SaveLabel stores label, replacing the label with the same port and scope.
+func (s *SQLite) SaveLabel(label models.PortLabel) error {
+	label = label.Normalized()
+	if label.UpdatedAt.IsZero() {
+		label.UpdatedAt = time.Now()
+	}
+	_, err := s.db.Exec(`
+	INSERT OR REPLACE INTO port_labels (port, process_name, project, label, note, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?)`,
+		label.Port, label.ProcessName, label.Project, label.Label, label.Note, label.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save label: %w", err)
+	}
+	return nil
+}
+
+// DeleteLabel deletes the label with the port and scope of label, reporting whether there was one.
+func (s *SQLite) DeleteLabel(label models.PortLabel) (bool, error) {
+	label = label.Normalized()
+	result, err := s.db.Exec("DELETE FROM port_labels WHERE port = ? AND process_name = ? AND project = ?",
+		label.Port, label.ProcessName, label.Project)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete label: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
 func (s *SQLite) Close() error {
 	if s.db != nil {
 		return s.db.Close()
 	}
 	return nil
 }
+
+// encodeLaunch flattens a launch context into the history columns; argv and env are stored as JSON arrays.
+// A nil context is stored as empty strings.
+func encodeLaunch(launch *models.LaunchContext) (executable, args, cwd, env string, err error) {
+	if launch == nil {
+		return "", "", "", "", nil
+	}
+	argsJSON, err := json.Marshal(launch.Args)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	envJSON, err := json.Marshal(launch.Env)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	return launch.Executable, string(argsJSON), launch.Cwd, string(envJSON), nil
+}
+
+// decodeLaunch rebuilds a launch context from the history columns.
+// Rows without argv (older entries, or capture failed) have no launch context.
+func decodeLaunch(executable, args, cwd, env string) *models.LaunchContext {
+	launch := &models.LaunchContext{Executable: executable, Cwd: cwd}
+	if args == "" || json.Unmarshal([]byte(args), &launch.Args) != nil || len(launch.Args) == 0 {
+		return nil
+	}
+	if env != "" {
+		// A corrupt env column still leaves a usable launch with the current environment
+		_ = json.Unmarshal([]byte(env), &launch.Env)
+	}
+	return launch
+}
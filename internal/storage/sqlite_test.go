@@ -1,8 +1,11 @@
 package storage
 
 import (
+	"database/sql"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -530,6 +533,431 @@ func TestSQLite_EmptyDatabase(t *testing.T) {
 	}
 }
 
+func TestSQLite_ManagerColumn(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+
+	// Create a database with the original schema (no manager column)
+	legacy, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	_, err = legacy.Exec(`
+	CREATE TABLE history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		port_number INTEGER NOT NULL,
+		process_name TEXT NOT NULL,
+		pid INTEGER NOT NULL,
+		command TEXT,
+		killed_at DATETIME NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	INSERT INTO history (port_number, process_name, pid, command, killed_at)
+	VALUES (3000, 'node', 1234, 'npm start', '2024-01-01 10:00:00');
+	`)
+	legacy.Close()
+	if err != nil {
+		t.Fatalf("legacy schema setup error = %v", err)
+	}
+
+	s, err := NewSQLite(Config{DBPath: dbPath, Timeout: 50})
+	if err != nil {
+		t.Fatalf("NewSQLite() on legacy database error = %v", err)
+	}
+	defer s.Close()
+
+	entry := models.HistoryEntry{
+		PortNumber:  4000,
+		ProcessName: "node",
+		PID:         4321,
+		Command:     "node api.js",
+		Manager:     "pm2",
+		Escalated:   true,
+		KilledAt:    time.Now(),
+	}
+	if err := s.RecordKill(entry); err != nil {
+		t.Fatalf("RecordKill() error = %v", err)
+	}
+
+	history, err := s.GetHistory(10)
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("history count = %d, want 2", len(history))
+	}
+	if history[0].Manager != "pm2" {
+		t.Errorf("newest entry Manager = %q, want pm2", history[0].Manager)
+	}
+	if history[1].Manager != "" {
+		t.Errorf("legacy entry Manager = %q, want empty", history[1].Manager)
+	}
+	if !history[0].Escalated || history[1].Escalated {
+		t.Errorf("Escalated = %v/%v, want true for the new entry only", history[0].Escalated, history[1].Escalated)
+	}
+}
+
+func TestSQLite_LaunchContext(t *testing.T) {
+	s, err := NewSQLite(Config{DBPath: filepath.Join(t.TempDir(), "test.db"), Timeout: 50})
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	defer s.Close()
+
+	launch := &models.LaunchContext{
+		Executable: "/usr/bin/node",
+		Args:       []string{"node", "server.js", "--port", "3000"},
+		Cwd:        "/home/dev/app",
+		Env:        []string{"PATH=/usr/bin", "NODE_ENV=development"},
+	}
+	entries := []models.HistoryEntry{
+		{PortNumber: 8080, ProcessName: "python", PID: 1, KilledAt: time.Now().Add(-time.Minute)},
+		{PortNumber: 3000, ProcessName: "node", PID: 2, Launch: launch, KilledAt: time.Now()},
+	}
+	for _, entry := range entries {
+		if err := s.RecordKill(entry); err != nil {
+			t.Fatalf("RecordKill() error = %v", err)
+		}
+	}
+
+	history, err := s.GetHistory(10)
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("history count = %d, want 2", len(history))
+	}
+	if !reflect.DeepEqual(history[0].Launch, launch) {
+		t.Errorf("Launch = %+v, want %+v", history[0].Launch, launch)
+	}
+	if history[1].Launch != nil {
+		t.Errorf("entry without a launch context got %+v, want nil", history[1].Launch)
+	}
+}
+
+func TestSQLite_AuditColumns(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "v1.db")
+
+	// A version 1 database: every column added before the schema was versioned, user_version 0
+	legacy, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	_, err = legacy.Exec(`
+	CREATE TABLE history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		port_number INTEGER NOT NULL,
+		process_name TEXT NOT NULL,
+		pid INTEGER NOT NULL,
+		command TEXT,
+		killed_at DATETIME NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		manager TEXT NOT NULL DEFAULT '',
+		outcome TEXT NOT NULL DEFAULT '',
+		respawn_pid INTEGER NOT NULL DEFAULT 0,
+		supervisor TEXT NOT NULL DEFAULT '',
+		escalated INTEGER NOT NULL DEFAULT 0,
+		executable TEXT NOT NULL DEFAULT '',
+		args TEXT NOT NULL DEFAULT '',
+		cwd TEXT NOT NULL DEFAULT '',
+		env TEXT NOT NULL DEFAULT ''
+	);
+	INSERT INTO history (port_number, process_name, pid, command, outcome, killed_at)
+	VALUES (3000, 'node', 1234, 'npm start', 'killed', datetime('now', '-1 hour'));
+	`)
+	legacy.Close()
+	if err != nil {
+		t.Fatalf("legacy schema setup error = %v", err)
+	}
+
+	s, err := NewSQLite(Config{DBPath: dbPath, Timeout: 50})
+	if err != nil {
+		t.Fatalf("NewSQLite() on a version 1 database error = %v", err)
+	}
+	defer s.Close()
+
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil || version != schemaVersion {
+		t.Errorf("user_version = %d (%v), want %d", version, err, schemaVersion)
+	}
+
+	killed := models.HistoryEntry{
+		PortNumber: 3000, ProcessName: "node", PID: 2000, Outcome: models.OutcomeKilled,
+		Method: "SIGINT", Duration: 1500 * time.Millisecond, KilledBy: "dev", Owner: "dev",
+		Protocol: "tcp6", Address: "::", ContainerID: "abc123", ContainerName: "web", ImageName: "node:20",
+		KilledAt: time.Now(),
+	}
+	failed := models.HistoryEntry{
+		PortNumber: 3000, ProcessName: "node", PID: 2001, Outcome: models.OutcomeFailed,
+		Failed: true, Error: "permission denied", KilledBy: "dev", Owner: "root",
+		KilledAt: time.Now().Add(time.Second),
+	}
+	for _, entry := range []models.HistoryEntry{killed, failed} {
+		if err := s.RecordKill(entry); err != nil {
+			t.Fatalf("RecordKill() error = %v", err)
+		}
+	}
+
+	history, err := s.GetHistory(10)
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("history count = %d, want 3", len(history))
+	}
+
+	got := history[1]
+	got.ID, got.KilledAt, killed.KilledAt = 0, time.Time{}, time.Time{}
+	if !reflect.DeepEqual(got, killed) {
+		t.Errorf("round trip = %+v, want %+v", got, killed)
+	}
+	if !history[0].Failed || history[0].Error != "permission denied" || history[0].Owner != "root" {
+		t.Errorf("failed entry = %+v", history[0])
+	}
+	if legacyEntry := history[2]; legacyEntry.Failed || legacyEntry.Method != "" {
+		t.Errorf("legacy entry = %+v, want a successful kill with no audit details", legacyEntry)
+	}
+
+	// Failed attempts don't count as kills
+	if count, err := s.GetKillCount(3000, 1); err != nil || count != 2 {
+		t.Errorf("GetKillCount() = %d, %v; want 2", count, err)
+	}
+}
+
+func TestSQLite_Prune(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		maxAge      time.Duration
+		maxRows     int
+		wantRemoved int
+		wantPIDs    []int
+	}{
+		{"no limits", 0, 0, 0, []int{1, 2, 3, 4, 5}},
+		{"max age", 50 * time.Hour, 0, 2, []int{1, 2, 3}},
+		{"max rows", 0, 2, 3, []int{1, 2}},
+		{"both", 50 * time.Hour, 4, 2, []int{1, 2, 3}},
+		{"rows tighter than age", 50 * time.Hour, 1, 4, []int{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSQLite(Config{DBPath: filepath.Join(t.TempDir(), "test.db"), Timeout: 50})
+			if err != nil {
+				t.Fatalf("NewSQLite() error = %v", err)
+			}
+			defer s.Close()
+
+			// PID n was killed n-1 days ago
+			for pid := 1; pid <= 5; pid++ {
+				entry := models.HistoryEntry{PortNumber: 3000, ProcessName: "node", PID: pid, KilledAt: now.AddDate(0, 0, 1-pid)}
+				if err := s.RecordKill(entry); err != nil {
+					t.Fatalf("RecordKill() error = %v", err)
+				}
+			}
+
+			removed, err := s.Prune(tt.maxAge, tt.maxRows)
+			if err != nil {
+				t.Fatalf("Prune() error = %v", err)
+			}
+			if removed != tt.wantRemoved {
+				t.Errorf("Prune() removed %d, want %d", removed, tt.wantRemoved)
+			}
+
+			history, err := s.GetHistory(10)
+			if err != nil {
+				t.Fatalf("GetHistory() error = %v", err)
+			}
+			var pids []int
+			for _, entry := range history {
+				pids = append(pids, entry.PID)
+			}
+			if !reflect.DeepEqual(pids, tt.wantPIDs) {
+				t.Errorf("remaining PIDs = %v, want %v", pids, tt.wantPIDs)
+			}
+
+			if err := s.Compact(); err != nil {
+				t.Errorf("Compact() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestSQLite_QueryHistory(t *testing.T) {
+	s, err := NewSQLite(Config{DBPath: filepath.Join(t.TempDir(), "test.db"), Timeout: 50})
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	defer s.Close()
+
+	// PID n was killed n hours ago
+	now := time.Now()
+	entries := []models.HistoryEntry{
+		{PortNumber: 3000, ProcessName: "node", Command: "npm run dev"},
+		{PortNumber: 3001, ProcessName: "Node", Command: "node server.js", Failed: true},
+		{PortNumber: 5432, ProcessName: "postgres", Command: "postgres -D /data"},
+		{PortNumber: 8080, ProcessName: "java", Command: "java -jar 100%_app.jar"},
+		{PortNumber: 3000, ProcessName: "vite", Command: "vite --port 3000"},
+	}
+	for i := range entries {
+		entries[i].PID = i + 1
+		entries[i].KilledAt = now.Add(-time.Duration(i+1) * time.Hour)
+		if err := s.RecordKill(entries[i]); err != nil {
+			t.Fatalf("RecordKill() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		query    models.HistoryQuery
+		wantPIDs []int
+	}{
+		{"all", models.HistoryQuery{}, []int{1, 2, 3, 4, 5}},
+		{"port", models.HistoryQuery{Port: 3000}, []int{1, 5}},
+		{"port range", models.HistoryQuery{PortMin: 3000, PortMax: 3999}, []int{1, 2, 5}},
+		{"process name ignores case", models.HistoryQuery{ProcessName: "NODE"}, []int{1, 2}},
+		{"command substring", models.HistoryQuery{Command: "RUN DEV"}, []int{1}},
+		{"command wildcards are literal", models.HistoryQuery{Command: "0%_"}, []int{4}},
+		{"date range", models.HistoryQuery{Since: now.Add(-210 * time.Minute), Until: now.Add(-90 * time.Minute)}, []int{2, 3}},
+		{"succeeded", models.HistoryQuery{Status: models.StatusSucceeded, Port: 3001}, nil},
+		{"failed", models.HistoryQuery{Status: models.StatusFailed}, []int{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := s.QueryHistory(tt.query)
+			if err != nil {
+				t.Fatalf("QueryHistory() error = %v", err)
+			}
+			var pids []int
+			for _, entry := range page.Entries {
+				pids = append(pids, entry.PID)
+				if !tt.query.Matches(entry) {
+					t.Errorf("entry %+v doesn't match the query", entry)
+				}
+			}
+			if !reflect.DeepEqual(pids, tt.wantPIDs) {
+				t.Errorf("PIDs = %v, want %v", pids, tt.wantPIDs)
+			}
+			if page.NextCursor != "" {
+				t.Errorf("NextCursor = %q, want none on the only page", page.NextCursor)
+			}
+		})
+	}
+}
+
+func TestSQLite_QueryHistoryPagination(t *testing.T) {
+	s, err := NewSQLite(Config{DBPath: filepath.Join(t.TempDir(), "test.db"), Timeout: 50})
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	defer s.Close()
+
+	// Entries sharing a timestamp must neither repeat nor go missing across pages
+	now := time.Now()
+	for pid := 1; pid <= 7; pid++ {
+		killedAt := now.Add(-time.Duration(pid/3) * time.Minute)
+		if err := s.RecordKill(models.HistoryEntry{PortNumber: 3000, PID: pid, KilledAt: killedAt}); err != nil {
+			t.Fatalf("RecordKill() error = %v", err)
+		}
+	}
+
+	var pids []int
+	query := models.HistoryQuery{Port: 3000, Limit: 3}
+	for pages := 1; ; pages++ {
+		page, err := s.QueryHistory(query)
+		if err != nil {
+			t.Fatalf("QueryHistory() error = %v", err)
+		}
+		for _, entry := range page.Entries {
+			pids = append(pids, entry.PID)
+		}
+		if page.NextCursor == "" {
+			if pages != 3 {
+				t.Errorf("got %d pages, want 3", pages)
+			}
+			break
+		}
+		if pages > 3 {
+			t.Fatal("pagination doesn't end")
+		}
+		query.Cursor = page.NextCursor
+	}
+	if want := []int{2, 1, 5, 4, 3, 7, 6}; !reflect.DeepEqual(pids, want) {
+		t.Errorf("PIDs = %v, want %v", pids, want)
+	}
+
+	if _, err := s.QueryHistory(models.HistoryQuery{Cursor: "not a cursor"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("QueryHistory(bad cursor) error = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestSQLite_Stats(t *testing.T) {
+	s, err := NewSQLite(Config{DBPath: filepath.Join(t.TempDir(), "test.db"), Timeout: 50})
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	defer s.Close()
+
+	day := func(d, hour int) time.Time {
+		return time.Date(2026, 10, d, hour, 30, 0, 0, time.Local)
+	}
+	entries := []models.HistoryEntry{
+		{PortNumber: 3000, ProcessName: "node", Method: "SIGTERM", Duration: 100 * time.Millisecond, KilledAt: day(10, 9)},
+		{PortNumber: 3000, ProcessName: "node", Method: "SIGTERM", Duration: 300 * time.Millisecond, KilledAt: day(10, 9)},
+		{PortNumber: 3000, ProcessName: "vite", Method: "SIGKILL", Duration: 3200 * time.Millisecond, KilledAt: day(11, 14)},
+		{PortNumber: 5432, ProcessName: "postgres", Method: "SIGINT", Duration: 200 * time.Millisecond, KilledAt: day(11, 9)},
+		{PortNumber: 8080, ProcessName: "Node", Failed: true, Error: "permission denied", KilledAt: day(12, 9)},
+		// Outside the window
+		{PortNumber: 9000, ProcessName: "java", Method: "SIGKILL", Duration: time.Second, KilledAt: day(1, 9)},
+	}
+	for _, entry := range entries {
+		if err := s.RecordKill(entry); err != nil {
+			t.Fatalf("RecordKill() error = %v", err)
+		}
+	}
+
+	stats, err := s.Stats(models.StatsQuery{Since: day(5, 0), Until: day(13, 0), Top: 2})
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+
+	if stats.Attempts != 5 || stats.Kills != 4 || stats.Failed != 1 {
+		t.Errorf("attempts/kills/failed = %d/%d/%d, want 5/4/1", stats.Attempts, stats.Kills, stats.Failed)
+	}
+	wantPorts := []models.PortKillCount{{Port: 3000, ProcessName: "vite", Kills: 3}, {Port: 5432, ProcessName: "postgres", Kills: 1}}
+	if !reflect.DeepEqual(stats.TopPorts, wantPorts) {
+		t.Errorf("TopPorts = %+v, want %+v", stats.TopPorts, wantPorts)
+	}
+	// Failed kills don't count, and names group without case
+	wantProcesses := []models.ProcessKillCount{{ProcessName: "node", Kills: 2}, {ProcessName: "postgres", Kills: 1}}
+	if !reflect.DeepEqual(stats.TopProcesses, wantProcesses) {
+		t.Errorf("TopProcesses = %+v, want %+v", stats.TopProcesses, wantProcesses)
+	}
+	wantDays := []models.DayKillCount{{Day: "2026-10-10", Kills: 2}, {Day: "2026-10-11", Kills: 2}}
+	if !reflect.DeepEqual(stats.PerDay, wantDays) {
+		t.Errorf("PerDay = %+v, want %+v", stats.PerDay, wantDays)
+	}
+	if stats.PerHour[9] != 3 || stats.PerHour[14] != 1 {
+		t.Errorf("PerHour = %v, want 3 kills at 9:00 and 1 at 14:00", stats.PerHour)
+	}
+	if stats.MethodKills("SIGTERM") != 2 || stats.MethodKills("SIGKILL") != 1 || stats.ForcedRatio != 0.25 {
+		t.Errorf("Methods = %+v, ForcedRatio = %v; want 2 SIGTERM, 1 SIGKILL, 0.25", stats.Methods, stats.ForcedRatio)
+	}
+	// Median of 100, 200, 300 and 3200ms is the mean of the middle two
+	if stats.MedianDuration != 250*time.Millisecond {
+		t.Errorf("MedianDuration = %v, want 250ms", stats.MedianDuration)
+	}
+
+	// An empty window has no rankings and no median
+	empty, err := s.Stats(models.StatsQuery{Since: day(20, 0)})
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if empty.Attempts != 0 || len(empty.TopPorts) != 0 || empty.MedianDuration != 0 || empty.ForcedRatio != 0 {
+		t.Errorf("empty window stats = %+v", empty)
+	}
+}
+
 func BenchmarkSQLite_RecordKill(b *testing.B) {
 	tmpDir := b.TempDir()
 	dbPath := filepath.Join(tmpDir, "bench.db")
@@ -638,3 +1066,24 @@ func BenchmarkSQLite_GetKillCount(b *testing.B) {
 		}
 	}
 }
+
+func TestSQLite_KillSummariesBatches(t *testing.T) {
+	s := newTestSQLite(t)
+	now := time.Now()
+	ports := make([]int, 0, maxPortsPerQuery*2+1)
+	for port := 1; port <= maxPortsPerQuery*2+1; port++ {
+		ports = append(ports, port)
+	}
+	record(t, s,
+		models.HistoryEntry{PortNumber: 1, KilledAt: now},
+		models.HistoryEntry{PortNumber: maxPortsPerQuery*2 + 1, KilledAt: now},
+	)
+
+	summaries, err := s.KillSummaries(ports, 30)
+	if err != nil {
+		t.Fatalf("KillSummaries() error = %v", err)
+	}
+	if len(summaries) != 2 || summaries[maxPortsPerQuery*2+1].Kills != 1 {
+		t.Errorf("KillSummaries() = %+v, want both ends of the port list", summaries)
+	}
+}
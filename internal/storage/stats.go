@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// forcedRatio returns the share of kills with a recorded method that needed SIGKILL.
+func forcedRatio(methods []models.MethodKillCount) float64 {
+	var total, forced int
+	for _, m := range methods {
+		total += m.Kills
+		if m.Method == "SIGKILL" {
+			forced += m.Kills
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(forced) / float64(total)
+}
+
+// computeStats aggregates entries (in any order) the way SQLite.Stats does.
+func computeStats(entries []models.HistoryEntry, q models.StatsQuery) models.KillStats {
+	stats := models.KillStats{Since: q.Since, Until: q.Until}
+	top := q.Top
+	if top <= 0 {
+		top = models.DefaultStatsTop
+	}
+
+	// The process shown for a port is the most recent one killed there, in or out of the window
+	latest := make(map[int]models.HistoryEntry)
+	for _, entry := range entries {
+		if l, ok := latest[entry.PortNumber]; !ok || lessEntry(l, entry) {
+			latest[entry.PortNumber] = entry
+		}
+	}
+
+	ports := make(map[int]int)
+	processes := make(map[string]*models.ProcessKillCount)
+	days := make(map[string]int)
+	methods := make(map[string]int)
+	var durations []time.Duration
+	for _, entry := range entries {
+		if (!q.Since.IsZero() && entry.KilledAt.Before(q.Since)) || (!q.Until.IsZero() && !entry.KilledAt.Before(q.Until)) {
+			continue
+		}
+		stats.Attempts++
+		if entry.Failed {
+			stats.Failed++
+			continue
+		}
+		stats.Kills++
+		ports[entry.PortNumber]++
+		name := strings.ToLower(entry.ProcessName)
+		if processes[name] == nil {
+			processes[name] = &models.ProcessKillCount{ProcessName: entry.ProcessName}
+		}
+		processes[name].Kills++
+		days[entry.KilledAt.Format("2006-01-02")]++
+		stats.PerHour[entry.KilledAt.Hour()]++
+		if entry.Method != "" {
+			methods[entry.Method]++
+		}
+		if entry.Duration > 0 {
+			durations = append(durations, entry.Duration)
+		}
+	}
+
+	for port, kills := range ports {
+		stats.TopPorts = append(stats.TopPorts, models.PortKillCount{Port: port, ProcessName: latest[port].ProcessName, Kills: kills})
+	}
+	sort.Slice(stats.TopPorts, func(i, j int) bool {
+		a, b := stats.TopPorts[i], stats.TopPorts[j]
+		return a.Kills > b.Kills || (a.Kills == b.Kills && a.Port < b.Port)
+	})
+	if len(stats.TopPorts) > top {
+		stats.TopPorts = stats.TopPorts[:top]
+	}
+
+	for _, c := range processes {
+		stats.TopProcesses = append(stats.TopProcesses, *c)
+	}
+	sort.Slice(stats.TopProcesses, func(i, j int) bool {
+		a, b := stats.TopProcesses[i], stats.TopProcesses[j]
+		return a.Kills > b.Kills || (a.Kills == b.Kills && a.ProcessName < b.ProcessName)
+	})
+	if len(stats.TopProcesses) > top {
+		stats.TopProcesses = stats.TopProcesses[:top]
+	}
+
+	for day, kills := range days {
+		stats.PerDay = append(stats.PerDay, models.DayKillCount{Day: day, Kills: kills})
+	}
+	sort.Slice(stats.PerDay, func(i, j int) bool { return stats.PerDay[i].Day < stats.PerDay[j].Day })
+
+	for method, kills := range methods {
+		stats.Methods = append(stats.Methods, models.MethodKillCount{Method: method, Kills: kills})
+	}
+	sort.Slice(stats.Methods, func(i, j int) bool {
+		a, b := stats.Methods[i], stats.Methods[j]
+		return a.Kills > b.Kills || (a.Kills == b.Kills && a.Method < b.Method)
+	})
+	stats.ForcedRatio = forcedRatio(stats.Methods)
+
+	if n := len(durations); n > 0 {
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		stats.MedianDuration = (durations[(n-1)/2] + durations[n/2]) / 2
+	}
+	return stats
+}
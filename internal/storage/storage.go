@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/manson/port-chaser/internal/models"
@@ -11,12 +13,38 @@ import (
 type Storage interface {
 	// RecordKill saves a history entry when a process is killed
 	RecordKill(entry models.HistoryEntry) error
+	// ImportEntries adds the entries that aren't stored yet and returns how many it added
+	ImportEntries(entries []models.HistoryEntry) (int, error)
 	// GetHistory retrieves recent kill history, limited to the specified count
 	GetHistory(limit int) ([]models.HistoryEntry, error)
+	// QueryHistory returns one page of the entries matching the query, newest first
+	QueryHistory(q models.HistoryQuery) (models.HistoryPage, error)
+	// Stats aggregates the kill history in the query's window
+	Stats(q models.StatsQuery) (models.KillStats, error)
 	// GetKillCount returns how many times a specific port has been killed within the given days
 	GetKillCount(port int, days int) (int, error)
 	// GetLastKillTime returns when the specified port was last killed
 	GetLastKillTime(port int) (time.Time, error)
+	// KillSummaries returns the kill count and kills by hour of day within days, and the last
+	// kill time, of each of the ports that was ever killed, in one query; ports never killed are left out
+	KillSummaries(ports []int, days int) (map[int]models.PortKillSummary, error)
+	// Prune deletes entries older than maxAge and all but the newest maxRows entries
+	// (0 disables either limit) and returns how many were deleted
+	Prune(maxAge time.Duration, maxRows int) (int, error)
+	// Compact reclaims the space freed by Prune and refreshes query planner statistics
+	Compact() error
+	// RecordPortEvents saves port timeline events (listeners appearing and disappearing)
+	RecordPortEvents(events []models.PortEvent) error
+	// PortEvents returns the port timeline events matching the query, oldest first
+	PortEvents(q models.PortEventQuery) ([]models.PortEvent, error)
+	// PrunePortEvents deletes port timeline events older than maxAge (0 keeps them all)
+	PrunePortEvents(maxAge time.Duration) (int, error)
+	// Labels returns every port label, ordered by port, then scope
+	Labels() ([]models.PortLabel, error)
+	// SaveLabel stores a label, replacing the one with the same port and scope
+	SaveLabel(label models.PortLabel) error
+	// DeleteLabel deletes the label with the same port and scope, reporting whether there was one
+	DeleteLabel(label models.PortLabel) (bool, error)
 	// Close closes the storage connection and releases resources
 	Close() error
 }
@@ -24,7 +52,9 @@ type Storage interface {
 // Config holds configuration settings for storage implementations.
 // These settings control database behavior and performance.
 type Config struct {
-	// DBPath is the file path to the SQLite database (empty means use default location)
+	// Backend selects the implementation Open creates (empty means SQLite)
+	Backend    Backend
+	// DBPath is the file path to the SQLite database or JSON Lines file (empty means the platform data directory)
 	DBPath     string
 	// WALEnabled enables Write-Ahead Logging for better concurrency
 	WALEnabled bool
@@ -41,3 +71,52 @@ func DefaultConfig() Config {
 		Timeout:    50,
 	}
 }
+
+// Backend names a Storage implementation.
+type Backend string
+
+const (
+	// BackendSQLite stores history in a SQLite database; it needs a cgo build
+	BackendSQLite Backend = "sqlite"
+	// BackendJSONL appends history to a JSON Lines file; it is pure Go
+	BackendJSONL Backend = "jsonl"
+	// BackendMemory keeps history in memory until the process exits
+	BackendMemory Backend = "memory"
+)
+
+// ParseBackend converts a backend name ("sqlite", "jsonl" or "memory") into a Backend.
+// An empty name is SQLite.
+func ParseBackend(name string) (Backend, error) {
+	switch b := Backend(strings.ToLower(strings.TrimSpace(name))); b {
+	case "":
+		return BackendSQLite, nil
+	case BackendSQLite, BackendJSONL, BackendMemory:
+		return b, nil
+	}
+	return "", fmt.Errorf("unknown storage backend %q (want sqlite, jsonl or memory)", name)
+}
+
+// Open creates the Storage selected by cfg.Backend.
+func Open(cfg Config) (Storage, error) {
+	backend, err := ParseBackend(string(cfg.Backend))
+	if err != nil {
+		return nil, err
+	}
+	// Return a nil interface, not a nil pointer, on failure
+	switch backend {
+	case BackendJSONL:
+		s, err := NewJSONL(cfg.DBPath)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case BackendMemory:
+		return NewMemory(), nil
+	default:
+		s, err := NewSQLite(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+}
@@ -0,0 +1,150 @@
+// Package timeline records which process listened on which port over time.
+// A Recorder compares each port scan with the previous one and stores an event whenever
+// a listener appears or disappears, so past occupancy of a port can be looked up later.
+package timeline
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+)
+
+// Store keeps timeline events; every storage backend implements it.
+type Store interface {
+	// RecordPortEvents saves events
+	RecordPortEvents(events []models.PortEvent) error
+	// PortEvents returns the events matching the query, oldest first
+	PortEvents(q models.PortEventQuery) ([]models.PortEvent, error)
+	// PrunePortEvents deletes events older than maxAge (0 keeps them all)
+	PrunePortEvents(maxAge time.Duration) (int, error)
+}
+
+// Holdings returns who listened on port (0 for every port) at any time in the window
+// [since, until), oldest first. Zero bounds are open; a zero until includes current listeners.
+func Holdings(store Store, port int, since, until time.Time) ([]models.PortHolding, error) {
+	// Events before the window tell who already held the port when it starts, and events
+	// after it when the listeners that were there at its end went away
+	events, err := store.PortEvents(models.PortEventQuery{Port: port})
+	if err != nil {
+		return nil, err
+	}
+	return models.Holdings(events, since, until), nil
+}
+
+// listener identifies a process listening on a port.
+type listener struct {
+	port, pid int
+}
+
+// Recorder turns successive port scans into timeline events. It is safe for concurrent use.
+type Recorder struct {
+	store Store
+
+	mu sync.Mutex
+	// listening holds the listeners as of the last recorded scan (nil before the first)
+	listening map[listener]models.PortEvent
+}
+
+// NewRecorder creates a recorder that writes to store.
+func NewRecorder(store Store) *Recorder {
+	return &Recorder{store: store}
+}
+
+// Observe records the listeners that appeared or disappeared since the previous scan and
+// returns the events, disappearances first. The first scan is compared with the listeners the
+// store still has open, so restarting port-chaser doesn't record every listener again and
+// listeners that went away in the meantime are closed (at this scan's time).
+// If storing fails, nothing is remembered and the next scan records the changes again.
+func (r *Recorder) Observe(ports []models.PortInfo, at time.Time) ([]models.PortEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.listening
+	if previous == nil {
+		open, err := r.open()
+		if err != nil {
+			return nil, err
+		}
+		previous = open
+	}
+
+	current := make(map[listener]models.PortEvent, len(ports))
+	for _, port := range ports {
+		current[listener{port.PortNumber, port.PID}] = models.NewPortEvent(models.PortEventAppeared, port, at)
+	}
+
+	var gone, appeared []models.PortEvent
+	for key, event := range previous {
+		if _, ok := current[key]; !ok {
+			event.ID = 0
+			event.Kind = models.PortEventDisappeared
+			event.At = at
+			gone = append(gone, event)
+		}
+	}
+	for key, event := range current {
+		if _, ok := previous[key]; !ok {
+			appeared = append(appeared, event)
+		} else {
+			// Keep what was recorded when it appeared
+			current[key] = previous[key]
+		}
+	}
+	sortByPort(gone)
+	sortByPort(appeared)
+	events := append(gone, appeared...)
+
+	if err := r.store.RecordPortEvents(events); err != nil {
+		return nil, err
+	}
+	r.listening = current
+	return events, nil
+}
+
+// Holdings returns who listened on port at any time in [since, until).
+func (r *Recorder) Holdings(port int, since, until time.Time) ([]models.PortHolding, error) {
+	return Holdings(r.store, port, since, until)
+}
+
+// Prune deletes events older than maxAge (0 keeps them all).
+func (r *Recorder) Prune(maxAge time.Duration) (int, error) {
+	return r.store.PrunePortEvents(maxAge)
+}
+
+// open returns the listeners the store has no disappeared event for.
+func (r *Recorder) open() (map[listener]models.PortEvent, error) {
+	holdings, err := Holdings(r.store, 0, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	open := make(map[listener]models.PortEvent)
+	for _, h := range holdings {
+		if !h.Open() {
+			continue
+		}
+		open[listener{h.Port, h.PID}] = models.PortEvent{
+			Port:          h.Port,
+			Kind:          models.PortEventAppeared,
+			PID:           h.PID,
+			ProcessName:   h.ProcessName,
+			Command:       h.Command,
+			ContainerID:   h.ContainerID,
+			ContainerName: h.ContainerName,
+			ImageName:     h.ImageName,
+			At:            h.From,
+		}
+	}
+	return open, nil
+}
+
+// sortByPort orders events by port, then PID, so each scan's events are stored in a stable order.
+func sortByPort(events []models.PortEvent) {
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Port != events[j].Port {
+			return events[i].Port < events[j].Port
+		}
+		return events[i].PID < events[j].PID
+	})
+}
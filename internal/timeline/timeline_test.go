@@ -0,0 +1,114 @@
+package timeline
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/storage"
+)
+
+// failingStore fails every write while fail is set.
+type failingStore struct {
+	*storage.Memory
+	fail bool
+}
+
+func (s *failingStore) RecordPortEvents(events []models.PortEvent) error {
+	if s.fail {
+		return errors.New("disk full")
+	}
+	return s.Memory.RecordPortEvents(events)
+}
+
+// kinds describes events as "kind port/pid" for comparison.
+func kinds(events []models.PortEvent) []string {
+	var list []string
+	for _, e := range events {
+		list = append(list, fmt.Sprintf("%s %d/%d", e.Kind, e.Port, e.PID))
+	}
+	return list
+}
+
+func TestRecorder_Observe(t *testing.T) {
+	store := storage.NewMemory()
+	r := NewRecorder(store)
+	now := time.Now()
+	node := models.PortInfo{PortNumber: 3000, PID: 101, ProcessName: "node"}
+	java := models.PortInfo{PortNumber: 8080, PID: 202, ProcessName: "java"}
+	vite := models.PortInfo{PortNumber: 3000, PID: 303, ProcessName: "vite"}
+
+	steps := []struct {
+		name  string
+		ports []models.PortInfo
+		want  []string
+	}{
+		{"first scan", []models.PortInfo{java, node}, []string{"appeared 3000/101", "appeared 8080/202"}},
+		{"nothing changed", []models.PortInfo{node, java}, nil},
+		{"another process took the port", []models.PortInfo{vite, java}, []string{"disappeared 3000/101", "appeared 3000/303"}},
+		{"everything went away", nil, []string{"disappeared 3000/303", "disappeared 8080/202"}},
+	}
+	for i, step := range steps {
+		events, err := r.Observe(step.ports, now.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatalf("%s: Observe() error = %v", step.name, err)
+		}
+		if got := kinds(events); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: events = %v, want %v", step.name, got, step.want)
+		}
+	}
+
+	holdings, err := r.Holdings(3000, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Holdings() error = %v", err)
+	}
+	if len(holdings) != 2 || holdings[0].ProcessName != "node" || !holdings[0].Until.Equal(now.Add(2*time.Minute)) ||
+		holdings[1].ProcessName != "vite" || holdings[1].Open() {
+		t.Errorf("Holdings(3000) = %+v, want node then vite, both closed", holdings)
+	}
+}
+
+func TestRecorder_Restart(t *testing.T) {
+	store := storage.NewMemory()
+	now := time.Now()
+	node := models.PortInfo{PortNumber: 3000, PID: 101, ProcessName: "node"}
+	java := models.PortInfo{PortNumber: 8080, PID: 202, ProcessName: "java"}
+	if _, err := NewRecorder(store).Observe([]models.PortInfo{node, java}, now.Add(-time.Hour)); err != nil {
+		t.Fatalf("Observe() error = %v", err)
+	}
+
+	// A new recorder carries on from what the store has open
+	events, err := NewRecorder(store).Observe([]models.PortInfo{node}, now)
+	if err != nil {
+		t.Fatalf("Observe() error = %v", err)
+	}
+	if got := kinds(events); !reflect.DeepEqual(got, []string{"disappeared 8080/202"}) {
+		t.Errorf("events after restart = %v, want only java closed", got)
+	}
+	holdings, _ := Holdings(store, 3000, time.Time{}, time.Time{})
+	if len(holdings) != 1 || !holdings[0].From.Equal(now.Add(-time.Hour)) || !holdings[0].Open() {
+		t.Errorf("node holdings = %+v, want one still open since the first scan", holdings)
+	}
+}
+
+func TestRecorder_RetriesAfterFailure(t *testing.T) {
+	store := &failingStore{Memory: storage.NewMemory(), fail: true}
+	r := NewRecorder(store)
+	now := time.Now()
+	node := models.PortInfo{PortNumber: 3000, PID: 101, ProcessName: "node"}
+
+	if _, err := r.Observe([]models.PortInfo{node}, now); err == nil {
+		t.Fatal("Observe() should report the store's error")
+	}
+	store.fail = false
+	events, err := r.Observe([]models.PortInfo{node}, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Observe() error = %v", err)
+	}
+	if got := kinds(events); !reflect.DeepEqual(got, []string{"appeared 3000/101"}) {
+		t.Errorf("events after the failure = %v, want the appearance recorded again", got)
+	}
+}
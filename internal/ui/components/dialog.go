@@ -5,11 +5,14 @@ import (
 	"strings"
 
 	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/policy"
 	"github.com/manson/port-chaser/internal/ui"
 )
 
 type Dialog struct {
 	styles *ui.Styles
+	// Policy decides which processes get a warning (nil falls back to PortInfo.ShouldDisplayWarning)
+	Policy *policy.Policy
 }
 
 func NewDialog(styles *ui.Styles) *Dialog {
@@ -29,8 +32,8 @@ func (d *Dialog) RenderConfirmKill(port *models.PortInfo) string {
 	lines = append(lines, title)
 	lines = append(lines, "")
 
-	if port.ShouldDisplayWarning() {
-		warning := ui.RenderWarning(d.styles, "System critical process!")
+	if msg := protectionWarning(protection(d.Policy, port)); msg != "" {
+		warning := ui.RenderWarning(d.styles, msg)
 		lines = append(lines, warning)
 		lines = append(lines, "")
 	}
@@ -38,8 +41,17 @@ func (d *Dialog) RenderConfirmKill(port *models.PortInfo) string {
 	lines = append(lines, d.formatPortInfo(port))
 	lines = append(lines, "")
 
-	prompt := d.styles.StatusKey.Render("[y]") + " confirm  " +
-		d.styles.StatusDim.Render("[n/esc] cancel")
+	if port.IsManaged() {
+		warning := ui.RenderWarning(d.styles, "Managed by "+port.Manager+", a kill will likely respawn")
+		lines = append(lines, warning)
+		lines = append(lines, "")
+	}
+
+	prompt := d.styles.StatusKey.Render("[y]") + " confirm  "
+	if len(port.StopCommand) > 0 {
+		prompt += d.styles.StatusKey.Render("[m]") + " " + strings.Join(port.StopCommand, " ") + "  "
+	}
+	prompt += d.styles.StatusDim.Render("[n/esc] cancel")
 	lines = append(lines, prompt)
 
 	content := strings.Join(lines, "\n")
@@ -61,6 +73,14 @@ func (d *Dialog) formatPortInfo(port *models.PortInfo) string {
 		info = append(info, dockerLine)
 	}
 
+	if port.IsManaged() {
+		managerLine := "Manager: " + port.Manager
+		if port.ManagedName != "" {
+			managerLine += " (" + port.ManagedName + ")"
+		}
+		info = append(info, managerLine)
+	}
+
 	if port.Command != "" {
 		cmd := port.Command
 		if len(cmd) > 50 {
@@ -80,8 +100,35 @@ func (d *Dialog) getMarker(port *models.PortInfo) string {
 	if port.IsRecommended() {
 		return ui.RenderRecommendedMarker(d.styles)
 	}
-	if port.ShouldDisplayWarning() {
+	if protection(d.Policy, port).Action != policy.ActionAllow {
 		return ui.RenderSystemMarker(d.styles)
 	}
 	return ""
 }
+
+// protection returns the policy decision for port. Without a policy, the system process
+// heuristic stands in as an unnamed warning.
+func protection(p *policy.Policy, port *models.PortInfo) policy.Decision {
+	if p == nil {
+		if port.ShouldDisplayWarning() {
+			return policy.Decision{Action: policy.ActionWarn}
+		}
+		return policy.Decision{Action: policy.ActionAllow}
+	}
+	return p.Evaluate(port)
+}
+
+// protectionWarning describes a policy decision for the kill dialog, or returns "" if it allows the kill.
+func protectionWarning(decision policy.Decision) string {
+	switch {
+	case decision.Action == policy.ActionAllow:
+		return ""
+	case decision.Rule == "":
+		return "System critical process!"
+	case decision.Action == policy.ActionBlock:
+		return fmt.Sprintf("Protected by policy rule %q, the kill will be refused", decision.Rule)
+	case decision.Action == policy.ActionConfirm:
+		return fmt.Sprintf("Policy rule %q requires typing the process name to confirm", decision.Rule)
+	}
+	return fmt.Sprintf("Policy rule %q: kill with care", decision.Rule)
+}
@@ -4,11 +4,14 @@ import (
 	"strings"
 
 	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/policy"
 	"github.com/manson/port-chaser/internal/ui"
 )
 
 type PortList struct {
 	styles *ui.Styles
+	// Policy decides which processes are marked (nil falls back to PortInfo.ShouldDisplayWarning)
+	Policy *policy.Policy
 }
 
 func NewPortList(styles *ui.Styles) *PortList {
@@ -78,7 +81,11 @@ func (pl *PortList) renderMarkers(port models.PortInfo) string {
 		markers = append(markers, ui.RenderRecommendedMarker(pl.styles))
 	}
 
-	if port.ShouldDisplayWarning() {
+	if port.IsManaged() {
+		markers = append(markers, ui.RenderManagedMarker(pl.styles))
+	}
+
+	if protection(pl.Policy, &port).Action != policy.ActionAllow {
 		markers = append(markers, ui.RenderSystemMarker(pl.styles))
 	}
 
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/manson/port-chaser/internal/models"
+	"github.com/manson/port-chaser/internal/policy"
 	"github.com/manson/port-chaser/internal/ui"
 )
 
@@ -96,6 +97,25 @@ func TestPortList_RenderSystemMarker(t *testing.T) {
 	}
 }
 
+func TestPortList_RenderPolicyMarker(t *testing.T) {
+	styles := ui.DefaultStyles()
+	pl := NewPortList(styles)
+	pl.Policy = policy.New(
+		policy.Rule{Name: "container-init", Action: policy.ActionAllow, PIDs: []policy.Range{{Min: 1, Max: 1}}},
+		policy.Rule{Name: "sshd", Action: policy.ActionBlock, ProcessNames: []string{"sshd"}},
+	)
+
+	// A containerised app at PID 1 that the policy allows
+	if result := pl.Render([]models.PortInfo{{PortNumber: 3000, ProcessName: "node", PID: 1}}, 0, 80); strings.Contains(result, "[S]") {
+		t.Error("a port the policy allows should not get the system marker")
+	}
+
+	// A high-PID sshd the policy blocks
+	if result := pl.Render([]models.PortInfo{{PortNumber: 22, ProcessName: "sshd", PID: 4321, User: "root"}}, 0, 80); !strings.Contains(result, "[S]") {
+		t.Error("a port the policy blocks should get the system marker")
+	}
+}
+
 func TestHeader_Render(t *testing.T) {
 	styles := ui.DefaultStyles()
 	header := NewHeader(styles, "Port Chaser")
@@ -195,6 +215,37 @@ func TestDialog_RenderConfirmKill_SystemProcess(t *testing.T) {
 	}
 }
 
+func TestDialog_RenderConfirmKill_Policy(t *testing.T) {
+	styles := ui.DefaultStyles()
+	dialog := NewDialog(styles)
+	dialog.Policy = policy.Default()
+
+	tests := []struct {
+		name string
+		port models.PortInfo
+		want string
+	}{
+		{"blocked", models.PortInfo{PortNumber: 22, ProcessName: "sshd", PID: 4321}, `Protected by policy rule "sshd"`},
+		{"confirm", models.PortInfo{PortNumber: 8080, ProcessName: "node", PID: 1}, `Policy rule "low-pid" requires typing`},
+		{"warn", models.PortInfo{PortNumber: 8080, ProcessName: "nginx", PID: 4321, User: "root"}, `Policy rule "root-owned"`},
+		{"allowed", models.PortInfo{PortNumber: 3000, ProcessName: "node", PID: 4321, User: "dev"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := dialog.RenderConfirmKill(&tt.port)
+			if strings.Contains(result, "System critical process") {
+				t.Error("the heuristic warning should not be shown when a policy is set")
+			}
+			if tt.want == "" && strings.Contains(result, "Policy rule") {
+				t.Errorf("an allowed port should get no warning:\n%s", result)
+			}
+			if tt.want != "" && !strings.Contains(result, tt.want) {
+				t.Errorf("warning should contain %q:\n%s", tt.want, result)
+			}
+		})
+	}
+}
+
 func TestDialog_RenderConfirmKill_Docker(t *testing.T) {
 	styles := ui.DefaultStyles()
 	dialog := NewDialog(styles)
@@ -19,6 +19,8 @@ type Styles struct {
 
 	RecommendedMarker lipgloss.Style
 
+	ManagedMarker lipgloss.Style
+
 	SystemMarker lipgloss.Style
 
 	StatusBar lipgloss.Style
@@ -86,6 +88,10 @@ func DefaultStyles() *Styles {
 		Foreground(yellow).
 		Bold(true)
 
+	s.ManagedMarker = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("141")).
+		Bold(true)
+
 	s.SystemMarker = lipgloss.NewStyle().
 		Foreground(red).
 		Bold(true)
@@ -145,6 +151,10 @@ func RenderRecommendedMarker(styles *Styles) string {
 	return styles.RecommendedMarker.Render("[!]")
 }
 
+func RenderManagedMarker(styles *Styles) string {
+	return styles.ManagedMarker.Render("[M]")
+}
+
 func RenderSystemMarker(styles *Styles) string {
 	return styles.SystemMarker.Render("[S]")
 }